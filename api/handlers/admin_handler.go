@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"net/url"
+	"quiz-app/pkg/entity"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/user"
+	"strconv"
+	"time"
+)
+
+func AdminHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, service *user.Service) {
+
+	requireAdmin := accessCtrlService.RequireRole(entity.RoleAdmin)
+
+	listUsersHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		errorMsg := "Unable to list users"
+
+		query, err := parseUserListQuery(r.URL.Query())
+		if err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(err.Error())); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		page, appErr := service.ListUsers(query, r.URL.Query().Get("cursor"))
+
+		if appErr != nil {
+			log.Println(appErr)
+			if appErr == entity.ErrInvalidCursor || appErr == entity.ErrInvalidSort {
+				w.WriteHeader(http.StatusBadRequest)
+				errorMsg = appErr.Error()
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			log.Println(err)
+		}
+	})
+
+	router.Handle("/admin/users", requireAdmin(listUsersHandler)).Methods("GET", "OPTIONS")
+}
+
+// parseUserListQuery reads limit, username, sort, order and the RFC 3339 date filters
+// createdAfter, createdBefore, lastLoginAfter and lastLoginBefore.
+func parseUserListQuery(values url.Values) (*entity.UserListQuery, error) {
+	query := &entity.UserListQuery{
+		UsernamePrefix: values.Get("username"),
+		SortBy:         values.Get("sort"),
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, fmt.Errorf("limit must be a number")
+		}
+		query.Limit = n
+	}
+
+	switch values.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return nil, fmt.Errorf("order must be asc or desc")
+	}
+
+	dates := map[string]**time.Time{
+		"createdAfter":    &query.CreatedAfter,
+		"createdBefore":   &query.CreatedBefore,
+		"lastLoginAfter":  &query.LastLoginAfter,
+		"lastLoginBefore": &query.LastLoginBefore,
+	}
+
+	for name, target := range dates {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 date", name)
+		}
+		*target = &t
+	}
+
+	return query, nil
+}
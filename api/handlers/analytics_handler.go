@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/analytics"
+	"quiz-app/pkg/entity"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/quiz"
+)
+
+func AnalyticsHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, quizService *quiz.Service, service *analytics.Service) {
+
+	// only the owner of the quiz in the {id} route variable may analyse it:
+	requireOwner := accessCtrlService.RequireOwnership(func(r *http.Request) (int64, *entity.AppError) {
+		quizId, err := pathID(r, "id")
+		if err != nil {
+			return 0, entity.ErrEntityNotFound
+		}
+
+		return quizService.GetOwnerID(quizId)
+	})
+
+	itemAnalysisHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		quizId, _ := pathID(r, "id")
+
+		report, err := service.GetItemAnalysis(quizId)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, "Unable to analyse quiz")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.Println(err)
+		}
+	})
+
+	router.Handle("/quizzes/{id:[0-9]+}/analysis", requireOwner(itemAnalysisHandler)).Methods("GET", "OPTIONS")
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/attempt"
+	"quiz-app/pkg/entity"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+)
+
+func AttemptHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, service *attempt.Service) {
+
+	// only the user taking the attempt in the {id} route variable may see or change it:
+	requireOwner := accessCtrlService.RequireOwnership(func(r *http.Request) (int64, *entity.AppError) {
+		attemptId, err := pathID(r, "id")
+		if err != nil {
+			return 0, entity.ErrEntityNotFound
+		}
+
+		return service.GetOwnerID(attemptId)
+	})
+
+	startAttemptHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+
+		quizId, _ := pathID(r, "id")
+		a, err := service.StartAttempt(quizId, claims.UserId)
+
+		if err != nil {
+			log.Println(err)
+			writeAttemptError(w, err, "Unable to start attempt")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(a); err != nil {
+			log.Println(err)
+		}
+	})
+
+	getAttemptHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptId, _ := pathID(r, "id")
+		a, err := service.GetAttempt(attemptId)
+
+		if err != nil {
+			log.Println(err)
+			writeAttemptError(w, err, "Error finding attempt")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(a); err != nil {
+			log.Println(err)
+		}
+	})
+
+	openQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		errorMsg := "Unable to open question"
+
+		questionId, parseErr := pathID(r, "questionId")
+		if parseErr != nil {
+			log.Println(parseErr)
+			w.WriteHeader(http.StatusNotFound)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		attemptId, _ := pathID(r, "id")
+		question, err := service.OpenQuestion(attemptId, questionId)
+
+		if err != nil {
+			log.Println(err)
+			writeAttemptError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(question); err != nil {
+			log.Println(err)
+		}
+	})
+
+	answerHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response entity.Response
+		errorMsg := "Unable to save answer"
+
+		questionId, parseErr := pathID(r, "questionId")
+		if err := json.NewDecoder(r.Body).Decode(&response); err != nil || parseErr != nil {
+			log.Println(err, parseErr)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		attemptId, _ := pathID(r, "id")
+		answer, err := service.AnswerQuestion(attemptId, questionId, &response)
+
+		if err != nil {
+			log.Println(err)
+			writeAttemptError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(answer); err != nil {
+			log.Println(err)
+		}
+	})
+
+	submitHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attemptId, _ := pathID(r, "id")
+		a, err := service.SubmitAttempt(attemptId)
+
+		if err != nil {
+			log.Println(err)
+			writeAttemptError(w, err, "Unable to submit attempt")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(a); err != nil {
+			log.Println(err)
+		}
+	})
+
+	router.Handle("/quizzes/{id:[0-9]+}/attempts", accessCtrlService.IsUserAuthenticated(startAttemptHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/attempts/{id:[0-9]+}", requireOwner(getAttemptHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/attempts/{id:[0-9]+}/questions/{questionId:[0-9]+}/open", requireOwner(openQuestionHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/attempts/{id:[0-9]+}/answers/{questionId:[0-9]+}", requireOwner(answerHandler)).Methods("PUT", "OPTIONS")
+	router.Handle("/attempts/{id:[0-9]+}/submit", requireOwner(submitHandler)).Methods("POST", "OPTIONS")
+}
+
+// writeAttemptError answers with the status for an error of the attempt service.
+func writeAttemptError(w http.ResponseWriter, err *entity.AppError, errorMsg string) {
+	switch err {
+	case entity.ErrInvalidAnswer:
+		w.WriteHeader(http.StatusBadRequest)
+		errorMsg = err.Error()
+	case entity.ErrAttemptSubmitted, entity.ErrAttemptExpired, entity.ErrQuestionExpired, entity.ErrQuestionNotOpened,
+		entity.ErrNotEnoughQuestions:
+		w.WriteHeader(http.StatusConflict)
+		errorMsg = err.Error()
+	case entity.ErrEntityNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	if _, err := w.Write([]byte(errorMsg)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/health"
+)
+
+func HealthHandlers(router *mux.Router, service *health.Service, build *entity.BuildInfo) {
+
+	writeJSON := func(w http.ResponseWriter, status int, body interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			log.Println(err)
+		}
+	}
+
+	// the process is alive as long as it answers, whatever the state of its dependencies:
+	livenessHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": entity.HealthOK})
+	})
+
+	readinessHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readiness := service.Ready(r.Context())
+
+		status := http.StatusOK
+		if readiness.Status != entity.HealthOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, status, readiness)
+	})
+
+	versionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, build)
+	})
+
+	router.Handle("/healthz", livenessHandler).Methods("GET", "OPTIONS")
+	router.Handle("/readyz", readinessHandler).Methods("GET", "OPTIONS")
+	router.Handle("/version", versionHandler).Methods("GET", "OPTIONS")
+
+	// kept for the probes that still use it:
+	router.Handle("/ping", livenessHandler).Methods("GET", "OPTIONS")
+}
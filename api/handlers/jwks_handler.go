@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/keyring"
+)
+
+func JwksHandlers(router *mux.Router, kr *keyring.Keyring) {
+
+	jwksHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+
+		if err := json.NewEncoder(w).Encode(kr.JWKS()); err != nil {
+			log.Println(err)
+		}
+	})
+
+	router.Handle("/.well-known/jwks.json", jwksHandler).Methods("GET", "OPTIONS")
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/leaderboard"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"strconv"
+)
+
+func LeaderboardHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, service *leaderboard.Service) {
+
+	quizLeaderboardHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+
+		limit, ok := leaderboardLimit(w, r)
+		if !ok {
+			return
+		}
+
+		quizId, _ := pathID(r, "id")
+		board, err := service.GetQuizLeaderboard(quizId, r.URL.Query().Get("window"), limit, claims.UserId)
+
+		if err != nil {
+			log.Println(err)
+			writeLeaderboardError(w, err, "Unable to get leaderboard")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(board); err != nil {
+			log.Println(err)
+		}
+	})
+
+	globalLeaderboardHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+
+		limit, ok := leaderboardLimit(w, r)
+		if !ok {
+			return
+		}
+
+		board, err := service.GetGlobalLeaderboard(r.URL.Query().Get("window"), limit, claims.UserId)
+
+		if err != nil {
+			log.Println(err)
+			writeLeaderboardError(w, err, "Unable to get leaderboard")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(board); err != nil {
+			log.Println(err)
+		}
+	})
+
+	router.Handle("/quizzes/{id:[0-9]+}/leaderboard", accessCtrlService.IsUserAuthenticated(quizLeaderboardHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/leaderboard", accessCtrlService.IsUserAuthenticated(globalLeaderboardHandler)).Methods("GET", "OPTIONS")
+}
+
+// leaderboardLimit parses the optional limit query parameter and answers 400 when it is not a number.
+func leaderboardLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	limit := r.URL.Query().Get("limit")
+	if limit == "" {
+		return 0, true
+	}
+
+	n, err := strconv.Atoi(limit)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusBadRequest)
+		if _, err := w.Write([]byte("limit must be a number")); err != nil {
+			log.Println(err)
+		}
+
+		return 0, false
+	}
+
+	return n, true
+}
+
+// writeLeaderboardError answers with the status for an error of the leaderboard service.
+func writeLeaderboardError(w http.ResponseWriter, err *entity.AppError, errorMsg string) {
+	switch err {
+	case entity.ErrInvalidWindow:
+		w.WriteHeader(http.StatusBadRequest)
+		errorMsg = err.Error()
+	case entity.ErrEntityNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	if _, err := w.Write([]byte(errorMsg)); err != nil {
+		log.Println(err)
+	}
+}
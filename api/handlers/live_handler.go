@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/live"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/quiz"
+)
+
+func LiveHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, quizService *quiz.Service, service *live.Service, allowedOrigin string) {
+
+	// only the owner of the quiz in the {id} route variable may host it:
+	requireOwner := accessCtrlService.RequireOwnership(func(r *http.Request) (int64, *entity.AppError) {
+		quizId, err := pathID(r, "id")
+		if err != nil {
+			return 0, entity.ErrEntityNotFound
+		}
+
+		return quizService.GetOwnerID(quizId)
+	})
+
+	// browsers send the token with the first message, since they cannot set headers on the
+	// handshake, so only pages of the app itself may connect:
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || origin == allowedOrigin
+		},
+	}
+
+	createSessionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		quizId, _ := pathID(r, "id")
+
+		session, err := service.CreateSession(quizId, claims.UserId)
+
+		if err != nil {
+			log.Println(err)
+			writeLiveError(w, err, "Unable to create live session")
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(session); err != nil {
+			log.Println(err)
+		}
+	})
+
+	connectHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := mux.Vars(r)["code"]
+
+		if _, err := service.GetSession(code); err != nil {
+			log.Println(err)
+			writeLiveError(w, err, "Unable to join live session")
+			return
+		}
+
+		// the upgrader answers the failed handshakes itself:
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Println(err)
+			return
+		}
+
+		service.Serve(code, conn)
+	})
+
+	router.Handle("/quizzes/{id:[0-9]+}/live", requireOwner(createSessionHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/live/{code:[A-Za-z0-9]+}", connectHandler).Methods("GET")
+}
+
+// writeLiveError answers with the status for an error of the live service.
+func writeLiveError(w http.ResponseWriter, err *entity.AppError, errorMsg string) {
+	switch err {
+	case entity.ErrEmptyLiveQuiz:
+		w.WriteHeader(http.StatusBadRequest)
+		errorMsg = err.Error()
+	case entity.ErrNotEnoughQuestions:
+		w.WriteHeader(http.StatusConflict)
+		errorMsg = err.Error()
+	case entity.ErrEntityNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	if _, err := w.Write([]byte(errorMsg)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/quiz"
+)
+
+func QuestionBankHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, service *quiz.Service) {
+
+	// only the owner of the bank question in the {id} route variable may see or change it:
+	requireOwner := accessCtrlService.RequireOwnership(func(r *http.Request) (int64, *entity.AppError) {
+		questionId, err := pathID(r, "id")
+		if err != nil {
+			return 0, entity.ErrEntityNotFound
+		}
+
+		return service.GetBankQuestionOwnerID(questionId)
+	})
+
+	addQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		var question *entity.Question
+		errorMsg := "Unable to add question"
+		if err := json.NewDecoder(r.Body).Decode(&question); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		created, err := service.AddBankQuestion(claims.UserId, question)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(created); err != nil {
+			log.Println(err)
+		}
+	})
+
+	listQuestionsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+
+		questions, err := service.ListBankQuestions(&entity.BankQuery{
+			OwnerId:    claims.UserId,
+			Tag:        r.URL.Query().Get("tag"),
+			Difficulty: r.URL.Query().Get("difficulty"),
+		})
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, "Unable to list questions")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(questions); err != nil {
+			log.Println(err)
+		}
+	})
+
+	getQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		questionId, _ := pathID(r, "id")
+		question, err := service.GetBankQuestion(questionId)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, "Error finding question")
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(question); err != nil {
+			log.Println(err)
+		}
+	})
+
+	updateQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var question *entity.Question
+		errorMsg := "Unable to update question"
+		if err := json.NewDecoder(r.Body).Decode(&question); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		questionId, _ := pathID(r, "id")
+		updated, err := service.UpdateBankQuestion(questionId, question)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(updated); err != nil {
+			log.Println(err)
+		}
+	})
+
+	deleteQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		questionId, _ := pathID(r, "id")
+
+		if err := service.DeleteBankQuestion(questionId); err != nil {
+			log.Println(err)
+			writeQuizError(w, err, "Unable to delete question")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	router.Handle("/questions", accessCtrlService.IsUserAuthenticated(addQuestionHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/questions", accessCtrlService.IsUserAuthenticated(listQuestionsHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/questions/{id:[0-9]+}", requireOwner(getQuestionHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/questions/{id:[0-9]+}", requireOwner(updateQuestionHandler)).Methods("PUT", "OPTIONS")
+	router.Handle("/questions/{id:[0-9]+}", requireOwner(deleteQuestionHandler)).Methods("DELETE", "OPTIONS")
+}
@@ -0,0 +1,403 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"io"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/quiz"
+	"quiz-app/pkg/transfer"
+	"strconv"
+)
+
+const (
+	// maxImportSize limits the size of imported files:
+	maxImportSize = 10 << 20
+	defaultFormat = "json"
+)
+
+func QuizHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, service *quiz.Service) {
+
+	// only the owner of the quiz in the {id} route variable may change it:
+	requireOwner := accessCtrlService.RequireOwnership(func(r *http.Request) (int64, *entity.AppError) {
+		quizId, err := pathID(r, "id")
+		if err != nil {
+			return 0, entity.ErrEntityNotFound
+		}
+
+		return service.GetOwnerID(quizId)
+	})
+
+	createQuizHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		var q *entity.Quiz
+		errorMsg := "Unable to create quiz"
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil || q == nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		created, err := service.CreateQuiz(claims.UserId, q)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(created); err != nil {
+			log.Println(err)
+		}
+	})
+
+	importQuizHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		errorMsg := "Unable to import quiz"
+
+		data, readErr := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+		if readErr != nil {
+			log.Println(readErr)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = defaultFormat
+		}
+
+		created, err := service.ImportQuiz(claims.UserId, format, data, r.URL.Query().Get("title"))
+
+		if importErr, ok := errAsImportError(err); ok {
+			log.Println(err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			if err := json.NewEncoder(w).Encode(map[string]interface{}{"error": err.Error(), "issues": importErr.Issues}); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(created); err != nil {
+			log.Println(err)
+		}
+	})
+
+	exportQuizHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = defaultFormat
+		}
+
+		quizId, _ := pathID(r, "id")
+		data, err := service.ExportQuiz(quizId, format)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, "Unable to export quiz")
+			return
+		}
+
+		f, _ := transfer.For(format)
+		w.Header().Set("Content-Type", f.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"quiz-%d.%s\"", quizId, format))
+		if _, err := w.Write(data); err != nil {
+			log.Println(err)
+		}
+	})
+
+	listQuizzesHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		errorMsg := "Unable to list quizzes"
+
+		query := &entity.QuizListQuery{}
+		numbers := map[string]*int64{"ownerId": &query.OwnerId, "after": &query.AfterId}
+		for name, target := range numbers {
+			if value := r.URL.Query().Get(name); value != "" {
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil {
+					log.Println(err)
+					w.WriteHeader(http.StatusBadRequest)
+					if _, err := w.Write([]byte(name + " must be a number")); err != nil {
+						log.Println(err)
+					}
+
+					return
+				}
+				*target = n
+			}
+		}
+
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil {
+				log.Println(err)
+				w.WriteHeader(http.StatusBadRequest)
+				if _, err := w.Write([]byte("limit must be a number")); err != nil {
+					log.Println(err)
+				}
+
+				return
+			}
+			query.Limit = n
+		}
+
+		page, err := service.ListQuizzes(query)
+
+		if err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(page); err != nil {
+			log.Println(err)
+		}
+	})
+
+	getQuizHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		errorMsg := "Error finding quiz"
+
+		quizId, parseErr := pathID(r, "id")
+		if parseErr != nil {
+			log.Println(parseErr)
+			w.WriteHeader(http.StatusNotFound)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		q, err := service.GetQuiz(quizId, claims.UserId)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(q); err != nil {
+			log.Println(err)
+		}
+	})
+
+	updateQuizHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var update quiz.QuizUpdate
+		errorMsg := "Unable to update quiz"
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		// the id has been checked by requireOwner:
+		quizId, _ := pathID(r, "id")
+		q, err := service.UpdateQuiz(quizId, &update)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(q); err != nil {
+			log.Println(err)
+		}
+	})
+
+	setDrawRulesHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rules []*entity.DrawRule
+		errorMsg := "Unable to set draw rules"
+		if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		quizId, _ := pathID(r, "id")
+		q, err := service.SetDrawRules(quizId, rules)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(q); err != nil {
+			log.Println(err)
+		}
+	})
+
+	deleteQuizHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		quizId, _ := pathID(r, "id")
+
+		if err := service.DeleteQuiz(quizId); err != nil {
+			log.Println(err)
+			writeQuizError(w, err, "Unable to delete quiz")
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	addQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var question *entity.Question
+		errorMsg := "Unable to add question"
+		if err := json.NewDecoder(r.Body).Decode(&question); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		quizId, _ := pathID(r, "id")
+		created, err := service.AddQuestion(quizId, question)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(created); err != nil {
+			log.Println(err)
+		}
+	})
+
+	updateQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var question *entity.Question
+		errorMsg := "Unable to update question"
+
+		questionId, parseErr := pathID(r, "questionId")
+		if err := json.NewDecoder(r.Body).Decode(&question); err != nil || parseErr != nil {
+			log.Println(err, parseErr)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		quizId, _ := pathID(r, "id")
+		updated, err := service.UpdateQuestion(quizId, questionId, question)
+
+		if err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(updated); err != nil {
+			log.Println(err)
+		}
+	})
+
+	deleteQuestionHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		errorMsg := "Unable to delete question"
+
+		questionId, parseErr := pathID(r, "questionId")
+		if parseErr != nil {
+			log.Println(parseErr)
+			w.WriteHeader(http.StatusNotFound)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		quizId, _ := pathID(r, "id")
+		if err := service.DeleteQuestion(quizId, questionId); err != nil {
+			log.Println(err)
+			writeQuizError(w, err, errorMsg)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	router.Handle("/quizzes", accessCtrlService.IsUserAuthenticated(createQuizHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/quizzes", accessCtrlService.IsUserAuthenticated(listQuizzesHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/quizzes/import", accessCtrlService.IsUserAuthenticated(importQuizHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}/export", requireOwner(exportQuizHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}", accessCtrlService.IsUserAuthenticated(getQuizHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}", requireOwner(updateQuizHandler)).Methods("PATCH", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}", requireOwner(deleteQuizHandler)).Methods("DELETE", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}/draw-rules", requireOwner(setDrawRulesHandler)).Methods("PUT", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}/questions", requireOwner(addQuestionHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}/questions/{questionId:[0-9]+}", requireOwner(updateQuestionHandler)).Methods("PUT", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}/questions/{questionId:[0-9]+}", requireOwner(deleteQuestionHandler)).Methods("DELETE", "OPTIONS")
+}
+
+// pathID parses the numeric route variable name.
+func pathID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+}
+
+// writeQuizError answers with the status for an error of the quiz service.
+func writeQuizError(w http.ResponseWriter, err *entity.AppError, errorMsg string) {
+	switch {
+	case err == entity.ErrInvalidQuiz, err == entity.ErrInvalidDrawRule, err == entity.ErrUnknownFormat, errors.Is(err, entity.ErrInvalidQuestion):
+		w.WriteHeader(http.StatusBadRequest)
+		errorMsg = err.Error()
+	case err == entity.ErrEntityNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+
+	if _, err := w.Write([]byte(errorMsg)); err != nil {
+		log.Println(err)
+	}
+}
+
+// errAsImportError returns the problems of a rejected import.
+func errAsImportError(err *entity.AppError) (*entity.ImportError, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	importErr, ok := err.AppError.(*entity.ImportError)
+	return importErr, ok
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/quiz"
+	"quiz-app/pkg/stream"
+)
+
+func StreamHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, quizService *quiz.Service, service *stream.Service) {
+
+	// only the owner of the quiz in the {id} route variable may watch it:
+	requireOwner := accessCtrlService.RequireOwnership(func(r *http.Request) (int64, *entity.AppError) {
+		quizId, err := pathID(r, "id")
+		if err != nil {
+			return 0, entity.ErrEntityNotFound
+		}
+
+		return quizService.GetOwnerID(quizId)
+	})
+
+	// browsers cannot set headers on an EventSource, so they first exchange their access token
+	// for a ticket to the events of the quiz at POST /quizzes/{id}/events/ticket, and connect
+	// with ?ticket= instead. The browser reconnects with the same ticket until the access token
+	// expires; a stream opened again with a new ticket can pass ?lastEventId= to resume:
+	allowTicket := accessCtrlService.AllowTicket(func(r *http.Request) string {
+		quizId, _ := pathID(r, "id")
+		return quizEventsAudience(quizId)
+	})
+
+	quizEventsTicketHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		quizId, _ := pathID(r, "id")
+
+		ticket, err := accessCtrlService.IssueTicket(claims, quizEventsAudience(quizId))
+
+		if err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			if _, err := w.Write([]byte("Unable to issue ticket")); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(ticket); err != nil {
+			log.Println(err)
+		}
+	})
+
+	quizEventsHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		quizId, _ := pathID(r, "id")
+
+		if err := service.Serve(w, r, quizId); err != nil {
+			log.Println(err)
+			writeLeaderboardError(w, err, "Unable to stream quiz events")
+		}
+	})
+
+	router.Handle("/quizzes/{id:[0-9]+}/events/ticket", requireOwner(quizEventsTicketHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/quizzes/{id:[0-9]+}/events", allowTicket(requireOwner(quizEventsHandler))).Methods("GET", "OPTIONS")
+}
+
+// quizEventsAudience is the audience of the tickets to the events of the quiz.
+func quizEventsAudience(quizId int64) string {
+	return fmt.Sprintf("quiz-events:%d", quizId)
+}
@@ -4,10 +4,13 @@ import (
 	"encoding/json"
 	"github.com/gorilla/mux"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"quiz-app/pkg/entity"
 	accessCtrl "quiz-app/pkg/middleware/access-control"
 	"quiz-app/pkg/user"
+	"strconv"
 )
 
 func UserHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, service *user.Service) {
@@ -34,10 +37,23 @@ func UserHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, ser
 			return
 		}
 
-		authUser, err := service.AuthenticateUser(u.Username, u.Password)
+		authUser, err := service.AuthenticateUser(u.Username, u.Password, clientIP(r))
 
 		if err != nil {
 			log.Println(err)
+			if err.Is(entity.ErrTooManyAttempts) {
+				if retryErr, ok := err.AppError.(*entity.RetryAfterError); ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+				}
+
+				w.WriteHeader(http.StatusTooManyRequests)
+				if _, err := w.Write([]byte(err.Error())); err != nil {
+					log.Println(err)
+				}
+
+				return
+			}
+
 			if err == entity.ErrSecretKey || err == entity.ErrJwtCreation {
 				w.WriteHeader(http.StatusInternalServerError)
 				if _, err := w.Write([]byte(errorMsg)); err != nil {
@@ -64,6 +80,310 @@ func UserHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, ser
 
 	})
 
+	registerHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var u *entity.User
+		errorMsg := "Unable to register user"
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if u == nil || u.Username == "" || u.Password == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		authUser, err := service.RegisterUser(u.Username, u.Email, u.Password)
+
+		if err != nil {
+			log.Println(err)
+			switch err {
+			case entity.ErrInvalidUsername, entity.ErrInvalidEmail, entity.ErrInvalidPassword:
+				w.WriteHeader(http.StatusBadRequest)
+				errorMsg = err.Error()
+			case entity.ErrUserAlreadyExists, entity.ErrEmailAlreadyExists:
+				w.WriteHeader(http.StatusConflict)
+				errorMsg = err.Error()
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(authUser); err != nil {
+			log.Println(err)
+		}
+	})
+
+	refreshTokenHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		errorMsg := "Unable to refresh token"
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RefreshToken == "" {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		authUser, err := service.RefreshToken(body.RefreshToken)
+
+		if err != nil {
+			log.Println(err)
+			if err == entity.ErrInvalidRefreshToken || err == entity.ErrRefreshTokenReuse {
+				w.WriteHeader(http.StatusUnauthorized)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(authUser); err != nil {
+			log.Println(err)
+		}
+	})
+
+	forgotPasswordHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Email string `json:"email"`
+		}
+		errorMsg := "Unable to request password reset"
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Email == "" {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		// the response is the same whether or not an account uses the address:
+		if err := service.RequestPasswordReset(body.Email); err != nil {
+			log.Println(err)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	resetPasswordHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Token    string `json:"token"`
+			Password string `json:"password"`
+		}
+		errorMsg := "Unable to reset password"
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Token == "" || body.Password == "" {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err := service.ResetPassword(body.Token, body.Password); err != nil {
+			log.Println(err)
+			if err == entity.ErrInvalidResetToken || err == entity.ErrInvalidPassword {
+				w.WriteHeader(http.StatusBadRequest)
+				errorMsg = err.Error()
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	logoutHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		errorMsg := "Unable to log out user"
+
+		// the refresh token is optional, an empty body only revokes the access token:
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				log.Println(err)
+				w.WriteHeader(http.StatusBadRequest)
+				if _, err := w.Write([]byte(errorMsg)); err != nil {
+					log.Println(err)
+				}
+
+				return
+			}
+		}
+
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		if err := accessCtrlService.RevokeToken(claims); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if body.RefreshToken != "" {
+			if err := service.RevokeRefreshToken(claims.UserId, body.RefreshToken); err != nil {
+				log.Println(err)
+				w.WriteHeader(http.StatusInternalServerError)
+				if _, err := w.Write([]byte(errorMsg)); err != nil {
+					log.Println(err)
+				}
+
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	getMeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		errorMsg := "Error finding user"
+
+		u, err := service.GetProfile(claims.UserId)
+
+		if err != nil {
+			log.Println(err)
+			if err == entity.ErrEntityNotFound {
+				w.WriteHeader(http.StatusNotFound)
+			} else {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(u); err != nil {
+			log.Println(err)
+		}
+	})
+
+	updateMeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		var update user.ProfileUpdate
+		errorMsg := "Unable to update user"
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		u, err := service.UpdateProfile(claims.UserId, &update)
+
+		if err != nil {
+			log.Println(err)
+			switch err {
+			case entity.ErrInvalidUsername, entity.ErrInvalidEmail, entity.ErrInvalidPassword:
+				w.WriteHeader(http.StatusBadRequest)
+				errorMsg = err.Error()
+			case entity.ErrIncorrectPassword:
+				w.WriteHeader(http.StatusForbidden)
+				errorMsg = err.Error()
+			case entity.ErrUserAlreadyExists, entity.ErrEmailAlreadyExists:
+				w.WriteHeader(http.StatusConflict)
+				errorMsg = err.Error()
+			case entity.ErrEntityNotFound:
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(u); err != nil {
+			log.Println(err)
+		}
+	})
+
+	deleteMeHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := accessCtrl.ClaimsFromContext(r.Context())
+		var body struct {
+			Password string `json:"password"`
+		}
+		errorMsg := "Unable to delete user"
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Password == "" {
+			log.Println(err)
+			w.WriteHeader(http.StatusBadRequest)
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		if err := service.DeleteUser(claims.UserId, body.Password); err != nil {
+			log.Println(err)
+			switch err {
+			case entity.ErrIncorrectPassword:
+				w.WriteHeader(http.StatusForbidden)
+				errorMsg = err.Error()
+			case entity.ErrEntityNotFound:
+				w.WriteHeader(http.StatusNotFound)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+			if _, err := w.Write([]byte(errorMsg)); err != nil {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		// the access token would be rejected anyway once the user is gone:
+		if err := accessCtrlService.RevokeToken(claims); err != nil {
+			log.Println(err)
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
 	userHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		username := vars["username"]
@@ -71,7 +391,7 @@ func UserHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, ser
 
 		u, err := service.GetUserByUsername(username)
 
-		if u == nil {
+		if err == entity.ErrEntityNotFound {
 			w.WriteHeader(http.StatusNotFound)
 			log.Println(err)
 			if _, err := w.Write([]byte(errorMsg)); err != nil {
@@ -89,7 +409,7 @@ func UserHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, ser
 			return
 		}
 
-		if err := json.NewEncoder(w).Encode(u); err != nil {
+		if err := json.NewEncoder(w).Encode(u.Public()); err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			if _, err := w.Write([]byte(errorMsg)); err != nil {
 				log.Println(err)
@@ -99,5 +419,24 @@ func UserHandlers(router *mux.Router, accessCtrlService *accessCtrl.Service, ser
 	})
 
 	router.Handle("/user/authenticate", authenticateHandler).Methods("POST", "OPTIONS")
+	router.Handle("/user/password/forgot", forgotPasswordHandler).Methods("POST", "OPTIONS")
+	router.Handle("/user/password/reset", resetPasswordHandler).Methods("POST", "OPTIONS")
+	router.Handle("/user/logout", accessCtrlService.IsUserAuthenticated(logoutHandler)).Methods("POST", "OPTIONS")
+	router.Handle("/user/token/refresh", refreshTokenHandler).Methods("POST", "OPTIONS")
+	router.Handle("/users", registerHandler).Methods("POST", "OPTIONS")
+	// registered before /users/{username} so that "me" is not taken for a username:
+	router.Handle("/users/me", accessCtrlService.IsUserAuthenticated(getMeHandler)).Methods("GET", "OPTIONS")
+	router.Handle("/users/me", accessCtrlService.IsUserAuthenticated(updateMeHandler)).Methods("PATCH", "OPTIONS")
+	router.Handle("/users/me", accessCtrlService.IsUserAuthenticated(deleteMeHandler)).Methods("DELETE", "OPTIONS")
 	router.Handle("/users/{username}", accessCtrlService.IsUserAuthenticated(userHandler)).Methods("GET", "OPTIONS")
 }
+
+// clientIP returns the address of the peer. X-Forwarded-For is ignored since any client can set it.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
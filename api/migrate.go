@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"quiz-app/config"
+	"quiz-app/pkg/database"
+	"quiz-app/pkg/migration"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status | create <name> [flags]"
+
+// runMigrate runs the migrate subcommand with its arguments: up applies the pending migrations,
+// down reverts the latest one or the given number of them, status lists the migrations, and
+// create adds the files of a new migration to the source tree, from its root. The flags are
+// the ones of the server.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	command, args := args[0], args[1:]
+
+	// down and create take an argument before the flags:
+	argument := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		argument, args = args[0], args[1:]
+	}
+
+	if command == "create" {
+		if argument == "" {
+			return errors.New(migrateUsage)
+		}
+
+		paths, err := migration.Create(migration.Dir, argument)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			fmt.Println("Created", path)
+		}
+		return nil
+	}
+
+	steps := 1
+	switch command {
+	case "up", "status":
+		if argument != "" {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		if argument != "" {
+			n, err := strconv.Atoi(argument)
+			if err != nil || n < 1 {
+				return errors.New("steps must be a positive number")
+			}
+			steps = n
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	cfg, cfgErr := config.Load(args, os.LookupEnv)
+	if cfgErr != nil {
+		return cfgErr
+	}
+
+	pool, dbErr := database.Connect(cfg.Database)
+	if dbErr != nil {
+		return dbErr
+	}
+	defer pool.Close()
+
+	migrations, loadErr := migration.Embedded()
+	if loadErr != nil {
+		return loadErr
+	}
+	service := migration.InitService(migration.InitRepo(pool), migrations)
+
+	switch command {
+	case "up":
+		applied, err := service.Up()
+		for _, m := range applied {
+			fmt.Println("Applied", migration.Label(m))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+	case "down":
+		reverted, err := service.Down(steps)
+		for _, m := range reverted {
+			fmt.Println("Reverted", migration.Label(m))
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if len(reverted) == 0 {
+			fmt.Println("No applied migrations")
+		}
+	case "status":
+		statuses, err := service.Status(context.Background())
+		if err != nil {
+			return err
+		}
+
+		out := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(out, "MIGRATION\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+
+			if status.Unknown {
+				appliedAt += " (unknown to this version)"
+			}
+
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return out.Flush()
+	}
+
+	return nil
+}
@@ -1,88 +1,265 @@
 package main
 
 import (
-	"database/sql"
-	b64 "encoding/base64"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
 	"quiz-app/api/handlers"
 	"quiz-app/config"
+	"quiz-app/pkg/analytics"
+	"quiz-app/pkg/attempt"
+	"quiz-app/pkg/database"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/health"
+	"quiz-app/pkg/keyring"
+	"quiz-app/pkg/leaderboard"
+	"quiz-app/pkg/live"
+	"quiz-app/pkg/mailer"
 	"quiz-app/pkg/middleware"
 	accessCtrl "quiz-app/pkg/middleware/access-control"
+	"quiz-app/pkg/migration"
+	"quiz-app/pkg/quiz"
+	"quiz-app/pkg/stream"
 	"quiz-app/pkg/user"
+	"strconv"
+	"syscall"
 	"time"
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("No .env file found")
-	}
-}
+// version and commit are set when the server is linked, see health.ReadBuildInfo.
+var (
+	version = "dev"
+	commit  = ""
+)
 
 func main() {
 
-	// encode password for database connection
-	encodedPassword := string(b64.URLEncoding.EncodeToString([]byte(config.DBPassword)))
+	// migrate [up|down|status|create] manages the database schema instead of serving:
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// load configuration from the environment, a .env or YAML file and the flags:
+	cfg, cfgErr := config.Load(os.Args[1:], os.LookupEnv)
+	if cfgErr != nil {
+		log.Fatal(cfgErr)
+	}
+	log.Printf("Configuration:\n%s", cfg)
 
-	// determine ssl mode
-	sslMode := "disable"
-	if config.Env == "production" {
-		sslMode = "verify-full"
+	// connect to the database, waiting for it to come up:
+	pool, dbErr := database.Connect(cfg.Database)
+	if dbErr != nil {
+		log.Fatal(dbErr)
 	}
 
-	// connection string for db connection
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", config.DBUser, encodedPassword, config.DBHost, config.DBPort, config.DBName, sslMode)
-	// database connection
-	pool, err := sql.Open("postgres", connString)
-	// await database connection before continuing execution:
-	defer func(pool *sql.DB) {
-		err := pool.Close()
+	// apply pending migrations before anything uses the schema:
+	migrations, migrationErr := migration.Embedded()
+	if migrationErr != nil {
+		log.Fatal(migrationErr)
+	}
+	migrationService := migration.InitService(migration.InitRepo(pool), migrations)
+
+	if cfg.MigrateOnStart {
+		applied, err := migrationService.Up()
+		for _, m := range applied {
+			log.Println("Applied migration", migration.Label(m))
+		}
+
 		if err != nil {
-			log.Fatal("unable to close db pool")
+			log.Fatal(err)
 		}
-	}(pool)
+	}
 
-	// if error with database connection
-	if err != nil {
-		log.Fatal(err)
+	// load jwt signing and verification keys:
+	kr, keyErr := loadKeyring(cfg)
+	if keyErr != nil {
+		log.Fatal(keyErr)
+	}
+
+	// mail through smtp when configured, otherwise write mails to a log, which the configuration
+	// only allows outside of production:
+	var m mailer.Mailer
+	if cfg.Mail.SMTPHost != "" {
+		m = mailer.InitSMTPMailer(cfg.Mail.SMTPHost, strconv.Itoa(cfg.Mail.SMTPPort), cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
+	} else if cfg.Mail.LogFile != "" {
+		mailLog, err := os.OpenFile(cfg.Mail.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer mailLog.Close()
+		m = mailer.InitLogMailer(mailLog)
+	} else {
+		m = mailer.InitLogMailer(os.Stdout)
 	}
 
 	// define repositories:
 	accessCtrlRepo := accessCtrl.InitRepo(pool)
+	revocationStore := accessCtrl.InitPGRevocationStore(pool)
 	userRepo := user.InitRepo(pool)
+	quizRepo := quiz.InitRepo(pool)
+	attemptRepo := attempt.InitRepo(pool)
+	leaderboardRepo := leaderboard.InitRepo(pool)
+	analyticsRepo := analytics.InitRepo(pool)
 
 	// provide repository to services:
-	accessCtrlService := accessCtrl.InitService(accessCtrlRepo)
-	userService := user.InitService(userRepo)
+	accessCtrlService := accessCtrl.InitService(accessCtrlRepo, revocationStore, kr)
+	userService := user.InitService(userRepo, kr, m, cfg.PasswordResetURL)
+	quizService := quiz.InitService(quizRepo)
+	leaderboardService := leaderboard.InitService(leaderboardRepo, quizRepo)
+	streamService := stream.InitService(stream.NewBroker(), leaderboardService)
+	attemptService := attempt.InitService(attemptRepo, quizRepo, cfg.AttemptGracePeriod, streamService)
+	liveService := live.InitService(quizRepo, accessCtrlService)
+	analyticsService := analytics.InitService(analyticsRepo, quizRepo)
+
+	// the server is ready to take requests when the database answers on the latest schema:
+	healthService := health.InitService(cfg.HealthCheckTimeout)
+	healthService.Register(health.DatabaseChecker(pool))
+	healthService.Register(health.MigrationChecker(migrationService))
 
 	// create request multiplexer
 	router := mux.NewRouter()
 
-	http.Handle("/", middleware.Cors.Handler(router))
-
-	router.HandleFunc("/ping", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
-
 	// pass services to handlers (controllers):
 	handlers.UserHandlers(router, accessCtrlService, userService)
+	handlers.AdminHandlers(router, accessCtrlService, userService)
+	handlers.QuizHandlers(router, accessCtrlService, quizService)
+	handlers.QuestionBankHandlers(router, accessCtrlService, quizService)
+	handlers.AttemptHandlers(router, accessCtrlService, attemptService)
+	handlers.LeaderboardHandlers(router, accessCtrlService, leaderboardService)
+	handlers.LiveHandlers(router, accessCtrlService, quizService, liveService, cfg.RequestOriginURL)
+	handlers.StreamHandlers(router, accessCtrlService, quizService, streamService)
+	handlers.AnalyticsHandlers(router, accessCtrlService, quizService, analyticsService)
+	handlers.JwksHandlers(router, kr)
+	handlers.HealthHandlers(router, healthService, health.ReadBuildInfo(version, commit))
 
+	// event streams set their own write deadlines, see stream.Service.Serve:
 	server := &http.Server{
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
-		Addr:         fmt.Sprintf(":%s", config.Port),
-		Handler:      middleware.Cors.Handler(router),
+		Addr:         fmt.Sprintf(":%d", cfg.Port),
+		Handler:      middleware.NewCors(cfg.RequestOriginURL).Handler(router),
 	}
 
-	log.Println(fmt.Sprintf("Server to listen at port=%s", config.Port))
-	err = server.ListenAndServe()
+	// streams and live sessions would hold up the shutdown, so they are ended when it starts:
+	server.RegisterOnShutdown(streamService.Close)
+	server.RegisterOnShutdown(liveService.Close)
+
+	listener, err := net.Listen("tcp", server.Addr)
 	if err != nil {
 		log.Println("Unable to run server")
 		log.Fatal(err.Error())
 	}
 
-	log.Println(fmt.Sprintf("Server listening at port=%s", config.Port))
+	// shut down on ctrl-c and when the process is asked to stop, as on deploys:
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// readiness fails as soon as the signal arrives, and the server keeps taking requests for
+	// the shutdown delay, so that load balancers stop sending them before it closes:
+	draining, drained := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		healthService.ShutDown()
+		time.Sleep(cfg.ShutdownDelay)
+		drained()
+	}()
+
+	log.Println(fmt.Sprintf("Server listening at port=%d", cfg.Port))
+	// password reset mails still being sent need the database:
+	if err := serve(draining, server, listener, cfg.ShutdownTimeout, userService, pool); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("Server stopped")
+}
+
+// serve serves on the listener until ctx is done, then shuts the server down: it stops
+// accepting connections, runs the functions registered with RegisterOnShutdown and waits up to
+// timeout for the requests in flight to finish, before it cuts off the rest. The closers, such
+// as the database pool, are closed after that, in order.
+func serve(ctx context.Context, server *http.Server, listener net.Listener, timeout time.Duration, closers ...io.Closer) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		log.Println(fmt.Sprintf("Shutting down, waiting up to %s for requests to finish", timeout))
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if err = server.Shutdown(shutdownCtx); err != nil {
+			log.Println("Requests did not finish in time, closing their connections")
+			server.Close()
+		}
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	for _, closer := range closers {
+		if closeErr := closer.Close(); closeErr != nil {
+			log.Println(closeErr)
+			err = errors.Join(err, closeErr)
+		}
+	}
+
+	return err
+}
+
+// loadKeyring loads the jwt signing key and any retired keys that should still verify tokens.
+// Outside of production a throwaway key is generated when no signing key is configured.
+func loadKeyring(cfg *config.Config) (*keyring.Keyring, *entity.AppError) {
+	kr := keyring.New()
+
+	if cfg.Jwt.VerificationKeysDir != "" {
+		keys, err := keyring.LoadDir(cfg.Jwt.VerificationKeysDir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if err := kr.Add(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var signingKey *keyring.Key
+	var err *entity.AppError
+	if cfg.Jwt.SigningKeyFile != "" {
+		signingKey, err = keyring.LoadFile(cfg.Jwt.SigningKeyId, cfg.Jwt.SigningKeyFile)
+	} else {
+		log.Println("No JWT_SIGNING_KEY_FILE set, generating a temporary signing key")
+		signingKey, err = keyring.GenerateKey(fmt.Sprintf("dev-%d", time.Now().Unix()))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := kr.Add(signingKey); err != nil {
+		return nil, err
+	}
+
+	if err := kr.SetSigningKey(signingKey.Id); err != nil {
+		return nil, err
+	}
+
+	return kr, nil
 }
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"quiz-app/pkg/entity"
+	mockStream "quiz-app/pkg/mocks/stream"
+	"quiz-app/pkg/stream"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pool stands in for the database pool and records whether the requests had finished when it
+// was closed.
+type pool struct {
+	finished *atomic.Bool
+	closed   bool
+	drained  bool
+}
+
+func (p *pool) Close() error {
+	p.closed = true
+	p.drained = p.finished.Load()
+	return nil
+}
+
+// listen starts to serve the handler in-process and returns its url and the result of serve.
+func listen(t *testing.T, ctx context.Context, server *http.Server, timeout time.Duration, p *pool) (string, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- serve(ctx, server, listener, timeout, p)
+	}()
+
+	return "http://" + listener.Addr().String(), done
+}
+
+func TestServe(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	t.Run("serve should drain the requests in flight and end the streams when the process is interrupted", func(t *testing.T) {
+		mockLeaderboards := mockStream.NewMockLeaderboardReader(mockCtrl)
+		mockLeaderboards.EXPECT().GetQuizLeaderboard(int64(7), entity.LeaderboardAllTime, 10, int64(0)).Return(&entity.Leaderboard{QuizId: 7}, nil)
+		streamService := stream.InitService(stream.NewBroker(), mockLeaderboards)
+
+		started := make(chan struct{})
+		finished := &atomic.Bool{}
+
+		router := http.NewServeMux()
+		router.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("done"))
+			finished.Store(true)
+		})
+		router.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+			streamService.Serve(w, r, 7)
+		})
+
+		server := &http.Server{Handler: router}
+		server.RegisterOnShutdown(streamService.Close)
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		p := &pool{finished: finished}
+		url, done := listen(t, ctx, server, 5*time.Second, p)
+
+		events, err := http.Get(url + "/events")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer events.Body.Close()
+
+		reader := bufio.NewReader(events.Body)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(line, "data: ") {
+				break
+			}
+		}
+
+		slow := make(chan string, 1)
+		go func() {
+			res, err := http.Get(url + "/slow")
+			if err != nil {
+				slow <- err.Error()
+				return
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			slow <- string(body)
+		}()
+		<-started
+
+		process, _ := os.FindProcess(os.Getpid())
+		if err := process.Signal(os.Interrupt); err != nil {
+			t.Fatal(err)
+		}
+
+		// the stream ends rather than holding up the shutdown:
+		if _, err := io.ReadAll(reader); err != nil {
+			t.Error("stream", err)
+		}
+
+		if body := <-slow; body != "done" {
+			t.Error("slow request", body)
+		}
+
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+
+		if !p.closed || !p.drained {
+			t.Error("pool closed before the requests finished")
+		}
+
+		if conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://")); err == nil {
+			conn.Close()
+			t.Error("still accepting connections")
+		}
+	})
+
+	t.Run("serve should cut off the requests that outlast the timeout", func(t *testing.T) {
+		started := make(chan struct{})
+		finished := &atomic.Bool{}
+
+		server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+				finished.Store(true)
+			}
+		})}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		p := &pool{finished: finished}
+		url, done := listen(t, ctx, server, 50*time.Millisecond, p)
+
+		failed := make(chan error, 1)
+		go func() {
+			res, err := http.Get(url)
+			if err == nil {
+				res.Body.Close()
+			}
+			failed <- err
+		}()
+		<-started
+
+		cancel()
+
+		if err := <-done; !errors.Is(err, context.DeadlineExceeded) {
+			t.Error(err)
+		}
+
+		if err := <-failed; err == nil {
+			t.Error("the request was not cut off")
+		}
+
+		if !p.closed || p.drained {
+			t.Fail()
+		}
+	})
+}
@@ -1,33 +1,363 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/joho/godotenv"
-	"log"
+	"gopkg.in/yaml.v3"
 	"os"
+	"path/filepath"
+	"quiz-app/pkg/entity"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 )
 
-var DBName string
-var DBUser string
-var DBPassword string
-var DBHost string
-var DBPort string
-var Env string
-var Port string
-var RequestOriginURL string
-var UserPassword string
-
-func init() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("No .env file found")
-	}
-
-	DBName, _ = os.LookupEnv("DATABASE_NAME")
-	DBUser, _ = os.LookupEnv("DATABASE_USER")
-	DBPassword, _ = os.LookupEnv("DATABASE_PASSWORD")
-	DBHost, _ = os.LookupEnv("DATABASE_HOST")
-	DBPort, _ = os.LookupEnv("DATABASE_PORT")
-	Env, _ = os.LookupEnv("ENVIRONMENT")
-	Port, _ = os.LookupEnv("PORT")
-	RequestOriginURL, _ = os.LookupEnv("REQUEST_ORIGIN_URL")
-	UserPassword, _ = os.LookupEnv("USER_PASSWORD")
+const (
+	defaultFile = ".env"
+	redacted    = "******"
+	production  = "production"
+)
+
+var sslModes = map[string]bool{"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true}
+
+// Config is the configuration of the server. Every field is read from the environment variable
+// in its env tag, from the key in its yaml tag of a YAML file or from the command-line flag in its
+// flag tag, and falls back to its default. Required fields have to be set one way or another,
+// and secret fields are redacted when the configuration is printed.
+type Config struct {
+	Env                string         `env:"ENVIRONMENT" yaml:"environment" flag:"env" default:"development"`
+	Port               int            `env:"PORT" yaml:"port" flag:"port" default:"8080"`
+	RequestOriginURL   string         `env:"REQUEST_ORIGIN_URL" yaml:"requestOriginUrl" flag:"request-origin-url" required:"true"`
+	PasswordResetURL   string         `env:"PASSWORD_RESET_URL" yaml:"passwordResetUrl" flag:"password-reset-url"`
+	AttemptGracePeriod time.Duration  `env:"ATTEMPT_GRACE_PERIOD" yaml:"attemptGracePeriod" flag:"attempt-grace-period" default:"5s"`
+	ShutdownTimeout    time.Duration  `env:"SHUTDOWN_TIMEOUT" yaml:"shutdownTimeout" flag:"shutdown-timeout" default:"15s"`
+	ShutdownDelay      time.Duration  `env:"SHUTDOWN_DELAY" yaml:"shutdownDelay" flag:"shutdown-delay"`
+	HealthCheckTimeout time.Duration  `env:"HEALTH_CHECK_TIMEOUT" yaml:"healthCheckTimeout" flag:"health-check-timeout" default:"2s"`
+	MigrateOnStart     bool           `env:"MIGRATE_ON_START" yaml:"migrateOnStart" flag:"migrate-on-start"`
+	Database           DatabaseConfig `yaml:"database"`
+	Jwt                JwtConfig      `yaml:"jwt"`
+	Mail               MailConfig     `yaml:"mail"`
+}
+
+// DatabaseConfig is the connection to Postgres, see database.Connect. Host may also be the
+// directory of a unix socket. SSLMode defaults to verify-full in production and to disable
+// otherwise.
+type DatabaseConfig struct {
+	Host            string        `env:"DATABASE_HOST" yaml:"host" flag:"db-host" required:"true"`
+	Port            int           `env:"DATABASE_PORT" yaml:"port" flag:"db-port" default:"5432"`
+	Name            string        `env:"DATABASE_NAME" yaml:"name" flag:"db-name" required:"true"`
+	User            string        `env:"DATABASE_USER" yaml:"user" flag:"db-user" required:"true"`
+	Password        string        `env:"DATABASE_PASSWORD" yaml:"password" flag:"db-password" secret:"true"`
+	SSLMode         string        `env:"DATABASE_SSL_MODE" yaml:"sslMode" flag:"db-ssl-mode"`
+	SSLRootCert     string        `env:"DATABASE_SSL_ROOT_CERT" yaml:"sslRootCert" flag:"db-ssl-root-cert"`
+	ApplicationName string        `env:"DATABASE_APPLICATION_NAME" yaml:"applicationName" flag:"db-application-name" default:"quiz-app"`
+	SearchPath      string        `env:"DATABASE_SEARCH_PATH" yaml:"searchPath" flag:"db-search-path"`
+	MaxOpenConns    int           `env:"DATABASE_MAX_OPEN_CONNS" yaml:"maxOpenConns" flag:"db-max-open-conns" default:"25"`
+	MaxIdleConns    int           `env:"DATABASE_MAX_IDLE_CONNS" yaml:"maxIdleConns" flag:"db-max-idle-conns" default:"10"`
+	ConnMaxLifetime time.Duration `env:"DATABASE_CONN_MAX_LIFETIME" yaml:"connMaxLifetime" flag:"db-conn-max-lifetime" default:"30m"`
+	ConnMaxIdleTime time.Duration `env:"DATABASE_CONN_MAX_IDLE_TIME" yaml:"connMaxIdleTime" flag:"db-conn-max-idle-time" default:"5m"`
+	ConnectTimeout  time.Duration `env:"DATABASE_CONNECT_TIMEOUT" yaml:"connectTimeout" flag:"db-connect-timeout" default:"1m"`
+}
+
+// JwtConfig names the signing key and the directory of retired keys that still verify tokens.
+// Outside of production a throwaway signing key is generated when none is configured.
+type JwtConfig struct {
+	SigningKeyId        string `env:"JWT_SIGNING_KEY_ID" yaml:"signingKeyId" flag:"jwt-signing-key-id"`
+	SigningKeyFile      string `env:"JWT_SIGNING_KEY_FILE" yaml:"signingKeyFile" flag:"jwt-signing-key-file"`
+	VerificationKeysDir string `env:"JWT_VERIFICATION_KEYS_DIR" yaml:"verificationKeysDir" flag:"jwt-verification-keys-dir"`
+}
+
+// MailConfig sends mail through SMTPHost when it is set, and otherwise writes mails to LogFile
+// or, without one, to the standard output. Mails carry password reset links, so production
+// requires SMTPHost.
+type MailConfig struct {
+	SMTPHost     string `env:"SMTP_HOST" yaml:"smtpHost" flag:"smtp-host"`
+	SMTPPort     int    `env:"SMTP_PORT" yaml:"smtpPort" flag:"smtp-port" default:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME" yaml:"smtpUsername" flag:"smtp-username"`
+	SMTPPassword string `env:"SMTP_PASSWORD" yaml:"smtpPassword" flag:"smtp-password" secret:"true"`
+	From         string `env:"MAIL_FROM" yaml:"from" flag:"mail-from"`
+	LogFile      string `env:"MAIL_LOG_FILE" yaml:"logFile" flag:"mail-log-file"`
+}
+
+// IsProduction reports whether the server runs in production.
+func (c *Config) IsProduction() bool {
+	return c.Env == production
+}
+
+// Load reads the configuration from, in increasing precedence, the defaults, a .env or YAML
+// file, the environment and the command-line flags in args, and validates it. The file is named
+// by the -config flag or CONFIG_FILE, and is otherwise the .env file of the working directory
+// when there is one. Empty values count as not set, like the ones in .env.example.
+func Load(args []string, lookupEnv func(key string) (string, bool)) (*Config, *entity.AppError) {
+	cfg := &Config{}
+	fields := fieldsOf(reflect.ValueOf(cfg).Elem())
+	var problems []string
+
+	for _, f := range fields {
+		if f.def != "" {
+			if err := f.set(f.def); err != nil {
+				return nil, entity.NewAppError(err)
+			}
+		}
+	}
+
+	flags := flag.NewFlagSet("quiz-app", flag.ContinueOnError)
+	file := flags.String("config", "", "a .env or YAML configuration `file`")
+	for _, f := range fields {
+		if f.value.Kind() == reflect.Bool {
+			flags.Bool(f.flag, false, "overrides "+f.env)
+		} else {
+			flags.String(f.flag, "", "overrides "+f.env)
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if *file == "" {
+		*file, _ = lookupEnv("CONFIG_FILE")
+	}
+
+	fileEnv, err := readFile(cfg, *file)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fields {
+		value, ok := lookupEnv(f.env)
+		if !ok || value == "" {
+			value = fileEnv[f.env]
+		}
+
+		if value != "" {
+			problems = appendProblem(problems, f.set(value))
+		}
+	}
+
+	flags.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if f.flag == fl.Name && fl.Value.String() != "" {
+				problems = appendProblem(problems, f.set(fl.Value.String()))
+			}
+		}
+	})
+
+	if cfg.Database.SSLMode == "" {
+		cfg.Database.SSLMode = "disable"
+		if cfg.IsProduction() {
+			cfg.Database.SSLMode = "verify-full"
+		}
+	}
+
+	problems = append(problems, cfg.validate(fields)...)
+	if len(problems) > 0 {
+		return nil, entity.NewAppError(fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; ")))
+	}
+
+	return cfg, nil
+}
+
+// String lists the configuration by environment variable with the secrets redacted, for the
+// startup log.
+func (c *Config) String() string {
+	var b strings.Builder
+
+	for _, f := range fieldsOf(reflect.ValueOf(c).Elem()) {
+		value := f.String()
+		if f.secret && value != "" {
+			value = redacted
+		}
+
+		fmt.Fprintf(&b, "%s=%s\n", f.env, value)
+	}
+
+	return b.String()
+}
+
+// validate returns the problems with the configuration.
+func (c *Config) validate(fields []field) []string {
+	problems := make([]string, 0)
+
+	for _, f := range fields {
+		if f.required && f.value.IsZero() {
+			problems = append(problems, f.env+" is required")
+		}
+	}
+
+	ports := map[string]int{"PORT": c.Port, "DATABASE_PORT": c.Database.Port, "SMTP_PORT": c.Mail.SMTPPort}
+	for _, name := range []string{"PORT", "DATABASE_PORT", "SMTP_PORT"} {
+		if ports[name] < 1 || ports[name] > 65535 {
+			problems = append(problems, name+" must be a port between 1 and 65535")
+		}
+	}
+
+	if c.AttemptGracePeriod < 0 {
+		problems = append(problems, "ATTEMPT_GRACE_PERIOD cannot be negative")
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		problems = append(problems, "SHUTDOWN_TIMEOUT must be positive")
+	}
+
+	if c.ShutdownDelay < 0 {
+		problems = append(problems, "SHUTDOWN_DELAY cannot be negative")
+	}
+
+	if c.HealthCheckTimeout <= 0 {
+		problems = append(problems, "HEALTH_CHECK_TIMEOUT must be positive")
+	}
+
+	if !sslModes[c.Database.SSLMode] {
+		problems = append(problems, "DATABASE_SSL_MODE must be one of disable, allow, prefer, require, verify-ca or verify-full")
+	}
+
+	// migrations hold a connection for their lock while they run on another:
+	if c.Database.MaxOpenConns < 2 {
+		problems = append(problems, "DATABASE_MAX_OPEN_CONNS must be at least 2")
+	}
+
+	if c.Database.MaxIdleConns < 0 || c.Database.ConnMaxLifetime < 0 || c.Database.ConnMaxIdleTime < 0 {
+		problems = append(problems, "DATABASE_MAX_IDLE_CONNS, DATABASE_CONN_MAX_LIFETIME and DATABASE_CONN_MAX_IDLE_TIME cannot be negative")
+	}
+
+	if c.Database.ConnectTimeout <= 0 {
+		problems = append(problems, "DATABASE_CONNECT_TIMEOUT must be positive")
+	}
+
+	if c.IsProduction() && c.Jwt.SigningKeyFile == "" {
+		problems = append(problems, "JWT_SIGNING_KEY_FILE is required in production")
+	}
+
+	// reset links are as good as a password, they must neither end up in a log nor lack a page:
+	if c.IsProduction() && c.Mail.SMTPHost == "" {
+		problems = append(problems, "SMTP_HOST is required in production")
+	}
+
+	if c.IsProduction() && c.PasswordResetURL == "" {
+		problems = append(problems, "PASSWORD_RESET_URL is required in production")
+	}
+
+	if c.Mail.SMTPHost != "" && c.Mail.From == "" {
+		problems = append(problems, "MAIL_FROM is required to send mail through SMTP_HOST")
+	}
+
+	return problems
+}
+
+// readFile reads a YAML file into cfg, or returns the variables of a .env file. A missing
+// default .env file is fine, a missing file that was asked for is not.
+func readFile(cfg *Config, path string) (map[string]string, *entity.AppError) {
+	optional := path == ""
+	if optional {
+		path = defaultFile
+	}
+
+	data, err := os.ReadFile(path)
+	if optional && errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, entity.NewAppError(fmt.Errorf("%s: %w", path, err))
+		}
+
+		return nil, nil
+	default:
+		env, err := godotenv.Unmarshal(string(data))
+		if err != nil {
+			return nil, entity.NewAppError(fmt.Errorf("%s: %w", path, err))
+		}
+
+		return env, nil
+	}
+}
+
+func appendProblem(problems []string, err error) []string {
+	if err != nil {
+		return append(problems, err.Error())
+	}
+
+	return problems
+}
+
+// field is a configuration value with the settings of its struct tags.
+type field struct {
+	value    reflect.Value
+	env      string
+	flag     string
+	def      string
+	required bool
+	secret   bool
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fieldsOf returns the fields of the struct v and of the structs it holds, in their order.
+func fieldsOf(v reflect.Value) []field {
+	fields := make([]field, 0)
+
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i).Tag
+		if v.Field(i).Kind() == reflect.Struct {
+			fields = append(fields, fieldsOf(v.Field(i))...)
+			continue
+		}
+
+		fields = append(fields, field{
+			value:    v.Field(i),
+			env:      tag.Get("env"),
+			flag:     tag.Get("flag"),
+			def:      tag.Get("default"),
+			required: tag.Get("required") == "true",
+			secret:   tag.Get("secret") == "true",
+		})
+	}
+
+	return fields
+}
+
+// set parses the text into the field by the type of the field.
+func (f field) set(text string) error {
+	switch {
+	case f.value.Type() == durationType:
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return fmt.Errorf("%s must be a duration such as 5s, not %q", f.env, text)
+		}
+		f.value.SetInt(int64(d))
+	case f.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return fmt.Errorf("%s must be a number, not %q", f.env, text)
+		}
+		f.value.SetInt(int64(n))
+	case f.value.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, not %q", f.env, text)
+		}
+		f.value.SetBool(b)
+	default:
+		f.value.SetString(text)
+	}
+
+	return nil
+}
+
+func (f field) String() string {
+	if f.value.Type() == durationType {
+		return time.Duration(f.value.Int()).String()
+	}
+
+	return fmt.Sprint(f.value.Interface())
 }
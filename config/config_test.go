@@ -0,0 +1,196 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func required() map[string]string {
+	return map[string]string{
+		"DATABASE_HOST":      "localhost",
+		"DATABASE_NAME":      "quiz",
+		"DATABASE_USER":      "quiz",
+		"REQUEST_ORIGIN_URL": "http://localhost:3000",
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// inTempDir runs the test in an empty working directory, to keep a .env file out of it.
+func inTempDir(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+func TestLoad(t *testing.T) {
+
+	inTempDir(t)
+
+	t.Run("Load should fall back to the defaults", func(t *testing.T) {
+		cfg, err := Load(nil, lookup(required()))
+
+		if err != nil || cfg.Env != "development" || cfg.Port != 8080 || cfg.Database.Port != 5432 || cfg.AttemptGracePeriod != 5*time.Second || cfg.ShutdownTimeout != 15*time.Second ||
+			cfg.Database.SSLMode != "disable" || cfg.Database.MaxOpenConns != 25 || cfg.Database.ConnectTimeout != time.Minute {
+			t.Fatal(err, cfg)
+		}
+	})
+
+	t.Run("Load should parse numbers and durations from the environment", func(t *testing.T) {
+		env := required()
+		env["PORT"] = "9090"
+		env["ATTEMPT_GRACE_PERIOD"] = "1m30s"
+
+		cfg, err := Load(nil, lookup(env))
+
+		if err != nil || cfg.Port != 9090 || cfg.AttemptGracePeriod != 90*time.Second {
+			t.Fatal(err, cfg)
+		}
+	})
+
+	t.Run("Load should treat empty variables as not set", func(t *testing.T) {
+		env := required()
+		env["PORT"] = ""
+
+		cfg, err := Load(nil, lookup(env))
+
+		if err != nil || cfg.Port != 8080 {
+			t.Fatal(err, cfg)
+		}
+	})
+
+	t.Run("Load should report every missing and invalid value at once", func(t *testing.T) {
+		env := map[string]string{"PORT": "http", "ATTEMPT_GRACE_PERIOD": "-1s", "DATABASE_PORT": "70000", "SHUTDOWN_TIMEOUT": "0s", "DATABASE_SSL_MODE": "sometimes"}
+
+		_, err := Load(nil, lookup(env))
+
+		if err == nil {
+			t.Fatal()
+		}
+
+		for _, problem := range []string{"DATABASE_HOST is required", "REQUEST_ORIGIN_URL is required", `PORT must be a number, not "http"`,
+			"DATABASE_PORT must be a port", "ATTEMPT_GRACE_PERIOD cannot be negative", "SHUTDOWN_TIMEOUT must be positive",
+			"DATABASE_SSL_MODE must be one of"} {
+			if !strings.Contains(err.Error(), problem) {
+				t.Error(problem, "missing from", err)
+			}
+		}
+	})
+
+	t.Run("Load should require a signing key and mail sender where they are needed", func(t *testing.T) {
+		env := required()
+		env["ENVIRONMENT"] = "production"
+		env["SMTP_HOST"] = "smtp.example.com"
+
+		_, err := Load(nil, lookup(env))
+
+		if err == nil || !strings.Contains(err.Error(), "JWT_SIGNING_KEY_FILE is required in production") || !strings.Contains(err.Error(), "MAIL_FROM is required") {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Load should require an SMTP server and a reset page in production", func(t *testing.T) {
+		env := required()
+		env["ENVIRONMENT"] = "production"
+
+		_, err := Load(nil, lookup(env))
+
+		if err == nil || !strings.Contains(err.Error(), "SMTP_HOST is required in production") || !strings.Contains(err.Error(), "PASSWORD_RESET_URL is required in production") {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Load should let the environment override a .env file and the flags override both", func(t *testing.T) {
+		path := writeFile(t, "quiz.env", "DATABASE_HOST=db\nDATABASE_NAME=file\nPORT=7070\n")
+		env := map[string]string{"DATABASE_USER": "quiz", "REQUEST_ORIGIN_URL": "http://localhost:3000", "DATABASE_NAME": "env", "PORT": "6060"}
+
+		cfg, err := Load([]string{"-config", path, "-port", "5050", "-migrate-on-start"}, lookup(env))
+
+		if err != nil || cfg.Database.Host != "db" || cfg.Database.Name != "env" || cfg.Port != 5050 || !cfg.MigrateOnStart {
+			t.Fatal(err, cfg)
+		}
+	})
+
+	t.Run("Load should read a YAML file named by CONFIG_FILE", func(t *testing.T) {
+		path := writeFile(t, "quiz.yaml", "port: 7070\nattemptGracePeriod: 2s\ndatabase:\n  host: db\n  password: secret\n")
+		env := required()
+		delete(env, "DATABASE_HOST")
+		env["CONFIG_FILE"] = path
+
+		cfg, err := Load(nil, lookup(env))
+
+		if err != nil || cfg.Port != 7070 || cfg.AttemptGracePeriod != 2*time.Second || cfg.Database.Host != "db" || cfg.Database.Password != "secret" {
+			t.Fatal(err, cfg)
+		}
+	})
+
+	t.Run("Load should read the .env file of the working directory when there is one", func(t *testing.T) {
+		if err := os.WriteFile(".env", []byte("PORT=7070\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(".env")
+
+		cfg, err := Load(nil, lookup(required()))
+
+		if err != nil || cfg.Port != 7070 {
+			t.Fatal(err, cfg)
+		}
+	})
+
+	t.Run("Load should fail on a configuration file that does not exist", func(t *testing.T) {
+		if _, err := Load([]string{"-config", "missing.yaml"}, lookup(required())); err == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Load should fail on an unknown flag", func(t *testing.T) {
+		if _, err := Load([]string{"-unknown", "1"}, lookup(required())); err == nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestString(t *testing.T) {
+
+	inTempDir(t)
+
+	t.Run("String should list the configuration with the secrets redacted", func(t *testing.T) {
+		env := required()
+		env["DATABASE_PASSWORD"] = "hunter2"
+
+		cfg, _ := Load(nil, lookup(env))
+		dump := cfg.String()
+
+		if strings.Contains(dump, "hunter2") || !strings.Contains(dump, "DATABASE_PASSWORD=******") ||
+			!strings.Contains(dump, "DATABASE_HOST=localhost") || !strings.Contains(dump, "ATTEMPT_GRACE_PERIOD=5s") ||
+			!strings.Contains(dump, "SMTP_PASSWORD=\n") {
+			t.Fatal(dump)
+		}
+	})
+}
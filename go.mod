@@ -5,8 +5,11 @@ go 1.21.4
 require (
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gorilla/mux v1.8.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
 	github.com/rs/cors v1.10.1
 	go.uber.org/mock v0.3.0
 	golang.org/x/crypto v0.16.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,15 @@
+package analytics
+
+import (
+	"quiz-app/pkg/entity"
+)
+
+type Reader interface {
+	FindSubmittedAttempts(quiz_id int64) ([]*entity.Attempt, *entity.AppError)
+	FindItemResponses(quiz_id int64) ([]*entity.ItemResponse, *entity.AppError)
+}
+
+// Repository interface
+type Repository interface {
+	Reader
+}
@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"database/sql"
+	"encoding/json"
+	"quiz-app/pkg/entity"
+)
+
+type PGRepository struct {
+	pool *sql.DB
+}
+
+func InitRepo(p *sql.DB) *PGRepository {
+	return &PGRepository{
+		pool: p,
+	}
+}
+
+// FindSubmittedAttempts returns the submitted attempts at the quiz with the questions they drew
+// from the question bank.
+func (r PGRepository) FindSubmittedAttempts(quizId int64) ([]*entity.Attempt, *entity.AppError) {
+	query := "select id, quiz_id, user_id, status, score, max_score, started_at, submitted_at " +
+		"from attempts where quiz_id=$1 and status=$2 order by id"
+
+	rows, err := r.pool.Query(query, quizId, entity.AttemptSubmitted)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	attempts := make([]*entity.Attempt, 0)
+	byId := make(map[int64]*entity.Attempt)
+	for rows.Next() {
+		var attempt entity.Attempt
+		var submittedAt sql.NullTime
+
+		if err := rows.Scan(&attempt.Id, &attempt.QuizId, &attempt.UserId, &attempt.Status, &attempt.Score,
+			&attempt.MaxScore, &attempt.StartedAt, &submittedAt); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		if submittedAt.Valid {
+			attempt.SubmittedAt = &submittedAt.Time
+		}
+
+		attempts = append(attempts, &attempt)
+		byId[attempt.Id] = &attempt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if err := r.findDraws(quizId, byId); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return attempts, nil
+}
+
+// findDraws adds the questions the submitted attempts at the quiz drew to the attempts by id.
+func (r PGRepository) findDraws(quizId int64, byId map[int64]*entity.Attempt) error {
+	query := "select d.attempt_id, d.question_id from attempt_draws d join attempts a on a.id = d.attempt_id " +
+		"where a.quiz_id=$1 and a.status=$2 order by d.attempt_id, d.position"
+
+	rows, err := r.pool.Query(query, quizId, entity.AttemptSubmitted)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var attemptId, questionId int64
+		if err := rows.Scan(&attemptId, &questionId); err != nil {
+			return err
+		}
+
+		if attempt, ok := byId[attemptId]; ok {
+			attempt.DrawnQuestionIds = append(attempt.DrawnQuestionIds, questionId)
+		}
+	}
+
+	return rows.Err()
+}
+
+// FindItemResponses returns the answers of the submitted attempts at the quiz, with the time
+// timed questions were opened, ordered by attempt and time of answering.
+func (r PGRepository) FindItemResponses(quizId int64) ([]*entity.ItemResponse, *entity.AppError) {
+	query := "select aa.attempt_id, aa.question_id, aa.response, aa.score, aa.answered_at, aq.opened_at " +
+		"from attempt_answers aa join attempts a on a.id = aa.attempt_id " +
+		"left join attempt_questions aq on aq.attempt_id = aa.attempt_id and aq.question_id = aa.question_id " +
+		"where a.quiz_id=$1 and a.status=$2 order by aa.attempt_id, aa.answered_at"
+
+	rows, err := r.pool.Query(query, quizId, entity.AttemptSubmitted)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	responses := make([]*entity.ItemResponse, 0)
+	for rows.Next() {
+		var response entity.ItemResponse
+		var data []byte
+		var openedAt sql.NullTime
+
+		if err := rows.Scan(&response.AttemptId, &response.QuestionId, &data, &response.Score, &response.AnsweredAt, &openedAt); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		if err := json.Unmarshal(data, &response.Response); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		if openedAt.Valid {
+			response.OpenedAt = &openedAt.Time
+		}
+
+		responses = append(responses, &response)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return responses, nil
+}
@@ -0,0 +1,331 @@
+package analytics
+
+import (
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/quiz"
+	"sort"
+)
+
+// Thresholds of the item flags. Questions that almost everyone or almost no one gets right
+// tell little apart, as do questions whose score hardly goes along with the rest of the quiz.
+const (
+	easyPValue        = 0.9
+	hardPValue        = 0.3
+	minPointBiserial  = 0.2
+	minReliableItems  = 2
+	minReliableScores = 2
+)
+
+type Service struct {
+	repo    Repository
+	quizzes quiz.Reader
+}
+
+func InitService(r Repository, q quiz.Reader) *Service {
+	return &Service{
+		repo:    r,
+		quizzes: q,
+	}
+}
+
+// GetItemAnalysis computes the item analysis of the quiz from its submitted attempts: for every
+// question of the quiz, and every question drawn from the question bank, its difficulty,
+// discrimination, option selection rates and time spent, and the reliability of the quiz.
+func (s *Service) GetItemAnalysis(quizId int64) (*entity.ItemAnalysis, *entity.AppError) {
+
+	q, err := s.quizzes.FindByID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.quizzes.FindQuestionsByQuizID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.repo.FindSubmittedAttempts(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses, err := s.repo.FindItemResponses(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	drawn, err := s.findDrawnQuestions(q, attempts)
+	if err != nil {
+		return nil, err
+	}
+
+	return analyze(quizId, questions, drawn, attempts, responses), nil
+}
+
+// findDrawnQuestions returns the questions of the question bank the attempts drew, in the
+// order they were first drawn. Questions deleted from the bank since are left out.
+func (s *Service) findDrawnQuestions(q *entity.Quiz, attempts []*entity.Attempt) ([]*entity.Question, *entity.AppError) {
+	ids := make([]int64, 0)
+	seen := make(map[int64]bool)
+	for _, attempt := range attempts {
+		for _, id := range attempt.DrawnQuestionIds {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	bank, err := s.quizzes.FindBankQuestions(&entity.BankQuery{OwnerId: q.OwnerId})
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int64]*entity.Question)
+	for _, question := range bank {
+		byId[question.Id] = question
+	}
+
+	drawn := make([]*entity.Question, 0)
+	for _, id := range ids {
+		if question, ok := byId[id]; ok {
+			drawn = append(drawn, question)
+		}
+	}
+
+	return drawn, nil
+}
+
+// analyze computes the report. Unanswered questions score 0, like they do when an attempt is
+// submitted, and the score of an attempt on the other questions is its total minus the question.
+func analyze(quizId int64, questions []*entity.Question, drawn []*entity.Question, attempts []*entity.Attempt, responses []*entity.ItemResponse) *entity.ItemAnalysis {
+
+	answers := make(map[int64]map[int64]*entity.ItemResponse)
+	for _, response := range responses {
+		if answers[response.AttemptId] == nil {
+			answers[response.AttemptId] = make(map[int64]*entity.ItemResponse)
+		}
+		answers[response.AttemptId][response.QuestionId] = response
+	}
+
+	seconds := timeOnQuestions(attempts, responses)
+
+	report := &entity.ItemAnalysis{
+		QuizId:   quizId,
+		Attempts: len(attempts),
+		Items:    make([]*entity.ItemStatistics, 0, len(questions)+len(drawn)),
+	}
+
+	totals := make([]float64, len(attempts))
+	for i, attempt := range attempts {
+		totals[i] = attempt.Score
+	}
+	report.MeanScore = mean(totals)
+
+	for _, question := range questions {
+		report.Items = append(report.Items, analyzeItem(question, false, attempts, answers, seconds))
+	}
+
+	for _, question := range drawn {
+		presented := make([]*entity.Attempt, 0)
+		for _, attempt := range attempts {
+			if hasDrawn(attempt, question.Id) {
+				presented = append(presented, attempt)
+			}
+		}
+
+		report.Items = append(report.Items, analyzeItem(question, true, presented, answers, seconds))
+	}
+
+	report.CronbachAlpha, report.KR20 = reliability(questions, attempts, answers)
+	return report
+}
+
+func analyzeItem(question *entity.Question, drawn bool, attempts []*entity.Attempt, answers map[int64]map[int64]*entity.ItemResponse, seconds map[*entity.ItemResponse]float64) *entity.ItemStatistics {
+
+	item := &entity.ItemStatistics{
+		QuestionId: question.Id,
+		Text:       question.Text,
+		Type:       question.Type,
+		Drawn:      drawn,
+		Attempts:   len(attempts),
+		Flags:      make([]string, 0),
+	}
+
+	selected := make(map[int64]int)
+	scores := make([]float64, len(attempts))
+	rest := make([]float64, len(attempts))
+	times := make([]float64, 0)
+
+	for i, attempt := range attempts {
+		response, ok := answers[attempt.Id][question.Id]
+		if ok {
+			item.Answered++
+			scores[i] = response.Score
+
+			for _, optionId := range response.Response.OptionIds {
+				selected[optionId]++
+			}
+
+			if t, ok := seconds[response]; ok {
+				times = append(times, t)
+			}
+		}
+
+		rest[i] = attempt.Score - scores[i]
+	}
+
+	if len(attempts) > 0 {
+		pValue := mean(scores)
+		item.PValue = &pValue
+	}
+
+	item.PointBiserial = correlation(scores, rest)
+
+	if len(times) > 0 {
+		average := mean(times)
+		item.AverageSeconds = &average
+	}
+
+	if isChoice(question.Type) {
+		for _, option := range question.Options {
+			stats := &entity.OptionStatistics{OptionId: option.Id, Text: option.Text, IsCorrect: option.IsCorrect, Selected: selected[option.Id]}
+			if len(attempts) > 0 {
+				stats.Rate = float64(stats.Selected) / float64(len(attempts))
+			}
+			item.Options = append(item.Options, stats)
+		}
+	}
+
+	item.Flags = flags(item)
+	return item
+}
+
+func flags(item *entity.ItemStatistics) []string {
+	flags := make([]string, 0)
+
+	if item.PValue != nil && *item.PValue > easyPValue {
+		flags = append(flags, entity.ItemTooEasy)
+	}
+
+	if item.PValue != nil && *item.PValue < hardPValue {
+		flags = append(flags, entity.ItemTooHard)
+	}
+
+	if item.PointBiserial != nil && *item.PointBiserial < minPointBiserial {
+		flags = append(flags, entity.ItemLowDiscrimination)
+	}
+
+	// a wrong option that is picked more often than every correct one:
+	mostCorrect, mostWrong := 0, 0
+	for _, option := range item.Options {
+		if option.IsCorrect && option.Selected > mostCorrect {
+			mostCorrect = option.Selected
+		}
+		if !option.IsCorrect && option.Selected > mostWrong {
+			mostWrong = option.Selected
+		}
+	}
+
+	if mostWrong > mostCorrect {
+		flags = append(flags, entity.ItemMisleading)
+	}
+
+	return flags
+}
+
+// reliability returns Cronbach's alpha of the questions of the quiz, and KR-20, which is the
+// same coefficient for questions that are scored right or wrong, when all scores are 0 or 1.
+func reliability(questions []*entity.Question, attempts []*entity.Attempt, answers map[int64]map[int64]*entity.ItemResponse) (*float64, *float64) {
+	if len(questions) < minReliableItems || len(attempts) < minReliableScores {
+		return nil, nil
+	}
+
+	dichotomous := true
+	totals := make([]float64, len(attempts))
+	itemVariances := 0.0
+
+	for _, question := range questions {
+		scores := make([]float64, len(attempts))
+		for i, attempt := range attempts {
+			if response, ok := answers[attempt.Id][question.Id]; ok {
+				scores[i] = response.Score
+			}
+
+			if scores[i] != 0 && scores[i] != 1 {
+				dichotomous = false
+			}
+			totals[i] += scores[i]
+		}
+
+		itemVariances += variance(scores)
+	}
+
+	totalVariance := variance(totals)
+	if totalVariance == 0 {
+		return nil, nil
+	}
+
+	k := float64(len(questions))
+	alpha := k / (k - 1) * (1 - itemVariances/totalVariance)
+	if !dichotomous {
+		return &alpha, nil
+	}
+
+	kr20 := alpha
+	return &alpha, &kr20
+}
+
+// timeOnQuestions returns the seconds spent on every answer: since the question was opened,
+// or for questions that are not opened, since the previous answer or the start of the attempt.
+func timeOnQuestions(attempts []*entity.Attempt, responses []*entity.ItemResponse) map[*entity.ItemResponse]float64 {
+	byAttempt := make(map[int64][]*entity.ItemResponse)
+	for _, response := range responses {
+		byAttempt[response.AttemptId] = append(byAttempt[response.AttemptId], response)
+	}
+
+	seconds := make(map[*entity.ItemResponse]float64)
+	for _, attempt := range attempts {
+		answered := byAttempt[attempt.Id]
+		sort.SliceStable(answered, func(i, j int) bool {
+			return answered[i].AnsweredAt.Before(answered[j].AnsweredAt)
+		})
+
+		previous := attempt.StartedAt
+		for _, response := range answered {
+			start := previous
+			if response.OpenedAt != nil {
+				start = *response.OpenedAt
+			}
+
+			if elapsed := response.AnsweredAt.Sub(start).Seconds(); elapsed >= 0 {
+				seconds[response] = elapsed
+			}
+			previous = response.AnsweredAt
+		}
+	}
+
+	return seconds
+}
+
+func hasDrawn(attempt *entity.Attempt, questionId int64) bool {
+	for _, id := range attempt.DrawnQuestionIds {
+		if id == questionId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isChoice(questionType string) bool {
+	switch questionType {
+	case entity.QuestionSingleChoice, entity.QuestionMultipleChoice, entity.QuestionTrueFalse:
+		return true
+	}
+
+	return false
+}
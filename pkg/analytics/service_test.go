@@ -0,0 +1,210 @@
+package analytics
+
+import (
+	"go.uber.org/mock/gomock"
+	"math"
+	"quiz-app/pkg/entity"
+	mockAnalytics "quiz-app/pkg/mocks/analytics"
+	mockQuiz "quiz-app/pkg/mocks/quiz"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func choiceQuestion(id int64, correct int64, options ...int64) *entity.Question {
+	question := &entity.Question{Id: id, QuizId: 7, Type: entity.QuestionSingleChoice}
+	for _, option := range options {
+		question.Options = append(question.Options, &entity.AnswerOption{Id: option, IsCorrect: option == correct})
+	}
+
+	return question
+}
+
+func isClose(actual *float64, expected float64) bool {
+	return actual != nil && math.Abs(*actual-expected) < 1e-9
+}
+
+func TestGetItemAnalysis(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAnalytics.NewMockRepository(mockCtrl)
+	mockQuizzes := mockQuiz.NewMockReader(mockCtrl)
+	service := InitService(mockRepo, mockQuizzes)
+
+	startedAt := time.Date(2024, time.May, 15, 13, 0, 0, 0, time.UTC)
+	openedAt := startedAt.Add(25 * time.Second)
+
+	questions := []*entity.Question{
+		choiceQuestion(1, 1, 1, 2, 3),
+		choiceQuestion(2, 4, 4, 5),
+		choiceQuestion(3, 7, 7, 8, 9),
+	}
+
+	// the selected option of every attempt at the questions, 0 for unanswered:
+	selections := [][]int64{
+		{1, 4, 7},
+		{1, 4, 8},
+		{1, 5, 8},
+		{2, 4, 0},
+		{1, 0, 8},
+	}
+
+	attempts := make([]*entity.Attempt, 0)
+	responses := make([]*entity.ItemResponse, 0)
+	for i, selected := range selections {
+		attempt := &entity.Attempt{Id: int64(i + 1), QuizId: 7, StartedAt: startedAt}
+		for j, optionId := range selected {
+			if optionId == 0 {
+				continue
+			}
+
+			response := &entity.ItemResponse{
+				AttemptId:  attempt.Id,
+				QuestionId: questions[j].Id,
+				Response:   entity.Response{OptionIds: []int64{optionId}},
+				AnsweredAt: startedAt.Add(time.Duration(10*(j+1)) * time.Second),
+			}
+			if questions[j].Options[0].Id == optionId {
+				response.Score = 1
+			}
+
+			// the second question is timed and opened 5 seconds before it is answered:
+			if j == 1 {
+				response.AnsweredAt = openedAt.Add(5 * time.Second)
+				response.OpenedAt = &openedAt
+			}
+
+			attempt.Score += response.Score
+			responses = append(responses, response)
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	mockQuizzes.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7, OwnerId: 1}, nil).AnyTimes()
+	mockQuizzes.EXPECT().FindQuestionsByQuizID(int64(7)).Return(questions, nil).AnyTimes()
+
+	t.Run("GetItemAnalysis should compute difficulty and discrimination of every question", func(t *testing.T) {
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return(attempts, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return(responses, nil)
+
+		report, err := service.GetItemAnalysis(7)
+		if err != nil || report.Attempts != 5 || len(report.Items) != 3 {
+			t.Fatal(err)
+		}
+
+		expected := []struct {
+			pValue        float64
+			pointBiserial float64
+			answered      int
+			flags         []string
+		}{
+			{pValue: 0.8, pointBiserial: -0.1336306209562122, answered: 5, flags: []string{entity.ItemLowDiscrimination}},
+			{pValue: 0.6, pointBiserial: 0, answered: 4, flags: []string{entity.ItemLowDiscrimination}},
+			{pValue: 0.2, pointBiserial: 0.6123724356957944, answered: 4, flags: []string{entity.ItemTooHard, entity.ItemMisleading}},
+		}
+
+		for i, item := range report.Items {
+			if !isClose(item.PValue, expected[i].pValue) || !isClose(item.PointBiserial, expected[i].pointBiserial) ||
+				item.Answered != expected[i].answered || !reflect.DeepEqual(item.Flags, expected[i].flags) {
+				t.Errorf("question %d: %+v", item.QuestionId, item)
+			}
+		}
+
+		if math.Abs(report.MeanScore-1.6) > 1e-9 {
+			t.Error("mean score", report.MeanScore)
+		}
+	})
+
+	t.Run("GetItemAnalysis should compute the selection rate of every option", func(t *testing.T) {
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return(attempts, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return(responses, nil)
+
+		report, _ := service.GetItemAnalysis(7)
+		options := report.Items[2].Options
+
+		if len(options) != 3 || options[0].Selected != 1 || options[1].Selected != 3 || options[1].Rate != 0.6 || options[2].Rate != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetItemAnalysis should average the time spent on every question", func(t *testing.T) {
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return(attempts, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return(responses, nil)
+
+		report, _ := service.GetItemAnalysis(7)
+
+		if !isClose(report.Items[0].AverageSeconds, 10) || !isClose(report.Items[1].AverageSeconds, 5) {
+			t.Error(report.Items[0].AverageSeconds, report.Items[1].AverageSeconds)
+		}
+	})
+
+	t.Run("GetItemAnalysis should compute KR-20 for questions scored right or wrong", func(t *testing.T) {
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return(attempts, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return(responses, nil)
+
+		report, _ := service.GetItemAnalysis(7)
+
+		if !isClose(report.KR20, 0.1875) || !isClose(report.CronbachAlpha, 0.1875) {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetItemAnalysis should only compute Cronbach's alpha for partial credit", func(t *testing.T) {
+		partial := []*entity.ItemResponse{
+			{AttemptId: 1, QuestionId: 1, Score: 1}, {AttemptId: 1, QuestionId: 2, Score: 0.5},
+			{AttemptId: 2, QuestionId: 1, Score: 0.5}, {AttemptId: 2, QuestionId: 2, Score: 0},
+		}
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return([]*entity.Attempt{{Id: 1, Score: 1.5}, {Id: 2, Score: 0.5}}, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return(partial, nil)
+
+		report, _ := service.GetItemAnalysis(7)
+
+		if report.KR20 != nil || report.CronbachAlpha == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetItemAnalysis should analyse drawn questions over the attempts that drew them", func(t *testing.T) {
+		drawnAttempts := []*entity.Attempt{
+			{Id: 1, Score: 1, DrawnQuestionIds: []int64{20}},
+			{Id: 2, Score: 0, DrawnQuestionIds: []int64{20, 21}},
+			{Id: 3, Score: 0},
+		}
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return(drawnAttempts, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return([]*entity.ItemResponse{{AttemptId: 1, QuestionId: 20, Score: 1}}, nil)
+		mockQuizzes.EXPECT().FindBankQuestions(&entity.BankQuery{OwnerId: 1}).Return([]*entity.Question{{Id: 20, OwnerId: 1, Type: entity.QuestionNumeric}}, nil)
+
+		report, _ := service.GetItemAnalysis(7)
+
+		// the deleted question 21 is left out:
+		if len(report.Items) != 4 {
+			t.Fatal(report.Items)
+		}
+
+		drawn := report.Items[3]
+		if !drawn.Drawn || drawn.Attempts != 2 || !isClose(drawn.PValue, 0.5) || drawn.Options != nil {
+			t.Errorf("%+v", drawn)
+		}
+	})
+
+	t.Run("GetItemAnalysis should not compute statistics without attempts", func(t *testing.T) {
+		mockRepo.EXPECT().FindSubmittedAttempts(int64(7)).Return([]*entity.Attempt{}, nil)
+		mockRepo.EXPECT().FindItemResponses(int64(7)).Return([]*entity.ItemResponse{}, nil)
+
+		report, err := service.GetItemAnalysis(7)
+
+		if err != nil || report.Items[0].PValue != nil || report.Items[0].PointBiserial != nil || report.KR20 != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetItemAnalysis should not analyse an unknown quiz", func(t *testing.T) {
+		mockQuizzes.EXPECT().FindByID(int64(8)).Return(nil, entity.ErrEntityNotFound)
+
+		if _, err := service.GetItemAnalysis(8); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"math"
+)
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
+// variance returns the population variance, which is what KR-20 and Cronbach's alpha use.
+func variance(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	m := mean(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - m) * (value - m)
+	}
+
+	return sum / float64(len(values))
+}
+
+// correlation returns the Pearson correlation of x and y, which is the point-biserial
+// correlation when x is 0 or 1. It is nil when either does not vary.
+func correlation(x []float64, y []float64) *float64 {
+	if len(x) < 2 || len(x) != len(y) {
+		return nil
+	}
+
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+
+	if sxx == 0 || syy == 0 {
+		return nil
+	}
+
+	r := sxy / math.Sqrt(sxx*syy)
+	return &r
+}
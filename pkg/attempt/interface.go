@@ -0,0 +1,31 @@
+package attempt
+
+import (
+	"database/sql"
+	"quiz-app/pkg/entity"
+	"time"
+)
+
+type Reader interface {
+	FindByID(attempt_id int64) (*entity.Attempt, *entity.AppError)
+	FindAnswersByAttemptID(attempt_id int64) ([]*entity.AttemptAnswer, *entity.AppError)
+	FindQuestionOpenedAt(attempt_id int64, question_id int64) (time.Time, *entity.AppError)
+}
+
+type Writer interface {
+	Create(attempt *entity.Attempt) *entity.AppError
+	SaveAnswer(answer *entity.AttemptAnswer) (sql.Result, *entity.AppError)
+	Submit(attempt_id int64, submittedAt time.Time) (sql.Result, *entity.AppError)
+	OpenQuestion(attempt_id int64, question_id int64, openedAt time.Time) (time.Time, *entity.AppError)
+}
+
+// Repository interface
+type Repository interface {
+	Reader
+	Writer
+}
+
+// Notifier is told about every attempt that is submitted, with its score.
+type Notifier interface {
+	AttemptSubmitted(attempt *entity.Attempt)
+}
@@ -0,0 +1,232 @@
+package attempt
+
+import (
+	"database/sql"
+	"encoding/json"
+	"quiz-app/pkg/entity"
+	"time"
+)
+
+type PGRepository struct {
+	pool *sql.DB
+}
+
+func InitRepo(p *sql.DB) *PGRepository {
+	return &PGRepository{
+		pool: p,
+	}
+}
+
+func (r PGRepository) FindByID(attemptId int64) (*entity.Attempt, *entity.AppError) {
+	var attempt entity.Attempt
+	var deadline sql.NullTime
+	var submittedAt sql.NullTime
+	var seed sql.NullInt64
+
+	query := "select id, quiz_id, user_id, status, score, max_score, started_at, deadline, submitted_at, seed from attempts where id=$1"
+	err := r.pool.QueryRow(query, attemptId).Scan(&attempt.Id, &attempt.QuizId, &attempt.UserId, &attempt.Status,
+		&attempt.Score, &attempt.MaxScore, &attempt.StartedAt, &deadline, &submittedAt, &seed)
+
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if deadline.Valid {
+		attempt.Deadline = &deadline.Time
+	}
+
+	if submittedAt.Valid {
+		attempt.SubmittedAt = &submittedAt.Time
+	}
+
+	if attempt.QuestionIds, err = r.findQuestionIDs("attempt_questions", attemptId); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if seed.Valid {
+		attempt.Seed = &seed.Int64
+		if attempt.DrawnQuestionIds, err = r.findQuestionIDs("attempt_draws", attemptId); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+	}
+
+	return &attempt, nil
+}
+
+// findQuestionIDs returns the ids of the questions in table, attempt_questions or attempt_draws,
+// that belong to the attempt.
+func (r PGRepository) findQuestionIDs(table string, attemptId int64) ([]int64, error) {
+	rows, err := r.pool.Query("select question_id from "+table+" where attempt_id=$1 order by position", attemptId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r PGRepository) FindAnswersByAttemptID(attemptId int64) ([]*entity.AttemptAnswer, *entity.AppError) {
+	query := "select a.attempt_id, a.question_id, a.response, a.is_correct, a.score, a.answered_at " +
+		"from attempt_answers a join questions q on q.id = a.question_id where a.attempt_id=$1 order by q.position, q.id"
+
+	rows, err := r.pool.Query(query, attemptId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	answers := make([]*entity.AttemptAnswer, 0)
+	for rows.Next() {
+		var answer entity.AttemptAnswer
+		var response []byte
+		result := &entity.AnswerResult{}
+
+		if err := rows.Scan(&answer.AttemptId, &answer.QuestionId, &response, &result.IsCorrect, &result.Score, &answer.AnsweredAt); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		if err := json.Unmarshal(response, &answer.Response); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		answer.Result = result
+		answers = append(answers, &answer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return answers, nil
+}
+
+// Create stores the attempt together with the questions of the quiz and the ones it drew from
+// the question bank.
+func (r PGRepository) Create(attempt *entity.Attempt) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	query := "insert into attempts (quiz_id, user_id, status, max_score, started_at, deadline, seed) values ($1, $2, $3, $4, $5, $6, $7) returning id"
+
+	var deadline sql.NullTime
+	if attempt.Deadline != nil {
+		deadline = sql.NullTime{Time: attempt.Deadline.UTC(), Valid: true}
+	}
+
+	var seed sql.NullInt64
+	if attempt.Seed != nil {
+		seed = sql.NullInt64{Int64: *attempt.Seed, Valid: true}
+	}
+
+	err = tx.QueryRow(query, attempt.QuizId, attempt.UserId, entity.AttemptInProgress, attempt.MaxScore, attempt.StartedAt.UTC(), deadline, seed).Scan(&attempt.Id)
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+
+	for i, questionId := range attempt.QuestionIds {
+		query := "insert into attempt_questions (attempt_id, question_id, position) values ($1, $2, $3)"
+		if _, err := tx.Exec(query, attempt.Id, questionId, i+1); err != nil {
+			return entity.NewAppError(err)
+		}
+	}
+
+	for i, questionId := range attempt.DrawnQuestionIds {
+		query := "insert into attempt_draws (attempt_id, question_id, position) values ($1, $2, $3)"
+		if _, err := tx.Exec(query, attempt.Id, questionId, i+1); err != nil {
+			return entity.NewAppError(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	attempt.Status = entity.AttemptInProgress
+	return nil
+}
+
+// SaveAnswer stores or replaces the answer to a question. Nothing is stored once the attempt
+// has been submitted, which shows as zero rows affected.
+func (r PGRepository) SaveAnswer(answer *entity.AttemptAnswer) (sql.Result, *entity.AppError) {
+	response, err := json.Marshal(answer.Response)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	query := "insert into attempt_answers (attempt_id, question_id, response, is_correct, score, answered_at) " +
+		"select $1, $2, $3::jsonb, $4, $5, $6 where exists (select 1 from attempts where id=$1 and status=$7) " +
+		"on conflict (attempt_id, question_id) do update set response=excluded.response, is_correct=excluded.is_correct, " +
+		"score=excluded.score, answered_at=excluded.answered_at"
+
+	res, err := r.pool.Exec(query, answer.AttemptId, answer.QuestionId, string(response), answer.Result.IsCorrect,
+		answer.Result.Score, answer.AnsweredAt, entity.AttemptInProgress)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+// Submit closes the attempt at submittedAt with the sum of its answer scores. An attempt that
+// was already submitted is left alone, which shows as zero rows affected.
+func (r PGRepository) Submit(attemptId int64, submittedAt time.Time) (sql.Result, *entity.AppError) {
+
+	query := "update attempts set status=$1, submitted_at=$2, " +
+		"score=(select coalesce(sum(score), 0) from attempt_answers where attempt_id=$3) where id=$3 and status=$4"
+
+	res, err := r.pool.Exec(query, entity.AttemptSubmitted, submittedAt.UTC(), attemptId, entity.AttemptInProgress)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+// OpenQuestion records when the question was first opened during the attempt and returns that
+// time, so that opening it again does not restart its clock.
+func (r PGRepository) OpenQuestion(attemptId int64, questionId int64, openedAt time.Time) (time.Time, *entity.AppError) {
+	var firstOpenedAt time.Time
+
+	// the no-op update makes the statement return the existing row on conflict:
+	query := "insert into attempt_questions (attempt_id, question_id, opened_at) values ($1, $2, $3) " +
+		"on conflict (attempt_id, question_id) do update set attempt_id=excluded.attempt_id returning opened_at"
+	err := r.pool.QueryRow(query, attemptId, questionId, openedAt.UTC()).Scan(&firstOpenedAt)
+	if err != nil {
+		return time.Time{}, entity.NewAppError(err)
+	}
+
+	return firstOpenedAt, nil
+}
+
+func (r PGRepository) FindQuestionOpenedAt(attemptId int64, questionId int64) (time.Time, *entity.AppError) {
+	var openedAt time.Time
+
+	query := "select opened_at from attempt_questions where attempt_id=$1 and question_id=$2"
+	err := r.pool.QueryRow(query, attemptId, questionId).Scan(&openedAt)
+
+	if err == sql.ErrNoRows {
+		return time.Time{}, entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return time.Time{}, entity.NewAppError(err)
+	}
+
+	return openedAt, nil
+}
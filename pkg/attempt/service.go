@@ -0,0 +1,364 @@
+package attempt
+
+import (
+	"log"
+	"math/rand"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/grader"
+	"quiz-app/pkg/quiz"
+	"time"
+)
+
+type Service struct {
+	repo        Repository
+	quizzes     quiz.Reader
+	gracePeriod time.Duration
+	notifier    Notifier
+	now         func() time.Time
+	newSeed     func() int64
+}
+
+// InitService creates the attempt service. Questions and their correct answers are read
+// through the quiz reader q. Answers to timed quizzes and questions are still accepted for
+// gracePeriod after their deadline, to make up for network latency. The notifier n, which
+// may be nil, is told about every submitted attempt.
+func InitService(r Repository, q quiz.Reader, gracePeriod time.Duration, n Notifier) *Service {
+	return &Service{
+		repo:        r,
+		quizzes:     q,
+		gracePeriod: gracePeriod,
+		notifier:    n,
+		now:         time.Now,
+		newSeed:     rand.Int63,
+	}
+}
+
+// StartAttempt starts an attempt of the user at the quiz. Attempts at a timed quiz get their
+// deadline from the server clock. Attempts at a quiz with draw rules draw their questions from
+// the question bank of the quiz owner with a new seed, which is recorded with the selection.
+func (s *Service) StartAttempt(quizId int64, userId int64) (*entity.Attempt, *entity.AppError) {
+
+	q, err := s.quizzes.FindByID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.quizzes.FindQuestionsByQuizID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	attempt := &entity.Attempt{
+		QuizId:    quizId,
+		UserId:    userId,
+		MaxScore:  float64(len(questions)),
+		Answers:   make([]*entity.AttemptAnswer, 0),
+		StartedAt: now,
+	}
+
+	attempt.QuestionIds = make([]int64, len(questions))
+	for i, question := range questions {
+		attempt.QuestionIds[i] = question.Id
+	}
+
+	if err := s.drawQuestions(q, attempt); err != nil {
+		return nil, err
+	}
+
+	if q.TimeLimitSeconds > 0 {
+		deadline := now.Add(time.Duration(q.TimeLimitSeconds) * time.Second)
+		attempt.Deadline = &deadline
+	}
+
+	if err := s.repo.Create(attempt); err != nil {
+		return nil, err
+	}
+
+	attempt.Status = entity.AttemptInProgress
+	s.setRemaining(attempt, now)
+	return attempt, nil
+}
+
+// GetAttempt returns the attempt with its answers. Results are left out until the attempt
+// has been submitted. An attempt whose time is up is submitted first.
+func (s *Service) GetAttempt(attemptId int64) (*entity.Attempt, *entity.AppError) {
+
+	attempt, err := s.repo.FindByID(attemptId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if s.isExpired(attempt, now) {
+		if err := s.submitExpired(attempt); err != nil {
+			return nil, err
+		}
+
+		if attempt, err = s.repo.FindByID(attemptId); err != nil {
+			return nil, err
+		}
+	}
+
+	if attempt.Answers, err = s.repo.FindAnswersByAttemptID(attemptId); err != nil {
+		return nil, err
+	}
+
+	if attempt.Status != entity.AttemptSubmitted {
+		for _, answer := range attempt.Answers {
+			answer.Result = nil
+		}
+	}
+
+	s.setRemaining(attempt, now)
+	return attempt, nil
+}
+
+// GetOwnerID returns the id of the user taking the attempt.
+func (s *Service) GetOwnerID(attemptId int64) (int64, *entity.AppError) {
+	attempt, err := s.repo.FindByID(attemptId)
+	if err != nil {
+		return 0, err
+	}
+
+	return attempt.UserId, nil
+}
+
+// OpenQuestion returns a question of the attempt without its solution and starts its clock
+// when it has a time limit. Opening a question again keeps its original deadline.
+func (s *Service) OpenQuestion(attemptId int64, questionId int64) (*entity.AttemptQuestion, *entity.AppError) {
+
+	attempt, question, err := s.findOpenAttemptQuestion(attemptId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	openedAt, err := s.repo.OpenQuestion(attemptId, questionId, s.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	opened := &entity.AttemptQuestion{
+		AttemptId: attemptId,
+		Question:  question.WithoutAnswers(),
+		OpenedAt:  openedAt,
+	}
+
+	// a question cannot outlast the attempt:
+	deadline := attempt.Deadline
+	if question.TimeLimitSeconds > 0 {
+		questionDeadline := openedAt.Add(time.Duration(question.TimeLimitSeconds) * time.Second)
+		if deadline == nil || questionDeadline.Before(*deadline) {
+			deadline = &questionDeadline
+		}
+	}
+
+	if deadline != nil {
+		remaining := entity.RemainingSeconds(*deadline, s.now())
+		opened.Deadline = deadline
+		opened.RemainingSeconds = &remaining
+	}
+
+	return opened, nil
+}
+
+// AnswerQuestion grades and stores the response to a question of the attempt, replacing an
+// earlier answer. The result is not returned so that the client cannot probe for the correct
+// answer before submitting. Answers after the deadline of the attempt submit it instead, and
+// answers after the deadline of a timed question are rejected.
+func (s *Service) AnswerQuestion(attemptId int64, questionId int64, response *entity.Response) (*entity.AttemptAnswer, *entity.AppError) {
+
+	_, question, err := s.findOpenAttemptQuestion(attemptId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if question.TimeLimitSeconds > 0 {
+		openedAt, err := s.repo.FindQuestionOpenedAt(attemptId, questionId)
+		if err == entity.ErrEntityNotFound {
+			return nil, entity.ErrQuestionNotOpened
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		deadline := openedAt.Add(time.Duration(question.TimeLimitSeconds) * time.Second)
+		if now.After(deadline.Add(s.gracePeriod)) {
+			return nil, entity.ErrQuestionExpired
+		}
+	}
+
+	result, err := grader.Grade(question, response)
+	if err != nil {
+		return nil, err
+	}
+
+	answer := &entity.AttemptAnswer{
+		AttemptId:  attemptId,
+		QuestionId: questionId,
+		Response:   *response,
+		Result:     result,
+		AnsweredAt: now.UTC(),
+	}
+
+	res, err := s.repo.SaveAnswer(answer)
+	if err != nil {
+		return nil, err
+	}
+
+	// the attempt was submitted in the meantime:
+	if rows, rowsErr := res.RowsAffected(); rowsErr != nil || rows == 0 {
+		return nil, entity.ErrAttemptSubmitted
+	}
+
+	answer.Result = nil
+	return answer, nil
+}
+
+// SubmitAttempt closes the attempt and returns it with its results and total score.
+// Unanswered questions score nothing.
+func (s *Service) SubmitAttempt(attemptId int64) (*entity.Attempt, *entity.AppError) {
+
+	attempt, err := s.repo.FindByID(attemptId)
+	if err != nil {
+		return nil, err
+	}
+
+	// answers saved within the grace period count, but the attempt ends at its deadline:
+	submittedAt := s.now().UTC()
+	if attempt.Deadline != nil && submittedAt.After(*attempt.Deadline) {
+		submittedAt = *attempt.Deadline
+	}
+
+	res, err := s.repo.Submit(attemptId, submittedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if rows, rowsErr := res.RowsAffected(); rowsErr != nil || rows == 0 {
+		return nil, entity.ErrAttemptSubmitted
+	}
+
+	if attempt, err = s.GetAttempt(attemptId); err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifier.AttemptSubmitted(attempt)
+	}
+
+	return attempt, nil
+}
+
+// findOpenAttemptQuestion returns the attempt, which has to be in progress and in time, and
+// its question with the solution. Only the questions the attempt started with belong to it.
+func (s *Service) findOpenAttemptQuestion(attemptId int64, questionId int64) (*entity.Attempt, *entity.Question, *entity.AppError) {
+	attempt, err := s.repo.FindByID(attemptId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attempt.Status != entity.AttemptInProgress {
+		return nil, nil, entity.ErrAttemptSubmitted
+	}
+
+	if s.isExpired(attempt, s.now()) {
+		if err := s.submitExpired(attempt); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, entity.ErrAttemptExpired
+	}
+
+	question, err := s.quizzes.FindQuestionByID(questionId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !hasQuestion(attempt, questionId) {
+		return nil, nil, entity.ErrEntityNotFound
+	}
+
+	return attempt, question, nil
+}
+
+// drawQuestions adds the questions that the draw rules of the quiz pick from the question bank
+// of its owner to the attempt.
+func (s *Service) drawQuestions(q *entity.Quiz, attempt *entity.Attempt) *entity.AppError {
+	rules, err := s.quizzes.FindDrawRules(q.Id)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+
+	bank, err := s.quizzes.FindBankQuestions(&entity.BankQuery{OwnerId: q.OwnerId})
+	if err != nil {
+		return err
+	}
+
+	seed := s.newSeed()
+	drawn, err := quiz.Draw(rules, bank, seed)
+	if err != nil {
+		return err
+	}
+
+	attempt.Seed = &seed
+	attempt.DrawnQuestionIds = make([]int64, len(drawn))
+	for i, question := range drawn {
+		attempt.DrawnQuestionIds[i] = question.Id
+	}
+	attempt.MaxScore += float64(len(drawn))
+
+	return nil
+}
+
+// hasQuestion reports whether the quiz had the question when the attempt started, or the
+// attempt drew it from the question bank.
+func hasQuestion(attempt *entity.Attempt, questionId int64) bool {
+	for _, ids := range [][]int64{attempt.QuestionIds, attempt.DrawnQuestionIds} {
+		for _, id := range ids {
+			if id == questionId {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isExpired reports whether the attempt is in progress past its deadline and grace period.
+func (s *Service) isExpired(attempt *entity.Attempt, now time.Time) bool {
+	return attempt.Status == entity.AttemptInProgress && attempt.Deadline != nil &&
+		now.After(attempt.Deadline.Add(s.gracePeriod))
+}
+
+// submitExpired submits an attempt whose time is up as of its deadline.
+func (s *Service) submitExpired(attempt *entity.Attempt) *entity.AppError {
+	res, err := s.repo.Submit(attempt.Id, *attempt.Deadline)
+	if err != nil {
+		return err
+	}
+
+	// zero rows affected means that a concurrent request submitted it first:
+	if rows, rowsErr := res.RowsAffected(); rowsErr != nil || rows == 0 || s.notifier == nil {
+		return nil
+	}
+
+	// the notifier needs the score, which the submission has just added up:
+	submitted, err := s.repo.FindByID(attempt.Id)
+	if err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	s.notifier.AttemptSubmitted(submitted)
+	return nil
+}
+
+func (s *Service) setRemaining(attempt *entity.Attempt, now time.Time) {
+	if attempt.Status == entity.AttemptInProgress && attempt.Deadline != nil {
+		remaining := entity.RemainingSeconds(*attempt.Deadline, now)
+		attempt.RemainingSeconds = &remaining
+	}
+}
@@ -0,0 +1,388 @@
+package attempt
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"go.uber.org/mock/gomock"
+	"quiz-app/pkg/entity"
+	mockAttempt "quiz-app/pkg/mocks/attempt"
+	mockQuiz "quiz-app/pkg/mocks/quiz"
+	"quiz-app/pkg/quiz"
+	"testing"
+	"time"
+)
+
+func newTestQuestion() *entity.Question {
+	return &entity.Question{
+		Id:     3,
+		QuizId: 7,
+		Type:   entity.QuestionMultipleChoice,
+		Text:   "Which are Go keywords?",
+		Options: []*entity.AnswerOption{
+			{Id: 10, Text: "func", IsCorrect: true},
+			{Id: 11, Text: "defer", IsCorrect: true},
+			{Id: 12, Text: "function"},
+		},
+	}
+}
+
+func TestAnswerQuestion(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAttempt.NewMockRepository(mockCtrl)
+	mockQuizzes := mockQuiz.NewMockReader(mockCtrl)
+	service := InitService(mockRepo, mockQuizzes, time.Second, nil)
+
+	inProgress := &entity.Attempt{Id: 1, QuizId: 7, UserId: 1, Status: entity.AttemptInProgress, QuestionIds: []int64{3}}
+
+	t.Run("AnswerQuestion should reject an answer to a submitted attempt", func(t *testing.T) {
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, QuizId: 7, Status: entity.AttemptSubmitted}, nil)
+
+		if _, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrAttemptSubmitted {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should reject a question of another quiz", func(t *testing.T) {
+		question := newTestQuestion()
+		question.Id = 4
+		question.QuizId = 8
+		mockRepo.EXPECT().FindByID(int64(1)).Return(inProgress, nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(4)).Return(question, nil)
+
+		if _, err := service.AnswerQuestion(1, 4, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should reject a question added to the quiz after the attempt started", func(t *testing.T) {
+		question := newTestQuestion()
+		question.Id = 5
+		mockRepo.EXPECT().FindByID(int64(1)).Return(inProgress, nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(5)).Return(question, nil)
+
+		if _, err := service.AnswerQuestion(1, 5, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should store the graded answer without returning the result", func(t *testing.T) {
+		mockRepo.EXPECT().FindByID(int64(1)).Return(inProgress, nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(3)).Return(newTestQuestion(), nil)
+		mockRepo.EXPECT().SaveAnswer(gomock.Any()).DoAndReturn(func(answer *entity.AttemptAnswer) (sql.Result, *entity.AppError) {
+			if answer.Result == nil || !answer.Result.IsCorrect || answer.Result.Score != 1 {
+				t.Fail()
+			}
+			return driver.RowsAffected(1), nil
+		})
+
+		answer, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10, 11}})
+
+		if err != nil || answer.Result != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should reject an answer when the attempt was submitted concurrently", func(t *testing.T) {
+		mockRepo.EXPECT().FindByID(int64(1)).Return(inProgress, nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(3)).Return(newTestQuestion(), nil)
+		mockRepo.EXPECT().SaveAnswer(gomock.Any()).Return(driver.RowsAffected(0), nil)
+
+		if _, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrAttemptSubmitted {
+			t.Fail()
+		}
+	})
+}
+
+func TestSubmitAttempt(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAttempt.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, mockQuiz.NewMockReader(mockCtrl), time.Second, nil)
+
+	t.Run("SubmitAttempt should reject an attempt that was already submitted", func(t *testing.T) {
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptSubmitted}, nil)
+		mockRepo.EXPECT().Submit(int64(1), gomock.Any()).Return(driver.RowsAffected(0), nil)
+
+		if _, err := service.SubmitAttempt(1); err != entity.ErrAttemptSubmitted {
+			t.Fail()
+		}
+	})
+
+	t.Run("SubmitAttempt should return the results", func(t *testing.T) {
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptInProgress}, nil)
+		mockRepo.EXPECT().Submit(int64(1), gomock.Any()).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptSubmitted, Score: 1, MaxScore: 2}, nil)
+		mockRepo.EXPECT().FindAnswersByAttemptID(int64(1)).Return([]*entity.AttemptAnswer{
+			{AttemptId: 1, QuestionId: 3, Result: &entity.AnswerResult{IsCorrect: true, Score: 1}},
+		}, nil)
+
+		a, err := service.SubmitAttempt(1)
+
+		if err != nil || a.Score != 1 || a.Answers[0].Result == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("SubmitAttempt should tell the notifier about the submitted attempt", func(t *testing.T) {
+		mockNotifier := mockAttempt.NewMockNotifier(mockCtrl)
+		service := InitService(mockRepo, mockQuiz.NewMockReader(mockCtrl), time.Second, mockNotifier)
+
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptInProgress}, nil)
+		mockRepo.EXPECT().Submit(int64(1), gomock.Any()).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptSubmitted, Score: 1, MaxScore: 2}, nil)
+		mockRepo.EXPECT().FindAnswersByAttemptID(int64(1)).Return([]*entity.AttemptAnswer{}, nil)
+		mockNotifier.EXPECT().AttemptSubmitted(gomock.Any()).Do(func(a *entity.Attempt) {
+			if a.Id != 1 || a.Score != 1 {
+				t.Fail()
+			}
+		})
+
+		if _, err := service.SubmitAttempt(1); err != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestGetAttempt(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAttempt.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, mockQuiz.NewMockReader(mockCtrl), time.Second, nil)
+
+	t.Run("GetAttempt should hide results while the attempt is in progress", func(t *testing.T) {
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptInProgress}, nil)
+		mockRepo.EXPECT().FindAnswersByAttemptID(int64(1)).Return([]*entity.AttemptAnswer{
+			{AttemptId: 1, QuestionId: 3, Result: &entity.AnswerResult{IsCorrect: true, Score: 1}},
+		}, nil)
+
+		a, err := service.GetAttempt(1)
+
+		if err != nil || a.Answers[0].Result != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestTimedAttempt(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAttempt.NewMockRepository(mockCtrl)
+	mockQuizzes := mockQuiz.NewMockReader(mockCtrl)
+	service := InitService(mockRepo, mockQuizzes, 5*time.Second, nil)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	deadline := now.Add(time.Minute)
+	timedAttempt := func() *entity.Attempt {
+		return &entity.Attempt{Id: 1, QuizId: 7, UserId: 1, Status: entity.AttemptInProgress, StartedAt: now, Deadline: &deadline, QuestionIds: []int64{3}}
+	}
+
+	timedQuestion := func() *entity.Question {
+		question := newTestQuestion()
+		question.TimeLimitSeconds = 10
+		return question
+	}
+
+	t.Run("StartAttempt should set the deadline from the time limit of the quiz", func(t *testing.T) {
+		mockQuizzes.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7, TimeLimitSeconds: 60}, nil)
+		mockQuizzes.EXPECT().FindQuestionsByQuizID(int64(7)).Return([]*entity.Question{newTestQuestion()}, nil)
+		mockQuizzes.EXPECT().FindDrawRules(int64(7)).Return([]*entity.DrawRule{}, nil)
+		mockRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		a, err := service.StartAttempt(7, 1)
+
+		if err != nil || a.Deadline == nil || !a.Deadline.Equal(deadline) {
+			t.FailNow()
+		}
+
+		if len(a.QuestionIds) != 1 || a.QuestionIds[0] != 3 {
+			t.Fail()
+		}
+
+		if a.RemainingSeconds == nil || *a.RemainingSeconds != 60 {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetAttempt should report the remaining time", func(t *testing.T) {
+		now = deadline.Add(-1500 * time.Millisecond)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockRepo.EXPECT().FindAnswersByAttemptID(int64(1)).Return([]*entity.AttemptAnswer{}, nil)
+
+		a, err := service.GetAttempt(1)
+
+		if err != nil || a.RemainingSeconds == nil || *a.RemainingSeconds != 2 {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should accept an answer within the grace period", func(t *testing.T) {
+		now = deadline.Add(4 * time.Second)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(3)).Return(newTestQuestion(), nil)
+		mockRepo.EXPECT().SaveAnswer(gomock.Any()).Return(driver.RowsAffected(1), nil)
+
+		if _, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10}}); err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should submit the attempt when the answer is too late", func(t *testing.T) {
+		now = deadline.Add(6 * time.Second)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockRepo.EXPECT().Submit(int64(1), deadline).Return(driver.RowsAffected(1), nil)
+
+		if _, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrAttemptExpired {
+			t.Fail()
+		}
+	})
+
+	t.Run("SubmitAttempt should end a late attempt at its deadline", func(t *testing.T) {
+		now = deadline.Add(3 * time.Second)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockRepo.EXPECT().Submit(int64(1), deadline).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, Status: entity.AttemptSubmitted, Deadline: &deadline, SubmittedAt: &deadline}, nil)
+		mockRepo.EXPECT().FindAnswersByAttemptID(int64(1)).Return([]*entity.AttemptAnswer{}, nil)
+
+		a, err := service.SubmitAttempt(1)
+
+		if err != nil || a.RemainingSeconds != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should reject a timed question that was not opened", func(t *testing.T) {
+		now = deadline.Add(-30 * time.Second)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(3)).Return(timedQuestion(), nil)
+		mockRepo.EXPECT().FindQuestionOpenedAt(int64(1), int64(3)).Return(time.Time{}, entity.ErrEntityNotFound)
+
+		if _, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrQuestionNotOpened {
+			t.Fail()
+		}
+	})
+
+	t.Run("OpenQuestion should cap the question deadline at the attempt deadline", func(t *testing.T) {
+		now = deadline.Add(-4 * time.Second)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(3)).Return(timedQuestion(), nil)
+		mockRepo.EXPECT().OpenQuestion(int64(1), int64(3), now).Return(now, nil)
+
+		opened, err := service.OpenQuestion(1, 3)
+
+		if err != nil || !opened.Deadline.Equal(deadline) || *opened.RemainingSeconds != 4 {
+			t.FailNow()
+		}
+
+		for _, option := range opened.Question.Options {
+			if option.IsCorrect {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("AnswerQuestion should reject an answer after the question deadline", func(t *testing.T) {
+		now = deadline.Add(-30 * time.Second)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(timedAttempt(), nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(3)).Return(timedQuestion(), nil)
+		mockRepo.EXPECT().FindQuestionOpenedAt(int64(1), int64(3)).Return(now.Add(-16*time.Second), nil)
+
+		if _, err := service.AnswerQuestion(1, 3, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrQuestionExpired {
+			t.Fail()
+		}
+	})
+}
+
+func TestDrawnAttempt(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAttempt.NewMockRepository(mockCtrl)
+	mockQuizzes := mockQuiz.NewMockReader(mockCtrl)
+	service := InitService(mockRepo, mockQuizzes, time.Second, nil)
+	service.newSeed = func() int64 { return 42 }
+
+	bank := func() []*entity.Question {
+		questions := make([]*entity.Question, 0)
+		for id := int64(20); id < 30; id++ {
+			question := newTestQuestion()
+			question.Id = id
+			question.QuizId = 0
+			question.OwnerId = 2
+			question.Tags = []string{"geography"}
+			question.Difficulty = entity.DifficultyEasy
+			questions = append(questions, question)
+		}
+
+		return questions
+	}
+
+	t.Run("StartAttempt should record the seed and the questions drawn from the bank of the owner", func(t *testing.T) {
+		rules := []*entity.DrawRule{{Tag: "geography", Count: 3}}
+		mockQuizzes.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7, OwnerId: 2}, nil)
+		mockQuizzes.EXPECT().FindQuestionsByQuizID(int64(7)).Return([]*entity.Question{newTestQuestion()}, nil)
+		mockQuizzes.EXPECT().FindDrawRules(int64(7)).Return(rules, nil)
+		mockQuizzes.EXPECT().FindBankQuestions(&entity.BankQuery{OwnerId: 2}).Return(bank(), nil)
+		mockRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		a, err := service.StartAttempt(7, 1)
+
+		if err != nil || a.Seed == nil || *a.Seed != 42 || a.MaxScore != 4 {
+			t.FailNow()
+		}
+
+		expected, _ := quiz.Draw(rules, bank(), 42)
+		if len(a.DrawnQuestionIds) != 3 {
+			t.FailNow()
+		}
+
+		for i, question := range expected {
+			if a.DrawnQuestionIds[i] != question.Id {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("StartAttempt should fail when the bank has too few questions", func(t *testing.T) {
+		mockQuizzes.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7, OwnerId: 2}, nil)
+		mockQuizzes.EXPECT().FindQuestionsByQuizID(int64(7)).Return([]*entity.Question{}, nil)
+		mockQuizzes.EXPECT().FindDrawRules(int64(7)).Return([]*entity.DrawRule{{Difficulty: entity.DifficultyHard, Count: 1}}, nil)
+		mockQuizzes.EXPECT().FindBankQuestions(&entity.BankQuery{OwnerId: 2}).Return(bank(), nil)
+
+		if a, err := service.StartAttempt(7, 1); a != nil || err != entity.ErrNotEnoughQuestions {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should accept an answer to a drawn question", func(t *testing.T) {
+		seed := int64(42)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, QuizId: 7, Status: entity.AttemptInProgress, Seed: &seed, DrawnQuestionIds: []int64{25}}, nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(25)).Return(bank()[5], nil)
+		mockRepo.EXPECT().SaveAnswer(gomock.Any()).Return(driver.RowsAffected(1), nil)
+
+		if _, err := service.AnswerQuestion(1, 25, &entity.Response{OptionIds: []int64{10}}); err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("AnswerQuestion should reject a bank question the attempt did not draw", func(t *testing.T) {
+		seed := int64(42)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.Attempt{Id: 1, QuizId: 7, Status: entity.AttemptInProgress, Seed: &seed, DrawnQuestionIds: []int64{25}}, nil)
+		mockQuizzes.EXPECT().FindQuestionByID(int64(26)).Return(bank()[6], nil)
+
+		if _, err := service.AnswerQuestion(1, 26, &entity.Response{OptionIds: []int64{10}}); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/lib/pq"
+	"log"
+	"net"
+	"net/url"
+	"quiz-app/config"
+	"quiz-app/pkg/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	driver = "postgres"
+
+	initialBackoff = 100 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+// DSN builds the connection url of the database. User, password and database name are escaped,
+// so that they reach Postgres as they are, and a host that is a directory is the unix socket
+// of the server.
+func DSN(cfg config.DatabaseConfig) string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:   "/" + cfg.Name,
+	}
+
+	if cfg.Password != "" {
+		dsn.User = url.UserPassword(cfg.User, cfg.Password)
+	} else {
+		dsn.User = url.User(cfg.User)
+	}
+
+	params := url.Values{}
+	if strings.HasPrefix(cfg.Host, "/") {
+		dsn.Host = ""
+		params.Set("host", cfg.Host)
+		params.Set("port", strconv.Itoa(cfg.Port))
+	}
+
+	params.Set("sslmode", cfg.SSLMode)
+	if cfg.SSLRootCert != "" {
+		params.Set("sslrootcert", cfg.SSLRootCert)
+	}
+
+	if cfg.ApplicationName != "" {
+		params.Set("application_name", cfg.ApplicationName)
+	}
+
+	// unknown parameters are set on every connection, like with SET:
+	if cfg.SearchPath != "" {
+		params.Set("search_path", cfg.SearchPath)
+	}
+
+	dsn.RawQuery = params.Encode()
+	return dsn.String()
+}
+
+// Open opens the pool of the database with the limits of cfg. It does not connect yet, see
+// Connect.
+func Open(cfg config.DatabaseConfig) (*sql.DB, *entity.AppError) {
+	pool, err := sql.Open(driver, DSN(cfg))
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return pool, nil
+}
+
+// Connect opens the pool of the database and waits for the database to answer, for up to
+// cfg.ConnectTimeout, so that the server fails at startup rather than on its first request
+// and can start alongside the database.
+func Connect(cfg config.DatabaseConfig) (*sql.DB, *entity.AppError) {
+	pool, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitFor(pool.PingContext, cfg.ConnectTimeout); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
+
+// waitFor calls ping until it succeeds or the timeout is up. After a failure it waits
+// initialBackoff, and twice as long after every failure after that, up to maxBackoff.
+func waitFor(ping func(ctx context.Context) error, timeout time.Duration) *entity.AppError {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var lastErr error
+	backoff := initialBackoff
+	for {
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		// a ping cut off by the timeout tells less than the one before it:
+		if ctx.Err() == nil || lastErr == nil {
+			lastErr = err
+		}
+
+		if ctx.Err() == nil {
+			log.Printf("Database is not reachable, retrying in %s: %s", backoff, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(backoff):
+			}
+		}
+
+		if ctx.Err() != nil {
+			return entity.NewAppError(fmt.Errorf("database is not reachable after %s: %w", timeout, lastErr))
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
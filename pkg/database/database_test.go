@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"quiz-app/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestConfig() config.DatabaseConfig {
+	return config.DatabaseConfig{
+		Host:            "db.example.com",
+		Port:            5433,
+		Name:            "quiz app",
+		User:            "quiz@app",
+		Password:        "p@ss:w/rd?#%é",
+		SSLMode:         "verify-full",
+		SSLRootCert:     "/etc/ssl/root ca.pem",
+		ApplicationName: "quiz-app",
+		SearchPath:      "quiz,public",
+		MaxOpenConns:    7,
+		MaxIdleConns:    3,
+	}
+}
+
+func TestDSN(t *testing.T) {
+
+	t.Run("DSN should pass the password and names on as they are", func(t *testing.T) {
+		params, err := pq.ParseURL(DSN(newTestConfig()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, param := range []string{`dbname='quiz app'`, `host='db.example.com'`, `port='5433'`, `user='quiz@app'`, `password='p@ss:w/rd?#%é'`,
+			`sslmode='verify-full'`, `sslrootcert='/etc/ssl/root ca.pem'`, `application_name='quiz-app'`, `search_path='quiz,public'`} {
+			if !strings.Contains(params, param) {
+				t.Error(param, "missing from", params)
+			}
+		}
+	})
+
+	t.Run("DSN should connect to a unix socket when the host is a directory", func(t *testing.T) {
+		cfg := newTestConfig()
+		cfg.Host = "/var/run/postgresql"
+		cfg.Password = ""
+
+		dsn := DSN(cfg)
+
+		if !strings.HasPrefix(dsn, "postgres://quiz%40app@/quiz%20app?") || !strings.Contains(dsn, "host=%2Fvar%2Frun%2Fpostgresql") {
+			t.Fatal(dsn)
+		}
+	})
+}
+
+func TestOpen(t *testing.T) {
+
+	t.Run("Open should limit the pool without connecting", func(t *testing.T) {
+		pool, err := Open(newTestConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		if pool.Stats().MaxOpenConnections != 7 || pool.Stats().OpenConnections != 0 {
+			t.Fail()
+		}
+	})
+}
+
+func TestWaitFor(t *testing.T) {
+
+	t.Run("waitFor should retry until the database answers", func(t *testing.T) {
+		pings := 0
+		ping := func(ctx context.Context) error {
+			pings++
+			if pings < 3 {
+				return errors.New("connection refused")
+			}
+			return nil
+		}
+
+		if err := waitFor(ping, time.Second); err != nil || pings != 3 {
+			t.Fatal(err, pings)
+		}
+	})
+
+	t.Run("waitFor should give up after the timeout with the reason", func(t *testing.T) {
+		ping := func(ctx context.Context) error {
+			return errors.New("connection refused")
+		}
+
+		start := time.Now()
+		err := waitFor(ping, 50*time.Millisecond)
+
+		if err == nil || !strings.Contains(err.Error(), "connection refused") || time.Since(start) > time.Second {
+			t.Fatal(err)
+		}
+	})
+}
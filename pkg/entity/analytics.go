@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"time"
+)
+
+// Item flags point authors to questions worth a second look.
+const (
+	ItemTooEasy           = "too_easy"
+	ItemTooHard           = "too_hard"
+	ItemLowDiscrimination = "low_discrimination"
+	ItemMisleading        = "misleading_distractor"
+)
+
+// ItemResponse is the answer of a submitted attempt to a question as used by the item
+// analysis. OpenedAt is only set for questions that had to be opened before they were answered.
+type ItemResponse struct {
+	AttemptId  int64
+	QuestionId int64
+	Response   Response
+	Score      float64
+	AnsweredAt time.Time
+	OpenedAt   *time.Time
+}
+
+// ItemAnalysis is the classical test theory report of a quiz, computed from its submitted
+// attempts. KR20 is only set when every question is scored right or wrong, CronbachAlpha also
+// for partial credit. Both only cover the questions of the quiz itself, since questions drawn
+// from the question bank differ between attempts.
+type ItemAnalysis struct {
+	QuizId        int64             `json:"quizId"`
+	Attempts      int               `json:"attempts"`
+	MeanScore     float64           `json:"meanScore"`
+	KR20          *float64          `json:"kr20,omitempty"`
+	CronbachAlpha *float64          `json:"cronbachAlpha,omitempty"`
+	Items         []*ItemStatistics `json:"items"`
+}
+
+// ItemStatistics describes how a question performed in the attempts it was part of. PValue is
+// its difficulty, the mean score from 0 to 1, and PointBiserial its discrimination, the
+// correlation of its score with the score on the other questions. AverageSeconds is the mean
+// time from opening, or from the previous answer, to the answer.
+type ItemStatistics struct {
+	QuestionId     int64               `json:"questionId"`
+	Text           string              `json:"text"`
+	Type           string              `json:"type"`
+	Drawn          bool                `json:"drawn,omitempty"`
+	Attempts       int                 `json:"attempts"`
+	Answered       int                 `json:"answered"`
+	PValue         *float64            `json:"pValue,omitempty"`
+	PointBiserial  *float64            `json:"pointBiserial,omitempty"`
+	AverageSeconds *float64            `json:"averageSeconds,omitempty"`
+	Options        []*OptionStatistics `json:"options,omitempty"`
+	Flags          []string            `json:"flags"`
+}
+
+// OptionStatistics is how often an option of a choice question was selected, as a share of the
+// attempts the question was part of.
+type OptionStatistics struct {
+	OptionId  int64   `json:"optionId"`
+	Text      string  `json:"text"`
+	IsCorrect bool    `json:"isCorrect"`
+	Selected  int     `json:"selected"`
+	Rate      float64 `json:"rate"`
+}
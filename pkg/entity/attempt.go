@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"time"
+)
+
+// Attempt states.
+const (
+	AttemptInProgress = "in_progress"
+	AttemptSubmitted  = "submitted"
+)
+
+// Attempt is one run of a user through a quiz. Score is set once the attempt is submitted,
+// MaxScore is the score of answering every question correctly. QuestionIds are the questions of
+// the quiz when the attempt started, the only ones it accepts answers to. Attempts at timed quizzes have
+// a Deadline set by the server when they start, and RemainingSeconds until it while in progress.
+// Attempts at quizzes with draw rules record the Seed of their random selection and the
+// DrawnQuestionIds it picked from the question bank, in addition to the questions of the quiz.
+type Attempt struct {
+	Id               int64            `json:"id"`
+	QuizId           int64            `json:"quizId"`
+	UserId           int64            `json:"userId"`
+	Status           string           `json:"status"`
+	Score            float64          `json:"score"`
+	MaxScore         float64          `json:"maxScore"`
+	Answers          []*AttemptAnswer `json:"answers"`
+	StartedAt        time.Time        `json:"startedAt"`
+	Deadline         *time.Time       `json:"deadline,omitempty"`
+	RemainingSeconds *int             `json:"remainingSeconds,omitempty"`
+	SubmittedAt      *time.Time       `json:"submittedAt,omitempty"`
+	QuestionIds      []int64          `json:"questionIds,omitempty"`
+	Seed             *int64           `json:"seed,omitempty"`
+	DrawnQuestionIds []int64          `json:"drawnQuestionIds,omitempty"`
+}
+
+// AttemptQuestion is a question opened during an attempt. Questions with a time limit have to
+// be opened before they can be answered, which starts their Deadline.
+type AttemptQuestion struct {
+	AttemptId        int64      `json:"attemptId"`
+	Question         *Question  `json:"question"`
+	OpenedAt         time.Time  `json:"openedAt"`
+	Deadline         *time.Time `json:"deadline,omitempty"`
+	RemainingSeconds *int       `json:"remainingSeconds,omitempty"`
+}
+
+// RemainingSeconds returns the whole seconds left until deadline, rounded up and never negative.
+func RemainingSeconds(deadline time.Time, now time.Time) int {
+	remaining := deadline.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+
+	return int((remaining + time.Second - 1) / time.Second)
+}
+
+// Response is what a user answered to a question: the selected options of a choice question,
+// all options in order for an ordering question, a match per option id for a matching question,
+// a number for a numeric question or a text for a short text question.
+type Response struct {
+	OptionIds []int64          `json:"optionIds,omitempty"`
+	Matches   map[int64]string `json:"matches,omitempty"`
+	Number    *float64         `json:"number,omitempty"`
+	Text      string           `json:"text,omitempty"`
+}
+
+// AttemptAnswer is the response to one question of an attempt. Result is graded when the
+// answer is saved but only shown once the attempt is submitted.
+type AttemptAnswer struct {
+	AttemptId  int64         `json:"attemptId"`
+	QuestionId int64         `json:"questionId"`
+	Response   Response      `json:"response"`
+	Result     *AnswerResult `json:"result,omitempty"`
+	AnsweredAt time.Time     `json:"answeredAt"`
+}
+
+// AnswerResult is the grade of an answer. Score is between 0 and 1, and below 1 for partially
+// correct answers.
+type AnswerResult struct {
+	IsCorrect bool    `json:"isCorrect"`
+	Score     float64 `json:"score"`
+}
@@ -3,6 +3,8 @@ package entity
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // AppError is a global error type
@@ -32,6 +34,12 @@ func (e *AppError) Error() string {
 	return e.Msg
 }
 
+// Unwrap returns the cause, so that errors.Is and errors.As look through details added with
+// errors such as NewInvalidQuestionError.
+func (e *AppError) Unwrap() error {
+	return e.AppError
+}
+
 func (e *AppError) Is(target error) bool {
 	return e.Error() == target.Error()
 }
@@ -44,6 +52,59 @@ func (e *AppError) As(target interface{}) bool {
 	return false
 }
 
+// RetryAfterError is the cause of ErrTooManyAttempts and tells the caller when to try again.
+type RetryAfterError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return ErrTooManyAttempts.Msg
+}
+
+// NewRetryAfterError returns an AppError that matches ErrTooManyAttempts and carries the
+// time to wait as a *RetryAfterError.
+func NewRetryAfterError(retryAfter time.Duration) *AppError {
+	return &AppError{
+		AppError: &RetryAfterError{RetryAfter: retryAfter},
+		Msg:      ErrTooManyAttempts.Msg,
+	}
+}
+
+// ImportIssue is a problem with an imported quiz, at Line of the file or with the whole file
+// when Line is 0.
+type ImportIssue struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportError is the cause of ErrInvalidImport and lists every problem found in the file.
+type ImportError struct {
+	Issues []ImportIssue
+}
+
+func (e *ImportError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrInvalidImport.Msg)
+	for _, issue := range e.Issues {
+		if issue.Line > 0 {
+			fmt.Fprintf(&b, "\nline %d: %s", issue.Line, issue.Message)
+		} else {
+			fmt.Fprintf(&b, "\n%s", issue.Message)
+		}
+	}
+
+	return b.String()
+}
+
+// NewImportError returns an AppError that matches ErrInvalidImport and carries the issues as
+// an *ImportError.
+func NewImportError(issues []ImportIssue) *AppError {
+	return &AppError{
+		AppError: &ImportError{Issues: issues},
+		Msg:      ErrInvalidImport.Msg,
+	}
+}
+
 // TODO:
 // - create method to display all errors including wrapped ones
 // - write tests
@@ -56,3 +117,87 @@ var ErrJwtCreation = NewAppError(errors.New("unable to create jwt string"))
 var ErrAppToken = NewAppError(errors.New("unable to authenticate token"))
 
 var ErrEntityNotFound = NewAppError(errors.New("entity could not be found"))
+
+var ErrUserAlreadyExists = NewAppError(errors.New("user already exists"))
+
+var ErrInvalidUsername = NewAppError(errors.New("username must be 3-32 characters of letters, digits, '.', '-' or '_'"))
+
+var ErrInvalidPassword = NewAppError(errors.New("password must be between 8 and 72 characters"))
+
+var ErrInvalidRefreshToken = NewAppError(errors.New("refresh token is invalid or has expired"))
+
+var ErrRefreshTokenReuse = NewAppError(errors.New("refresh token has already been used"))
+
+var ErrTokenRevoked = NewAppError(errors.New("jwt token has been revoked"))
+
+var ErrForbidden = NewAppError(errors.New("user is not allowed to access this resource"))
+
+var ErrIncorrectPassword = NewAppError(errors.New("password is incorrect"))
+
+var ErrInvalidCursor = NewAppError(errors.New("cursor is invalid"))
+
+var ErrInvalidSort = NewAppError(errors.New("sort must be one of createdAt, lastLoginAt or username"))
+
+var ErrInvalidEmail = NewAppError(errors.New("email address is invalid"))
+
+var ErrEmailAlreadyExists = NewAppError(errors.New("email address is already in use"))
+
+var ErrInvalidResetToken = NewAppError(errors.New("password reset token is invalid or has expired"))
+
+var ErrTooManyAttempts = NewAppError(errors.New("too many failed login attempts, try again later"))
+
+var ErrInvalidQuiz = NewAppError(errors.New("quiz must have a title of at most 200 characters and a time limit of at most a day"))
+
+var ErrInvalidQuestion = NewAppError(errors.New("question is invalid"))
+
+// NewInvalidQuestionError returns an AppError that unwraps to ErrInvalidQuestion and says why.
+func NewInvalidQuestionError(reason string) *AppError {
+	return &AppError{
+		AppError: ErrInvalidQuestion,
+		Msg:      fmt.Sprintf("%s: %s", ErrInvalidQuestion.Msg, reason),
+	}
+}
+
+var ErrAttemptSubmitted = NewAppError(errors.New("attempt has already been submitted"))
+
+var ErrInvalidAnswer = NewAppError(errors.New("answer does not fit the type or options of the question"))
+
+var ErrAttemptExpired = NewAppError(errors.New("time for this attempt is up"))
+
+var ErrQuestionExpired = NewAppError(errors.New("time for this question is up"))
+
+var ErrQuestionNotOpened = NewAppError(errors.New("timed questions have to be opened before they are answered"))
+
+var ErrInvalidWindow = NewAppError(errors.New("window must be one of daily, weekly or all_time"))
+
+var ErrInvalidDrawRule = NewAppError(errors.New("draw rules need a count between 1 and 100 and a known difficulty"))
+
+var ErrNotEnoughQuestions = NewAppError(errors.New("the question bank does not have enough questions for the draw rules of the quiz"))
+
+var ErrInvalidImport = NewAppError(errors.New("quiz could not be imported"))
+
+var ErrUnknownFormat = NewAppError(errors.New("format must be one of json, yaml, csv or gift"))
+
+var ErrEmptyLiveQuiz = NewAppError(errors.New("live sessions need a quiz with at least one question"))
+
+var ErrNotLiveHost = NewAppError(errors.New("only the host can run the live session"))
+
+var ErrLiveCommand = NewAppError(errors.New("command is not allowed in the current state of the live session"))
+
+var ErrAlreadyAnswered = NewAppError(errors.New("question has already been answered"))
+
+var ErrInvalidMigration = NewAppError(errors.New("migration is invalid"))
+
+// NewInvalidMigrationError returns an AppError that unwraps to ErrInvalidMigration and says why.
+func NewInvalidMigrationError(reason string) *AppError {
+	return &AppError{
+		AppError: ErrInvalidMigration,
+		Msg:      fmt.Sprintf("%s: %s", ErrInvalidMigration.Msg, reason),
+	}
+}
+
+var ErrUnknownMigration = NewAppError(errors.New("database has a migration this version of the server does not know"))
+
+var ErrShuttingDown = NewAppError(errors.New("server is shutting down"))
+
+var ErrHealthCheckFailed = NewAppError(errors.New("check failed"))
@@ -0,0 +1,32 @@
+package entity
+
+// Statuses of health checks.
+const (
+	HealthOK      = "ok"
+	HealthFailing = "failing"
+)
+
+// HealthCheck is the result of checking one dependency of the server, with the error when it
+// failed and how long the check took.
+type HealthCheck struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Readiness tells whether the server can take requests, which it can when every check is ok.
+type Readiness struct {
+	Status string         `json:"status"`
+	Checks []*HealthCheck `json:"checks"`
+}
+
+// BuildInfo identifies the build of the server. Modified is set when it was built from a tree
+// with uncommitted changes.
+type BuildInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit,omitempty"`
+	CommitTime string `json:"commitTime,omitempty"`
+	Modified   bool   `json:"modified,omitempty"`
+	GoVersion  string `json:"goVersion"`
+}
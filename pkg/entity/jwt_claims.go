@@ -1,9 +1,24 @@
 package entity
 
-import "github.com/dgrijalva/jwt-go"
+import (
+	"github.com/dgrijalva/jwt-go"
+	"time"
+)
 
+// JwtClaims are the claims carried by access tokens. Every token is issued with a unique
+// jti (StandardClaims.Id) so that it can be revoked before it expires. Access tokens have no
+// audience, tickets have the one they were issued for. Roles are the roles at the time the
+// token was issued, for clients to show; authorization checks the roles stored for the user.
 type JwtClaims struct {
-	Username string `json:"username"`
-	UserId  int64 `json:"userId"`
+	Username string   `json:"username"`
+	UserId   int64    `json:"userId"`
+	Roles    []string `json:"roles"`
 	jwt.StandardClaims
-}
\ No newline at end of file
+}
+
+// Ticket stands in for an access token on requests to one audience, for clients that cannot
+// send the Authorization header, such as EventSource in browsers.
+type Ticket struct {
+	Ticket    string    `json:"ticket"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
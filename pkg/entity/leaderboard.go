@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"time"
+)
+
+// Leaderboard windows. Daily and weekly leaderboards start at midnight UTC of the current
+// day and of the Monday of the current week.
+const (
+	LeaderboardDaily   = "daily"
+	LeaderboardWeekly  = "weekly"
+	LeaderboardAllTime = "all_time"
+)
+
+// LeaderboardEntry is the rank of a user by their best score. Equal scores are ranked by the
+// shorter completion time, then by the earlier submission. On the global leaderboard Score and
+// DurationSeconds add up the best attempt at every quiz the user took.
+type LeaderboardEntry struct {
+	Rank            int       `json:"rank"`
+	UserId          int64     `json:"userId"`
+	Username        string    `json:"username"`
+	Score           float64   `json:"score"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	SubmittedAt     time.Time `json:"submittedAt"`
+	Quizzes         int       `json:"quizzes,omitempty"`
+}
+
+// Leaderboard holds the top entries of a window and the entry of the calling user, which is
+// also set when they are not among the top entries.
+type Leaderboard struct {
+	QuizId  int64               `json:"quizId,omitempty"`
+	Window  string              `json:"window"`
+	Since   *time.Time          `json:"since,omitempty"`
+	Entries []*LeaderboardEntry `json:"entries"`
+	Me      *LeaderboardEntry   `json:"me,omitempty"`
+}
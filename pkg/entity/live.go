@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"time"
+)
+
+// Live session states. A session waits in the lobby until the host starts it, then alternates
+// between a question that is open for answers and the results of that round until it is finished.
+const (
+	LiveLobby    = "lobby"
+	LiveQuestion = "question"
+	LiveResults  = "results"
+	LiveFinished = "finished"
+)
+
+// Live commands sent by the clients of a session. Every connection starts with an auth command
+// that carries the access token. Only the host may start, advance and end the session.
+const (
+	LiveAuth   = "auth"
+	LiveStart  = "start"
+	LiveNext   = "next"
+	LiveEnd    = "end"
+	LiveAnswer = "answer"
+)
+
+// Live events pushed to the clients of a session. State is the snapshot sent to every client
+// when it connects or reconnects, Players is sent when a player joins or leaves.
+const (
+	LiveEventState    = "state"
+	LiveEventPlayers  = "players"
+	LiveEventQuestion = "question"
+	LiveEventAnswered = "answered"
+	LiveEventResults  = "results"
+	LiveEventFinished = "finished"
+	LiveEventError    = "error"
+)
+
+// LiveSession is a game of a quiz played by everyone at the same time. Players join with the
+// Code. Round is the number of the current question, starting at 1, and 0 in the lobby.
+type LiveSession struct {
+	Code          string    `json:"code"`
+	QuizId        int64     `json:"quizId"`
+	Title         string    `json:"title"`
+	HostId        int64     `json:"hostId"`
+	State         string    `json:"state"`
+	Round         int       `json:"round"`
+	QuestionCount int       `json:"questionCount"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// LiveStanding is the total score of a player. Players with equal scores share their rank.
+type LiveStanding struct {
+	Rank      int    `json:"rank"`
+	UserId    int64  `json:"userId"`
+	Username  string `json:"username"`
+	Score     int    `json:"score"`
+	Connected bool   `json:"connected"`
+}
+
+// LiveRoundResult is what a player scored for the question of a round. Points reward correct
+// answers and, among them, the faster ones.
+type LiveRoundResult struct {
+	QuestionId int64         `json:"questionId"`
+	Answered   bool          `json:"answered"`
+	Result     *AnswerResult `json:"result,omitempty"`
+	Points     int           `json:"points"`
+}
+
+// LiveCommand is a message from a client. Token is only set for auth, QuestionId and Response
+// only for answer.
+type LiveCommand struct {
+	Type       string    `json:"type"`
+	Token      string    `json:"token,omitempty"`
+	QuestionId int64     `json:"questionId,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// LiveEvent is a message to a client. The question of an open round comes without its answers
+// and with the Deadline for answering it; the results of the round reveal them.
+type LiveEvent struct {
+	Type             string           `json:"type"`
+	Session          *LiveSession     `json:"session,omitempty"`
+	Question         *Question        `json:"question,omitempty"`
+	Deadline         *time.Time       `json:"deadline,omitempty"`
+	RemainingSeconds *int             `json:"remainingSeconds,omitempty"`
+	Standings        []*LiveStanding  `json:"standings,omitempty"`
+	Result           *LiveRoundResult `json:"result,omitempty"`
+	Error            string           `json:"error,omitempty"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+)
+
+// Migration is a versioned change of the database schema, with the sql that makes the change
+// and the sql that undoes it.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// AppliedMigration is a migration recorded as applied in the schema version table.
+type AppliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+// MigrationStatus tells whether a migration is applied. Unknown migrations are applied to the
+// database but not part of this version of the server, which happens when it is older than
+// the database.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	Unknown   bool       `json:"unknown,omitempty"`
+}
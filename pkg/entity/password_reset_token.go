@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+)
+
+// PasswordResetToken is a single-use token mailed to a user to set a new password.
+// Only a hash of the token is stored.
+type PasswordResetToken struct {
+	Id        int64
+	UserId    int64
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+}
@@ -0,0 +1,163 @@
+package entity
+
+import (
+	"sort"
+	"time"
+)
+
+// Question types, each graded by its own grader.
+const (
+	QuestionSingleChoice   = "single_choice"
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionTrueFalse      = "true_false"
+	QuestionOrdering       = "ordering"
+	QuestionMatching       = "matching"
+	QuestionNumeric        = "numeric"
+	QuestionShortText      = "short_text"
+)
+
+// Question difficulties, used by draw rules to pick questions from the question bank.
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// Quiz is a set of questions. A quiz with a TimeLimitSeconds has to be finished within that
+// many seconds of starting an attempt. Every attempt at a quiz with DrawRules also gets a random
+// selection of questions from the question bank of the owner.
+type Quiz struct {
+	Id               int64       `json:"id"`
+	OwnerId          int64       `json:"ownerId"`
+	Title            string      `json:"title"`
+	Description      string      `json:"description"`
+	TimeLimitSeconds int         `json:"timeLimitSeconds,omitempty"`
+	Questions        []*Question `json:"questions,omitempty"`
+	DrawRules        []*DrawRule `json:"drawRules,omitempty"`
+	CreatedAt        time.Time   `json:"createdAt"`
+	UpdatedAt        time.Time   `json:"updatedAt"`
+}
+
+// Question is a question of one of the Question* types. Choice questions mark their correct
+// options, ordering questions list their options in the correct order, matching questions pair
+// every option with a Match, and numeric and short text questions keep their solution in Answer.
+// A question with a TimeLimitSeconds has to be answered within that many seconds of opening it.
+// A question without a QuizId belongs to the question bank of its OwnerId.
+type Question struct {
+	Id               int64           `json:"id"`
+	QuizId           int64           `json:"quizId,omitempty"`
+	OwnerId          int64           `json:"ownerId,omitempty"`
+	Position         int             `json:"position"`
+	Type             string          `json:"type"`
+	Text             string          `json:"text"`
+	TimeLimitSeconds int             `json:"timeLimitSeconds,omitempty"`
+	Tags             []string        `json:"tags,omitempty"`
+	Difficulty       string          `json:"difficulty,omitempty"`
+	Options          []*AnswerOption `json:"options"`
+	Answer           *QuestionAnswer `json:"answer,omitempty"`
+	// Choices are the matches of a matching question in alphabetical order, shown instead of
+	// the pairs to users that may not see the answers:
+	Choices []string `json:"choices,omitempty"`
+}
+
+// AnswerOption is one of the answers offered for a question. IsCorrect and Match are only
+// shown to the owner of the quiz.
+type AnswerOption struct {
+	Id         int64  `json:"id"`
+	QuestionId int64  `json:"questionId"`
+	Position   int    `json:"position"`
+	Text       string `json:"text"`
+	IsCorrect  bool   `json:"isCorrect,omitempty"`
+	Match      string `json:"match,omitempty"`
+}
+
+// QuestionAnswer is the solution of a numeric or short text question. A numeric response is
+// correct within Tolerance of Value. A short text response is correct when it equals one of
+// Accepted after trimming, collapsing whitespace and, unless CaseSensitive, ignoring case, or
+// when it is at most MaxDistance edits away from one.
+type QuestionAnswer struct {
+	Value         float64  `json:"value,omitempty"`
+	Tolerance     float64  `json:"tolerance,omitempty"`
+	Accepted      []string `json:"accepted,omitempty"`
+	CaseSensitive bool     `json:"caseSensitive,omitempty"`
+	MaxDistance   int      `json:"maxDistance,omitempty"`
+}
+
+// DrawRule picks Count questions from the question bank for an attempt, among those with the
+// Tag and the Difficulty. An empty Tag or Difficulty matches every question.
+type DrawRule struct {
+	Tag        string `json:"tag,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	Count      int    `json:"count"`
+}
+
+// BankQuery filters the question bank of an owner by tag and difficulty, when set.
+type BankQuery struct {
+	OwnerId    int64
+	Tag        string
+	Difficulty string
+}
+
+// QuizListQuery filters and pages quizzes by ascending id.
+type QuizListQuery struct {
+	OwnerId int64
+	AfterId int64
+	Limit   int
+}
+
+type QuizListPage struct {
+	Quizzes []*Quiz `json:"quizzes"`
+	NextId  int64   `json:"nextId,omitempty"`
+}
+
+// WithoutAnswers returns a copy of the quiz and its questions with IsCorrect cleared. Timed
+// questions are also left without their text and options, which only opening the question
+// during an attempt reveals, so that they cannot be read before their clock starts.
+func (q *Quiz) WithoutAnswers() *Quiz {
+	quiz := *q
+	quiz.Questions = make([]*Question, len(q.Questions))
+	for i, question := range q.Questions {
+		quiz.Questions[i] = question.WithoutAnswers()
+		if question.TimeLimitSeconds > 0 {
+			quiz.Questions[i].Text = ""
+			quiz.Questions[i].Options = make([]*AnswerOption, 0)
+			quiz.Questions[i].Choices = nil
+		}
+	}
+
+	return &quiz
+}
+
+// WithoutAnswers returns a copy of the question that does not give its solution away. Ordering
+// questions get their options sorted by id and without positions, and matching questions list
+// their matches as Choices.
+func (q *Question) WithoutAnswers() *Question {
+	question := *q
+	question.Answer = nil
+	question.Choices = nil
+	question.Options = make([]*AnswerOption, len(q.Options))
+	for i, option := range q.Options {
+		o := *option
+		o.IsCorrect = false
+		o.Match = ""
+		question.Options[i] = &o
+	}
+
+	switch q.Type {
+	case QuestionOrdering:
+		for _, o := range question.Options {
+			o.Position = 0
+		}
+		sort.Slice(question.Options, func(i, j int) bool {
+			return question.Options[i].Id < question.Options[j].Id
+		})
+	case QuestionMatching:
+		question.Choices = make([]string, 0, len(q.Options))
+		for _, option := range q.Options {
+			question.Choices = append(question.Choices, option.Match)
+		}
+		sort.Strings(question.Choices)
+	}
+
+	return &question
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+)
+
+// RefreshToken is a long-lived, single-use credential that can be exchanged for a new JWT.
+// Only a hash of the token is stored; every rotation of a token stays in the same family
+// so that a replayed token can revoke all of its descendants.
+type RefreshToken struct {
+	Id        int64
+	UserId    int64
+	FamilyId  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
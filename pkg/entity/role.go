@@ -0,0 +1,52 @@
+package entity
+
+// Roles that can be assigned to a user in the user_roles table.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Permissions granted through roles.
+const (
+	PermissionUsersRead  = "users:read"
+	PermissionUsersList  = "users:list"
+	PermissionUsersWrite = "users:write"
+)
+
+// RolePermissions maps each role to the permissions it grants.
+var RolePermissions = map[string][]string{
+	RoleUser: {
+		PermissionUsersRead,
+	},
+	RoleAdmin: {
+		PermissionUsersRead,
+		PermissionUsersList,
+		PermissionUsersWrite,
+	},
+}
+
+// HasRole reports whether roles contains any of the wanted roles.
+func HasRole(roles []string, wanted ...string) bool {
+	for _, role := range roles {
+		for _, w := range wanted {
+			if role == w {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// HasPermission reports whether any of the roles grants the permission.
+func HasPermission(roles []string, permission string) bool {
+	for _, role := range roles {
+		for _, p := range RolePermissions[role] {
+			if p == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
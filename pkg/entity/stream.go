@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+)
+
+// Stream event types. Leaderboard events carry the all-time leaderboard of the quiz whenever it
+// changes, attempt events an AttemptSubmission.
+const (
+	StreamLeaderboard      = "leaderboard"
+	StreamAttemptSubmitted = "attempt_submitted"
+)
+
+// StreamEvent is an event of the stream of a quiz. Ids increase with every event, so that a
+// client that reconnects can resume after the last one it received. Snapshots sent to clients
+// that cannot resume have no Id.
+type StreamEvent struct {
+	Id     int64
+	QuizId int64
+	Type   string
+	Data   interface{}
+}
+
+// AttemptSubmission is the outcome of an attempt that was submitted, by the user or because
+// its time was up.
+type AttemptSubmission struct {
+	AttemptId   int64     `json:"attemptId"`
+	QuizId      int64     `json:"quizId"`
+	UserId      int64     `json:"userId"`
+	Score       float64   `json:"score"`
+	MaxScore    float64   `json:"maxScore"`
+	SubmittedAt time.Time `json:"submittedAt"`
+}
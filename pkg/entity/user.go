@@ -5,9 +5,21 @@ import (
 )
 
 type User struct {
-	Id			  int64 `json:"id"`
-	Username	  string `json:"username"`
-	Password      string `json:"password"`
-	CreatedAt     time.Time `json:"createdAt"`
-	LastLoginAt   time.Time `json:"lastLoginAt"`
+	Id          int64     `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email,omitempty"`
+	Password    string    `json:"password"`
+	Roles       []string  `json:"roles"`
+	CreatedAt   time.Time `json:"createdAt"`
+	LastLoginAt time.Time `json:"lastLoginAt"`
+}
+
+// Public returns a copy of the user that other users may see, without the email address and
+// the password.
+func (u *User) Public() *User {
+	user := *u
+	user.Email = ""
+	user.Password = ""
+
+	return &user
 }
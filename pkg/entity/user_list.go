@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+)
+
+// Columns users can be sorted by when listed.
+const (
+	UserSortCreatedAt   = "createdAt"
+	UserSortLastLoginAt = "lastLoginAt"
+	UserSortUsername    = "username"
+)
+
+// UserListQuery filters, sorts and pages a list of users. Nil filters are ignored.
+type UserListQuery struct {
+	UsernamePrefix  string
+	CreatedAfter    *time.Time
+	CreatedBefore   *time.Time
+	LastLoginAfter  *time.Time
+	LastLoginBefore *time.Time
+	SortBy          string
+	Descending      bool
+	Limit           int
+	After           *UserListCursor
+}
+
+// UserListCursor points at the last user of a page by its sort value and id.
+type UserListCursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d"`
+	Value      string `json:"v"`
+	Id         int64  `json:"i"`
+}
+
+type UserListPage struct {
+	Users      []*User `json:"users"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,145 @@
+package grader
+
+import (
+	"fmt"
+	"quiz-app/pkg/entity"
+	"strings"
+)
+
+// SingleChoice grades questions with exactly one correct option, of which one has to be selected.
+type SingleChoice struct{}
+
+func (SingleChoice) Validate(question *entity.Question) *entity.AppError {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	if countCorrect(question) != 1 {
+		return entity.NewInvalidQuestionError("a single choice question needs exactly one correct option")
+	}
+
+	return nil
+}
+
+func (SingleChoice) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	selected, err := selectedOptions(question, response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(selected) != 1 {
+		return nil, entity.ErrInvalidAnswer
+	}
+
+	if selected[0].IsCorrect {
+		return result(1), nil
+	}
+
+	return result(0), nil
+}
+
+// TrueFalse grades statements offered as two options, one of them correct.
+type TrueFalse struct{}
+
+func (TrueFalse) Validate(question *entity.Question) *entity.AppError {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	if len(question.Options) != 2 || countCorrect(question) != 1 {
+		return entity.NewInvalidQuestionError("a true/false question needs a true and a false option, one of them correct")
+	}
+
+	return nil
+}
+
+func (TrueFalse) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	return SingleChoice{}.Grade(question, response)
+}
+
+// MultipleChoice grades questions with any number of correct options. Every selected correct
+// option earns a share of the score and every selected wrong option takes one away, so that
+// selecting everything scores nothing.
+type MultipleChoice struct{}
+
+func (MultipleChoice) Validate(question *entity.Question) *entity.AppError {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	if countCorrect(question) == 0 {
+		return entity.NewInvalidQuestionError("a multiple choice question needs at least one correct option")
+	}
+
+	return nil
+}
+
+func (MultipleChoice) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	selected, err := selectedOptions(question, response)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := 0
+	for _, option := range selected {
+		if option.IsCorrect {
+			hits++
+		} else {
+			hits--
+		}
+	}
+
+	return result(float64(hits) / float64(countCorrect(question))), nil
+}
+
+// validateOptions checks that the question has text and at least minOptions options with text.
+func validateOptions(question *entity.Question, minOptions int) *entity.AppError {
+	if strings.TrimSpace(question.Text) == "" {
+		return entity.NewInvalidQuestionError("a question needs text")
+	}
+
+	if len(question.Options) < minOptions {
+		return entity.NewInvalidQuestionError(fmt.Sprintf("a question of this type needs at least %d options", minOptions))
+	}
+
+	for _, option := range question.Options {
+		if option == nil || strings.TrimSpace(option.Text) == "" {
+			return entity.NewInvalidQuestionError("every option needs text")
+		}
+	}
+
+	return nil
+}
+
+func countCorrect(question *entity.Question) int {
+	n := 0
+	for _, option := range question.Options {
+		if option.IsCorrect {
+			n++
+		}
+	}
+
+	return n
+}
+
+// selectedOptions returns the options of the question selected by the response, in response
+// order. Unknown and repeated options are rejected.
+func selectedOptions(question *entity.Question, response *entity.Response) ([]*entity.AnswerOption, *entity.AppError) {
+	options := make(map[int64]*entity.AnswerOption, len(question.Options))
+	for _, option := range question.Options {
+		options[option.Id] = option
+	}
+
+	selected := make([]*entity.AnswerOption, 0, len(response.OptionIds))
+	for _, optionId := range response.OptionIds {
+		option, ok := options[optionId]
+		if !ok {
+			return nil, entity.ErrInvalidAnswer
+		}
+
+		selected = append(selected, option)
+		delete(options, optionId)
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,70 @@
+package grader
+
+import (
+	"fmt"
+	"quiz-app/pkg/entity"
+)
+
+// Grader validates and grades the questions of one type.
+type Grader interface {
+	// Validate checks that the question has everything needed to grade it.
+	Validate(question *entity.Question) *entity.AppError
+	// Grade scores the response to the question between 0 and 1. A response that does not
+	// fit the question returns ErrInvalidAnswer.
+	Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError)
+}
+
+var graders = map[string]Grader{
+	entity.QuestionSingleChoice:   SingleChoice{},
+	entity.QuestionMultipleChoice: MultipleChoice{},
+	entity.QuestionTrueFalse:      TrueFalse{},
+	entity.QuestionOrdering:       Ordering{},
+	entity.QuestionMatching:       Matching{},
+	entity.QuestionNumeric:        Numeric{},
+	entity.QuestionShortText:      ShortText{},
+}
+
+// Register makes g grade questions of questionType, replacing the grader for an existing type.
+// It is not safe to call while questions are graded and is meant to be called on start up.
+func Register(questionType string, g Grader) {
+	graders[questionType] = g
+}
+
+// For returns the grader for the type of question.
+func For(questionType string) (Grader, *entity.AppError) {
+	g, ok := graders[questionType]
+	if !ok {
+		return nil, entity.NewInvalidQuestionError(fmt.Sprintf("unknown question type %q", questionType))
+	}
+
+	return g, nil
+}
+
+// Validate checks the question with the grader for its type.
+func Validate(question *entity.Question) *entity.AppError {
+	g, err := For(question.Type)
+	if err != nil {
+		return err
+	}
+
+	return g.Validate(question)
+}
+
+// Grade scores the response with the grader for the type of the question.
+func Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	g, err := For(question.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Grade(question, response)
+}
+
+// result returns the result for a score, which is only correct when it is full.
+func result(score float64) *entity.AnswerResult {
+	if score < 0 {
+		score = 0
+	}
+
+	return &entity.AnswerResult{IsCorrect: score >= 1, Score: score}
+}
@@ -0,0 +1,217 @@
+package grader
+
+import (
+	"errors"
+	"quiz-app/pkg/entity"
+	"testing"
+)
+
+func number(n float64) *float64 {
+	return &n
+}
+
+func choiceQuestion(questionType string, correct ...int64) *entity.Question {
+	question := &entity.Question{Type: questionType, Text: "Pick"}
+	for _, id := range []int64{1, 2, 3, 4} {
+		option := &entity.AnswerOption{Id: id, Text: "option"}
+		for _, c := range correct {
+			option.IsCorrect = option.IsCorrect || c == id
+		}
+		question.Options = append(question.Options, option)
+	}
+
+	return question
+}
+
+func TestValidate(t *testing.T) {
+
+	t.Run("Validate should reject an unknown question type", func(t *testing.T) {
+		if err := Validate(&entity.Question{Type: "essay", Text: "Why?"}); !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Validate should reject a single choice question with two correct options", func(t *testing.T) {
+		if err := Validate(choiceQuestion(entity.QuestionSingleChoice, 1, 2)); !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Validate should reject a true/false question with more than two options", func(t *testing.T) {
+		if err := Validate(choiceQuestion(entity.QuestionTrueFalse, 1)); !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Validate should reject a matching question with an option without match", func(t *testing.T) {
+		question := choiceQuestion(entity.QuestionMatching)
+		for _, option := range question.Options {
+			option.Match = "a"
+		}
+		question.Options[2].Match = " "
+
+		if err := Validate(question); !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Validate should reject a numeric question with a negative tolerance", func(t *testing.T) {
+		question := &entity.Question{Type: entity.QuestionNumeric, Text: "Pi?", Answer: &entity.QuestionAnswer{Value: 3.14, Tolerance: -1}}
+
+		if err := Validate(question); !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Validate should reject a short text question without accepted answers", func(t *testing.T) {
+		question := &entity.Question{Type: entity.QuestionShortText, Text: "Capital of France?", Answer: &entity.QuestionAnswer{}}
+
+		if err := Validate(question); !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+}
+
+func TestGrade(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		question *entity.Question
+		response *entity.Response
+		score    float64
+	}{
+		{
+			name:     "single choice should score the correct option",
+			question: choiceQuestion(entity.QuestionSingleChoice, 2),
+			response: &entity.Response{OptionIds: []int64{2}},
+			score:    1,
+		},
+		{
+			name:     "single choice should not score a wrong option",
+			question: choiceQuestion(entity.QuestionSingleChoice, 2),
+			response: &entity.Response{OptionIds: []int64{3}},
+			score:    0,
+		},
+		{
+			name:     "multiple choice should score all correct options",
+			question: choiceQuestion(entity.QuestionMultipleChoice, 1, 2),
+			response: &entity.Response{OptionIds: []int64{2, 1}},
+			score:    1,
+		},
+		{
+			name:     "multiple choice should give partial credit for some correct options",
+			question: choiceQuestion(entity.QuestionMultipleChoice, 1, 2),
+			response: &entity.Response{OptionIds: []int64{1}},
+			score:    0.5,
+		},
+		{
+			name:     "multiple choice should take credit away for wrong options",
+			question: choiceQuestion(entity.QuestionMultipleChoice, 1, 2),
+			response: &entity.Response{OptionIds: []int64{1, 2, 3, 4}},
+			score:    0,
+		},
+		{
+			name:     "ordering should score options in their place",
+			question: choiceQuestion(entity.QuestionOrdering),
+			response: &entity.Response{OptionIds: []int64{1, 2, 4, 3}},
+			score:    0.5,
+		},
+		{
+			name: "matching should score correct pairs",
+			question: &entity.Question{Type: entity.QuestionMatching, Options: []*entity.AnswerOption{
+				{Id: 1, Text: "France", Match: "Paris"},
+				{Id: 2, Text: "Italy", Match: "Rome"},
+			}},
+			response: &entity.Response{Matches: map[int64]string{1: "Paris", 2: "Paris"}},
+			score:    0.5,
+		},
+		{
+			name:     "numeric should score a number within the tolerance",
+			question: &entity.Question{Type: entity.QuestionNumeric, Answer: &entity.QuestionAnswer{Value: 3.14, Tolerance: 0.01}},
+			response: &entity.Response{Number: number(3.1491)},
+			score:    1,
+		},
+		{
+			name:     "numeric should not score a number outside the tolerance",
+			question: &entity.Question{Type: entity.QuestionNumeric, Answer: &entity.QuestionAnswer{Value: 3.14, Tolerance: 0.01}},
+			response: &entity.Response{Number: number(3.16)},
+			score:    0,
+		},
+		{
+			name:     "short text should ignore case and whitespace",
+			question: &entity.Question{Type: entity.QuestionShortText, Answer: &entity.QuestionAnswer{Accepted: []string{"New York"}}},
+			response: &entity.Response{Text: "  new   york "},
+			score:    1,
+		},
+		{
+			name:     "short text should respect case when asked to",
+			question: &entity.Question{Type: entity.QuestionShortText, Answer: &entity.QuestionAnswer{Accepted: []string{"Go"}, CaseSensitive: true}},
+			response: &entity.Response{Text: "go"},
+			score:    0,
+		},
+		{
+			name:     "short text should not accept a response beyond the maximum distance",
+			question: &entity.Question{Type: entity.QuestionShortText, Answer: &entity.QuestionAnswer{Accepted: []string{"Mississippi"}, MaxDistance: 2}},
+			response: &entity.Response{Text: "Misisipi"},
+			score:    0,
+		},
+		{
+			name:     "short text should accept a response one edit away",
+			question: &entity.Question{Type: entity.QuestionShortText, Answer: &entity.QuestionAnswer{Accepted: []string{"Mississippi"}, MaxDistance: 1}},
+			response: &entity.Response{Text: "Missisippi"},
+			score:    1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run("Grade: "+test.name, func(t *testing.T) {
+			result, err := Grade(test.question, test.response)
+			if err != nil {
+				t.Fatalf("unexpected error: [%s]", err)
+			}
+
+			if result.Score != test.score || result.IsCorrect != (test.score == 1) {
+				t.Errorf("expected score %v, got %+v", test.score, result)
+			}
+		})
+	}
+
+	t.Run("Grade should reject responses that do not fit the question", func(t *testing.T) {
+		invalid := []struct {
+			question *entity.Question
+			response *entity.Response
+		}{
+			{choiceQuestion(entity.QuestionSingleChoice, 1), &entity.Response{OptionIds: []int64{1, 2}}},
+			{choiceQuestion(entity.QuestionMultipleChoice, 1), &entity.Response{OptionIds: []int64{1, 1}}},
+			{choiceQuestion(entity.QuestionMultipleChoice, 1), &entity.Response{OptionIds: []int64{9}}},
+			{choiceQuestion(entity.QuestionOrdering), &entity.Response{OptionIds: []int64{1, 2}}},
+			{choiceQuestion(entity.QuestionMatching), &entity.Response{Matches: map[int64]string{9: "a"}}},
+			{&entity.Question{Type: entity.QuestionNumeric, Answer: &entity.QuestionAnswer{}}, &entity.Response{}},
+		}
+
+		for i, test := range invalid {
+			if _, err := Grade(test.question, test.response); err != entity.ErrInvalidAnswer {
+				t.Errorf("expected invalid answer for case %d, got [%v]", i, err)
+			}
+		}
+	})
+}
+
+func TestLevenshtein(t *testing.T) {
+
+	t.Run("levenshtein should count insertions, deletions and substitutions", func(t *testing.T) {
+		cases := map[[2]string]int{
+			{"", ""}:              0,
+			{"kitten", "sitting"}: 3,
+			{"flaw", "lawn"}:      2,
+			{"héllo", "hello"}:    1,
+			{"gumbo", "gambol"}:   2,
+		}
+
+		for c, want := range cases {
+			if got := levenshtein(c[0], c[1]); got != want {
+				t.Errorf("levenshtein(%q, %q) = %d, want %d", c[0], c[1], got, want)
+			}
+		}
+	})
+}
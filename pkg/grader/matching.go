@@ -0,0 +1,45 @@
+package grader
+
+import (
+	"quiz-app/pkg/entity"
+	"strings"
+)
+
+// Matching grades questions that pair every option with its Match. Every correctly paired
+// option earns a share of the score.
+type Matching struct{}
+
+func (Matching) Validate(question *entity.Question) *entity.AppError {
+	if err := validateOptions(question, 2); err != nil {
+		return err
+	}
+
+	for _, option := range question.Options {
+		if strings.TrimSpace(option.Match) == "" {
+			return entity.NewInvalidQuestionError("every option of a matching question needs a match")
+		}
+	}
+
+	return nil
+}
+
+func (Matching) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	options := make(map[int64]*entity.AnswerOption, len(question.Options))
+	for _, option := range question.Options {
+		options[option.Id] = option
+	}
+
+	matched := 0
+	for optionId, match := range response.Matches {
+		option, ok := options[optionId]
+		if !ok {
+			return nil, entity.ErrInvalidAnswer
+		}
+
+		if strings.TrimSpace(match) == strings.TrimSpace(option.Match) {
+			matched++
+		}
+	}
+
+	return result(float64(matched) / float64(len(question.Options))), nil
+}
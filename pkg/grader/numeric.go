@@ -0,0 +1,38 @@
+package grader
+
+import (
+	"math"
+	"quiz-app/pkg/entity"
+	"strings"
+)
+
+// Numeric grades questions answered with a number within the tolerance of the answer value.
+type Numeric struct{}
+
+func (Numeric) Validate(question *entity.Question) *entity.AppError {
+	if strings.TrimSpace(question.Text) == "" {
+		return entity.NewInvalidQuestionError("a question needs text")
+	}
+
+	if question.Answer == nil || math.IsNaN(question.Answer.Value) || math.IsInf(question.Answer.Value, 0) {
+		return entity.NewInvalidQuestionError("a numeric question needs an answer value")
+	}
+
+	if question.Answer.Tolerance < 0 || math.IsNaN(question.Answer.Tolerance) {
+		return entity.NewInvalidQuestionError("the tolerance of a numeric question cannot be negative")
+	}
+
+	return nil
+}
+
+func (Numeric) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	if response.Number == nil || math.IsNaN(*response.Number) {
+		return nil, entity.ErrInvalidAnswer
+	}
+
+	if math.Abs(*response.Number-question.Answer.Value) <= question.Answer.Tolerance {
+		return result(1), nil
+	}
+
+	return result(0), nil
+}
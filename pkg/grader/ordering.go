@@ -0,0 +1,34 @@
+package grader
+
+import (
+	"quiz-app/pkg/entity"
+)
+
+// Ordering grades questions whose options have to be put in order. The options of the question
+// are in the correct order, and every option the response puts in its place earns a share of
+// the score.
+type Ordering struct{}
+
+func (Ordering) Validate(question *entity.Question) *entity.AppError {
+	return validateOptions(question, 2)
+}
+
+func (Ordering) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	ordered, err := selectedOptions(question, response)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ordered) != len(question.Options) {
+		return nil, entity.ErrInvalidAnswer
+	}
+
+	inPlace := 0
+	for i, option := range ordered {
+		if option.Id == question.Options[i].Id {
+			inPlace++
+		}
+	}
+
+	return result(float64(inPlace) / float64(len(question.Options))), nil
+}
@@ -0,0 +1,97 @@
+package grader
+
+import (
+	"quiz-app/pkg/entity"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTextLength bounds the responses compared by edit distance, which is quadratic:
+const maxTextLength = 1000
+
+// ShortText grades questions answered with a short text that has to match one of the accepted
+// answers after normalisation, or come within their maximum edit distance.
+type ShortText struct{}
+
+func (ShortText) Validate(question *entity.Question) *entity.AppError {
+	if strings.TrimSpace(question.Text) == "" {
+		return entity.NewInvalidQuestionError("a question needs text")
+	}
+
+	if question.Answer == nil || len(question.Answer.Accepted) == 0 {
+		return entity.NewInvalidQuestionError("a short text question needs at least one accepted answer")
+	}
+
+	for _, accepted := range question.Answer.Accepted {
+		if normalize(accepted, true) == "" {
+			return entity.NewInvalidQuestionError("accepted answers cannot be empty")
+		}
+	}
+
+	if question.Answer.MaxDistance < 0 {
+		return entity.NewInvalidQuestionError("the maximum distance of a short text question cannot be negative")
+	}
+
+	return nil
+}
+
+func (ShortText) Grade(question *entity.Question, response *entity.Response) (*entity.AnswerResult, *entity.AppError) {
+	if utf8.RuneCountInString(response.Text) > maxTextLength {
+		return nil, entity.ErrInvalidAnswer
+	}
+
+	text := normalize(response.Text, question.Answer.CaseSensitive)
+	if text == "" {
+		return result(0), nil
+	}
+
+	for _, accepted := range question.Answer.Accepted {
+		accepted = normalize(accepted, question.Answer.CaseSensitive)
+		if text == accepted {
+			return result(1), nil
+		}
+
+		if question.Answer.MaxDistance > 0 && levenshtein(text, accepted) <= question.Answer.MaxDistance {
+			return result(1), nil
+		}
+	}
+
+	return result(0), nil
+}
+
+// normalize trims the text, collapses whitespace and lower cases it unless caseSensitive.
+func normalize(text string, caseSensitive bool) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if !caseSensitive {
+		text = strings.ToLower(text)
+	}
+
+	return text
+}
+
+// levenshtein returns the number of rune insertions, deletions and substitutions that turn a into b.
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
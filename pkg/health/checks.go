@@ -0,0 +1,53 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"quiz-app/pkg/entity"
+)
+
+type checkFunc struct {
+	name  string
+	check func(ctx context.Context) *entity.AppError
+}
+
+func (c *checkFunc) Name() string {
+	return c.name
+}
+
+func (c *checkFunc) Check(ctx context.Context) *entity.AppError {
+	return c.check(ctx)
+}
+
+// NewChecker returns a HealthChecker with the name that runs the check.
+func NewChecker(name string, check func(ctx context.Context) *entity.AppError) HealthChecker {
+	return &checkFunc{name: name, check: check}
+}
+
+// DatabaseChecker checks that the database answers a ping.
+func DatabaseChecker(pool Pinger) HealthChecker {
+	return NewChecker("database", func(ctx context.Context) *entity.AppError {
+		if err := pool.PingContext(ctx); err != nil {
+			return entity.NewAppError(err)
+		}
+
+		return nil
+	})
+}
+
+// MigrationChecker checks that every migration is applied, since the repositories expect the
+// latest schema.
+func MigrationChecker(migrations PendingCounter) HealthChecker {
+	return NewChecker("migrations", func(ctx context.Context) *entity.AppError {
+		pending, err := migrations.Pending(ctx)
+		if err != nil {
+			return err
+		}
+
+		if pending > 0 {
+			return entity.NewAppError(fmt.Errorf("%d pending migrations", pending))
+		}
+
+		return nil
+	})
+}
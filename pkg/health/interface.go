@@ -0,0 +1,23 @@
+package health
+
+import (
+	"context"
+	"quiz-app/pkg/entity"
+)
+
+// HealthChecker checks a dependency the server needs to take requests. Subsystems register
+// their checkers with Service.Register; a check that does not return before ctx is done fails.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) *entity.AppError
+}
+
+// Pinger is a connection pool that can reach its database, such as sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PendingCounter counts the migrations that still have to be applied, see migration.Service.
+type PendingCounter interface {
+	Pending(ctx context.Context) (int, *entity.AppError)
+}
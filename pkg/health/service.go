@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"quiz-app/pkg/entity"
+	"sync"
+	"time"
+)
+
+type Service struct {
+	timeout time.Duration
+	now     func() time.Time
+
+	mu           sync.Mutex
+	checkers     []HealthChecker
+	shuttingDown bool
+}
+
+// InitService creates the service that checks whether the server is ready. Every check gets
+// up to timeout.
+func InitService(timeout time.Duration) *Service {
+	return &Service{
+		timeout:  timeout,
+		now:      time.Now,
+		checkers: make([]HealthChecker, 0),
+	}
+}
+
+// Register adds the checker to the readiness checks.
+func (s *Service) Register(checker HealthChecker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.checkers = append(s.checkers, checker)
+}
+
+// ShutDown fails the readiness of the server from now on, so that load balancers stop sending
+// it requests while it shuts down.
+func (s *Service) ShutDown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shuttingDown = true
+}
+
+// Ready runs the registered checks at the same time, after the check that the server is not
+// shutting down, and returns their results in that order.
+func (s *Service) Ready(ctx context.Context) *entity.Readiness {
+	s.mu.Lock()
+	checkers := append([]HealthChecker{s.shutdownChecker(s.shuttingDown)}, s.checkers...)
+	s.mu.Unlock()
+
+	readiness := &entity.Readiness{Status: entity.HealthOK, Checks: make([]*entity.HealthCheck, len(checkers))}
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker HealthChecker) {
+			defer wg.Done()
+			readiness.Checks[i] = s.run(ctx, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	for _, check := range readiness.Checks {
+		if check.Status != entity.HealthOK {
+			readiness.Status = entity.HealthFailing
+		}
+	}
+
+	return readiness
+}
+
+// run runs the check and stops waiting for it after the timeout. The readiness is served to
+// anyone, so the error a check returns is only logged, since a driver error can name hosts and
+// roles.
+func (s *Service) run(ctx context.Context, checker HealthChecker) *entity.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := s.now()
+	done := make(chan *entity.AppError, 1)
+	go func() {
+		done <- checker.Check(ctx)
+	}()
+
+	var err *entity.AppError
+	select {
+	case err = <-done:
+		if err != nil && err != entity.ErrShuttingDown {
+			log.Printf("health check %s failed: %s", checker.Name(), err)
+			err = entity.ErrHealthCheckFailed
+		}
+	case <-ctx.Done():
+		err = entity.NewAppError(fmt.Errorf("no answer within %s: %w", s.timeout, ctx.Err()))
+	}
+
+	check := &entity.HealthCheck{Name: checker.Name(), Status: entity.HealthOK, DurationMs: s.now().Sub(start).Milliseconds()}
+	if err != nil {
+		check.Status = entity.HealthFailing
+		check.Error = err.Error()
+	}
+
+	return check
+}
+
+func (s *Service) shutdownChecker(shuttingDown bool) HealthChecker {
+	return NewChecker("shutdown", func(ctx context.Context) *entity.AppError {
+		if shuttingDown {
+			return entity.ErrShuttingDown
+		}
+
+		return nil
+	})
+}
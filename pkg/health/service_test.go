@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"quiz-app/pkg/entity"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type pool struct {
+	err error
+}
+
+func (p *pool) PingContext(ctx context.Context) error {
+	return p.err
+}
+
+type migrations struct {
+	pending int
+}
+
+func (m *migrations) Pending(ctx context.Context) (int, *entity.AppError) {
+	return m.pending, nil
+}
+
+// hungMigrations answers once ctx is done, like a query to a database that does not answer.
+type hungMigrations struct {
+	returned chan struct{}
+}
+
+func (m *hungMigrations) Pending(ctx context.Context) (int, *entity.AppError) {
+	defer close(m.returned)
+
+	<-ctx.Done()
+	return 0, entity.NewAppError(ctx.Err())
+}
+
+func TestReady(t *testing.T) {
+
+	t.Run("Ready should be ok when every check is", func(t *testing.T) {
+		service := InitService(time.Second)
+		service.Register(DatabaseChecker(&pool{}))
+		service.Register(MigrationChecker(&migrations{}))
+
+		readiness := service.Ready(context.Background())
+
+		if readiness.Status != entity.HealthOK || len(readiness.Checks) != 3 {
+			t.Fatal(readiness)
+		}
+
+		for i, name := range []string{"shutdown", "database", "migrations"} {
+			if readiness.Checks[i].Name != name || readiness.Checks[i].Status != entity.HealthOK {
+				t.Error(readiness.Checks[i])
+			}
+		}
+	})
+
+	t.Run("Ready should report the checks that fail without their errors", func(t *testing.T) {
+		service := InitService(time.Second)
+		service.Register(DatabaseChecker(&pool{err: errors.New("dial tcp 10.0.3.7:5432: connection refused")}))
+		service.Register(MigrationChecker(&migrations{pending: 2}))
+
+		readiness := service.Ready(context.Background())
+
+		if readiness.Status != entity.HealthFailing || readiness.Checks[0].Status != entity.HealthOK {
+			t.Fatal(readiness)
+		}
+
+		for _, check := range readiness.Checks[1:] {
+			if check.Status != entity.HealthFailing || check.Error != entity.ErrHealthCheckFailed.Error() {
+				t.Error(check)
+			}
+		}
+	})
+
+	t.Run("Ready should not wait for a check longer than the timeout", func(t *testing.T) {
+		service := InitService(20 * time.Millisecond)
+		release := make(chan struct{})
+		defer close(release)
+
+		service.Register(NewChecker("stuck", func(ctx context.Context) *entity.AppError {
+			<-release
+			return nil
+		}))
+
+		start := time.Now()
+		readiness := service.Ready(context.Background())
+
+		if readiness.Status != entity.HealthFailing || !strings.HasPrefix(readiness.Checks[1].Error, "no answer within 20ms") || time.Since(start) > time.Second {
+			t.Fatal(readiness.Checks[1])
+		}
+	})
+
+	t.Run("Ready should stop the migration query after the timeout", func(t *testing.T) {
+		service := InitService(20 * time.Millisecond)
+		migrations := &hungMigrations{returned: make(chan struct{})}
+		service.Register(MigrationChecker(migrations))
+
+		service.Ready(context.Background())
+
+		select {
+		case <-migrations.returned:
+		case <-time.After(time.Second):
+			t.Fail()
+		}
+	})
+
+	t.Run("Ready should fail once the server is shutting down", func(t *testing.T) {
+		service := InitService(time.Second)
+		service.ShutDown()
+
+		readiness := service.Ready(context.Background())
+
+		if readiness.Status != entity.HealthFailing || readiness.Checks[0].Error != entity.ErrShuttingDown.Error() {
+			t.Fatal(readiness.Checks[0])
+		}
+	})
+}
+
+func TestReadBuildInfo(t *testing.T) {
+
+	t.Run("ReadBuildInfo should keep the version and commit set at link time", func(t *testing.T) {
+		build := ReadBuildInfo("1.4.0", "0c73865")
+
+		if build.Version != "1.4.0" || build.Commit != "0c73865" || build.GoVersion != runtime.Version() {
+			t.Fatal(build)
+		}
+	})
+}
@@ -0,0 +1,32 @@
+package health
+
+import (
+	"quiz-app/pkg/entity"
+	"runtime"
+	"runtime/debug"
+)
+
+// ReadBuildInfo describes the build with the version and commit set when it was linked, as in
+// go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse HEAD)". Without a
+// commit, it takes the one the go command recorded from git, if it did.
+func ReadBuildInfo(version string, commit string) *entity.BuildInfo {
+	build := &entity.BuildInfo{Version: version, Commit: commit, GoVersion: runtime.Version()}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok || commit != "" {
+		return build
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			build.Commit = setting.Value
+		case "vcs.time":
+			build.CommitTime = setting.Value
+		case "vcs.modified":
+			build.Modified = setting.Value == "true"
+		}
+	}
+
+	return build
+}
@@ -0,0 +1,214 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"os"
+	"path/filepath"
+	"quiz-app/pkg/entity"
+	"strings"
+	"sync"
+)
+
+// Key is a JWT key identified by the kid header. Verification-only keys have no PrivateKey.
+type Key struct {
+	Id         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+	PublicKey  crypto.PublicKey
+}
+
+// Keyring holds one signing key and any number of verification keys, so that tokens signed with
+// a retired key stay valid until they expire while new tokens are signed with its replacement.
+type Keyring struct {
+	mu           sync.RWMutex
+	keys         map[string]*Key
+	signingKeyId string
+}
+
+func New() *Keyring {
+	return &Keyring{
+		keys: make(map[string]*Key),
+	}
+}
+
+// NewKey creates a key from an RSA or Ed25519 private or public key.
+func NewKey(id string, k interface{}) (*Key, *entity.AppError) {
+	if id == "" {
+		return nil, entity.NewAppError(errors.New("key id must not be empty"))
+	}
+
+	switch key := k.(type) {
+	case *rsa.PrivateKey:
+		return &Key{Id: id, Method: jwt.SigningMethodRS256, PrivateKey: key, PublicKey: &key.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &Key{Id: id, Method: jwt.SigningMethodRS256, PublicKey: key}, nil
+	case ed25519.PrivateKey:
+		return &Key{Id: id, Method: SigningMethodEd25519, PrivateKey: key, PublicKey: key.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{Id: id, Method: SigningMethodEd25519, PublicKey: key}, nil
+	}
+
+	return nil, entity.NewAppError(fmt.Errorf("unsupported key type %T", k))
+}
+
+// ParsePEM parses a PKCS#8 private key, a PKCS#1 RSA private key or a PKIX public key.
+func ParsePEM(id string, data []byte) (*Key, *entity.AppError) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, entity.NewAppError(errors.New("unable to decode pem block"))
+	}
+
+	var k interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		k, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		k, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		k, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported pem block type %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return NewKey(id, k)
+}
+
+func LoadFile(id string, path string) (*Key, *entity.AppError) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return ParsePEM(id, data)
+}
+
+// LoadDir loads every *.pem file in dir, using the file name without extension as key id.
+func LoadDir(dir string) ([]*Key, *entity.AppError) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := LoadFile(id, path)
+		if err != nil {
+			return nil, err.Wrap(fmt.Errorf("unable to load key %s", path))
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// GenerateKey creates a new Ed25519 signing key.
+func GenerateKey(id string) (*Key, *entity.AppError) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return NewKey(id, privateKey)
+}
+
+func (k *Keyring) Add(key *Key) *entity.AppError {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[key.Id]; ok {
+		return entity.NewAppError(fmt.Errorf("key %s already exists", key.Id))
+	}
+
+	k.keys[key.Id] = key
+	return nil
+}
+
+// SetSigningKey selects the key used to sign new tokens. It must have been added with a private key.
+func (k *Keyring) SetSigningKey(id string) *entity.AppError {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, ok := k.keys[id]
+	if !ok {
+		return entity.NewAppError(fmt.Errorf("key %s not found", id))
+	}
+
+	if key.PrivateKey == nil {
+		return entity.NewAppError(fmt.Errorf("key %s has no private key", id))
+	}
+
+	k.signingKeyId = id
+	return nil
+}
+
+// Remove retires a key so that tokens signed with it no longer verify.
+func (k *Keyring) Remove(id string) *entity.AppError {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if id == k.signingKeyId {
+		return entity.NewAppError(fmt.Errorf("key %s is the signing key", id))
+	}
+
+	delete(k.keys, id)
+	return nil
+}
+
+// Sign signs the claims with the signing key and sets the kid header.
+func (k *Keyring) Sign(claims jwt.Claims) (string, *entity.AppError) {
+	k.mu.RLock()
+	key, ok := k.keys[k.signingKeyId]
+	k.mu.RUnlock()
+
+	if !ok {
+		return "", entity.ErrSecretKey
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.Id
+
+	tokenString, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		return "", entity.NewAppError(err)
+	}
+
+	return tokenString, nil
+}
+
+// Keyfunc resolves the verification key for a token from its kid header. The token algorithm
+// has to match the key, so a public key can never be used as an HMAC secret.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	if id == "" {
+		return nil, entity.NewAppError(errors.New("jwt token has no key id"))
+	}
+
+	k.mu.RLock()
+	key, ok := k.keys[id]
+	k.mu.RUnlock()
+
+	if !ok {
+		return nil, entity.NewAppError(fmt.Errorf("unknown key id %s", id))
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, entity.NewAppError(errors.New("unable to determine token algorithm method"))
+	}
+
+	return key.PublicKey, nil
+}
@@ -0,0 +1,130 @@
+package keyring
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/dgrijalva/jwt-go"
+	"math/big"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("unable to generate rsa key: [%s]", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatalf("unable to marshal rsa key: [%s]", err)
+	}
+
+	rsaPem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	t.Run("ParsePEM should load an RSA private key for RS256", func(t *testing.T) {
+		key, err := ParsePEM("rsa", rsaPem)
+		if err != nil {
+			t.Fatalf("unable to parse pem: [%s]", err)
+		}
+
+		if key.Method.Alg() != "RS256" || key.PrivateKey == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("ParsePEM should return an error for data that is not pem encoded", func(t *testing.T) {
+		if _, err := ParsePEM("rsa", []byte("hello")); err == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Sign should set the kid header and verify with the keyring", func(t *testing.T) {
+		rsaSigningKey, err := ParsePEM("rsa", rsaPem)
+		if err != nil {
+			t.Fatalf("unable to parse pem: [%s]", err)
+		}
+
+		edSigningKey, err := GenerateKey("ed25519")
+		if err != nil {
+			t.Fatalf("unable to generate key: [%s]", err)
+		}
+
+		for _, key := range []*Key{rsaSigningKey, edSigningKey} {
+			kr := New()
+			if err := kr.Add(key); err != nil {
+				t.Fatalf("unable to add key: [%s]", err)
+			}
+
+			if err := kr.SetSigningKey(key.Id); err != nil {
+				t.Fatalf("unable to set signing key: [%s]", err)
+			}
+
+			tokenString, signErr := kr.Sign(jwt.StandardClaims{Subject: "munens"})
+			if signErr != nil {
+				t.Fatalf("unable to sign token: [%s]", signErr)
+			}
+
+			token, parseErr := jwt.ParseWithClaims(tokenString, &jwt.StandardClaims{}, kr.Keyfunc)
+			if parseErr != nil || !token.Valid {
+				t.Errorf("%s token did not verify: [%v]", key.Method.Alg(), parseErr)
+			}
+
+			if token.Header["kid"] != key.Id {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("SetSigningKey should return an error for a verification only key", func(t *testing.T) {
+		key, err := NewKey("public", &rsaKey.PublicKey)
+		if err != nil {
+			t.Fatalf("unable to create key: [%s]", err)
+		}
+
+		kr := New()
+		if err := kr.Add(key); err != nil {
+			t.Fatalf("unable to add key: [%s]", err)
+		}
+
+		if err := kr.SetSigningKey("public"); err == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("JWKS should publish the public part of every key", func(t *testing.T) {
+		rsaPublic, _ := NewKey("a-rsa", &rsaKey.PublicKey)
+		edKey, _ := GenerateKey("b-ed25519")
+
+		kr := New()
+		for _, key := range []*Key{edKey, rsaPublic} {
+			if err := kr.Add(key); err != nil {
+				t.Fatalf("unable to add key: [%s]", err)
+			}
+		}
+
+		set := kr.JWKS()
+
+		if len(set.Keys) != 2 {
+			t.FailNow()
+		}
+
+		rsaJwk := set.Keys[0]
+		if rsaJwk.Kid != "a-rsa" || rsaJwk.Kty != "RSA" || rsaJwk.Alg != "RS256" {
+			t.Fail()
+		}
+
+		n, decodeErr := base64.RawURLEncoding.DecodeString(rsaJwk.N)
+		if decodeErr != nil || new(big.Int).SetBytes(n).Cmp(rsaKey.N) != 0 {
+			t.Fail()
+		}
+
+		edJwk := set.Keys[1]
+		if edJwk.Kid != "b-ed25519" || edJwk.Kty != "OKP" || edJwk.Crv != "Ed25519" || edJwk.Alg != "EdDSA" || edJwk.X == "" {
+			t.Fail()
+		}
+	})
+}
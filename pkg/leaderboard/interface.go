@@ -0,0 +1,16 @@
+package leaderboard
+
+import (
+	"quiz-app/pkg/entity"
+	"time"
+)
+
+type Reader interface {
+	FindQuizEntries(quiz_id int64, since time.Time, limit int, user_id int64) ([]*entity.LeaderboardEntry, *entity.AppError)
+	FindGlobalEntries(since time.Time, limit int, user_id int64) ([]*entity.LeaderboardEntry, *entity.AppError)
+}
+
+// Repository interface
+type Repository interface {
+	Reader
+}
@@ -0,0 +1,72 @@
+package leaderboard
+
+import (
+	"database/sql"
+	"fmt"
+	"quiz-app/pkg/entity"
+	"time"
+)
+
+type PGRepository struct {
+	pool *sql.DB
+}
+
+func InitRepo(p *sql.DB) *PGRepository {
+	return &PGRepository{
+		pool: p,
+	}
+}
+
+// bestAttempts selects the best submitted attempt of every user at every quiz since $1: the
+// highest score, then the shortest completion time, then the earliest submission.
+const bestAttempts = "best as (" +
+	"select distinct on (a.user_id, a.quiz_id) a.user_id, a.quiz_id, a.score, " +
+	"extract(epoch from a.submitted_at - a.started_at)::double precision as duration, a.submitted_at " +
+	"from attempts a where a.status='submitted' and a.submitted_at >= $1 %s" +
+	"order by a.user_id, a.quiz_id, a.score desc, duration, a.submitted_at)"
+
+// rankedEntries ranks the rows of totals and keeps the top $2 and the row of user $3.
+const rankedEntries = "ranked as (" +
+	"select t.*, row_number() over (order by t.score desc, t.duration, t.submitted_at, t.user_id) as rank from totals t) " +
+	"select r.rank, r.user_id, u.username, r.score, r.duration, r.submitted_at, r.quizzes " +
+	"from ranked r join users u on u.id = r.user_id where r.rank <= $2 or r.user_id = $3 order by r.rank"
+
+func (r PGRepository) FindQuizEntries(quizId int64, since time.Time, limit int, userId int64) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	query := "with " + fmt.Sprintf(bestAttempts, "and a.quiz_id = $4 ") + ", " +
+		"totals as (select b.user_id, b.score, b.duration, b.submitted_at, 0 as quizzes from best b), " + rankedEntries
+
+	return r.findEntries(query, since, limit, userId, quizId)
+}
+
+func (r PGRepository) FindGlobalEntries(since time.Time, limit int, userId int64) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	query := "with " + fmt.Sprintf(bestAttempts, "") + ", " +
+		"totals as (select b.user_id, sum(b.score) as score, sum(b.duration) as duration, " +
+		"max(b.submitted_at) as submitted_at, count(*) as quizzes from best b group by b.user_id), " + rankedEntries
+
+	return r.findEntries(query, since, limit, userId)
+}
+
+func (r PGRepository) findEntries(query string, args ...interface{}) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	rows, err := r.pool.Query(query, args...)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	entries := make([]*entity.LeaderboardEntry, 0)
+	for rows.Next() {
+		var entry entity.LeaderboardEntry
+		if err := rows.Scan(&entry.Rank, &entry.UserId, &entry.Username, &entry.Score, &entry.DurationSeconds,
+			&entry.SubmittedAt, &entry.Quizzes); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,128 @@
+package leaderboard
+
+import (
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/quiz"
+	"time"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+)
+
+type Service struct {
+	repo    Repository
+	quizzes quiz.Reader
+	now     func() time.Time
+}
+
+func InitService(r Repository, q quiz.Reader) *Service {
+	return &Service{
+		repo:    r,
+		quizzes: q,
+		now:     time.Now,
+	}
+}
+
+// GetQuizLeaderboard ranks the users by their best attempt at the quiz submitted within the
+// window. The entry of the caller is returned even when they are not among the top limit users.
+func (s *Service) GetQuizLeaderboard(quizId int64, window string, limit int, callerId int64) (*entity.Leaderboard, *entity.AppError) {
+
+	since, err := s.windowStart(window)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.quizzes.FindByID(quizId); err != nil {
+		return nil, err
+	}
+
+	limit = normalizeLimit(limit)
+	entries, err := s.repo.FindQuizEntries(quizId, since, limit, callerId)
+	if err != nil {
+		return nil, err
+	}
+
+	board := newLeaderboard(window, since, limit, callerId, entries)
+	board.QuizId = quizId
+	return board, nil
+}
+
+// GetGlobalLeaderboard ranks the users by the sum of their best scores at every quiz, counting
+// the attempts submitted within the window.
+func (s *Service) GetGlobalLeaderboard(window string, limit int, callerId int64) (*entity.Leaderboard, *entity.AppError) {
+
+	since, err := s.windowStart(window)
+	if err != nil {
+		return nil, err
+	}
+
+	limit = normalizeLimit(limit)
+	entries, err := s.repo.FindGlobalEntries(since, limit, callerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLeaderboard(window, since, limit, callerId, entries), nil
+}
+
+// windowStart returns the time from which attempts count in the window. The all-time window,
+// which is the default, starts at the zero time.
+func (s *Service) windowStart(window string) (time.Time, *entity.AppError) {
+	now := s.now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch window {
+	case entity.LeaderboardDaily:
+		return today, nil
+	case entity.LeaderboardWeekly:
+		// weeks start on Monday:
+		daysSinceMonday := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -daysSinceMonday), nil
+	case entity.LeaderboardAllTime, "":
+		return time.Time{}, nil
+	default:
+		return time.Time{}, entity.ErrInvalidWindow
+	}
+}
+
+// newLeaderboard splits the ranked entries into the top limit entries and the entry of the caller.
+func newLeaderboard(window string, since time.Time, limit int, callerId int64, entries []*entity.LeaderboardEntry) *entity.Leaderboard {
+	board := &entity.Leaderboard{
+		Window:  window,
+		Entries: make([]*entity.LeaderboardEntry, 0, len(entries)),
+	}
+
+	if window == "" {
+		board.Window = entity.LeaderboardAllTime
+	}
+
+	if !since.IsZero() {
+		board.Since = &since
+	}
+
+	for _, entry := range entries {
+		if entry.UserId == callerId {
+			board.Me = entry
+		}
+
+		if entry.Rank <= limit {
+			board.Entries = append(board.Entries, entry)
+		}
+	}
+
+	return board
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+
+	if limit > maxLimit {
+		return maxLimit
+	}
+
+	return limit
+}
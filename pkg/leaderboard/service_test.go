@@ -0,0 +1,133 @@
+package leaderboard
+
+import (
+	"go.uber.org/mock/gomock"
+	"quiz-app/pkg/entity"
+	mockLeaderboard "quiz-app/pkg/mocks/leaderboard"
+	mockQuiz "quiz-app/pkg/mocks/quiz"
+	"testing"
+	"time"
+)
+
+func TestGetQuizLeaderboard(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockLeaderboard.NewMockRepository(mockCtrl)
+	mockQuizzes := mockQuiz.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, mockQuizzes)
+
+	// a Wednesday:
+	now := time.Date(2024, time.May, 15, 13, 30, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	mockQuizzes.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7}, nil).AnyTimes()
+
+	t.Run("GetQuizLeaderboard should reject an unknown window", func(t *testing.T) {
+		board, err := service.GetQuizLeaderboard(7, "monthly", 10, 1)
+
+		if board != nil || err != entity.ErrInvalidWindow {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuizLeaderboard should not rank attempts at an unknown quiz", func(t *testing.T) {
+		mockQuizzes.EXPECT().FindByID(int64(8)).Return(nil, entity.ErrEntityNotFound)
+
+		board, err := service.GetQuizLeaderboard(8, entity.LeaderboardDaily, 10, 1)
+
+		if board != nil || err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuizLeaderboard should count attempts since midnight for the daily window", func(t *testing.T) {
+		since := time.Date(2024, time.May, 15, 0, 0, 0, 0, time.UTC)
+		mockRepo.EXPECT().FindQuizEntries(int64(7), since, 10, int64(1)).Return([]*entity.LeaderboardEntry{}, nil)
+
+		board, err := service.GetQuizLeaderboard(7, entity.LeaderboardDaily, 0, 1)
+
+		if err != nil || board.Since == nil || !board.Since.Equal(since) || board.QuizId != 7 {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuizLeaderboard should count attempts since Monday for the weekly window", func(t *testing.T) {
+		since := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+		mockRepo.EXPECT().FindQuizEntries(int64(7), since, 100, int64(1)).Return([]*entity.LeaderboardEntry{}, nil)
+
+		if _, err := service.GetQuizLeaderboard(7, entity.LeaderboardWeekly, 1000, 1); err != nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuizLeaderboard should return the rank of a caller outside the top entries", func(t *testing.T) {
+		mockRepo.EXPECT().FindQuizEntries(int64(7), time.Time{}, 2, int64(1)).Return([]*entity.LeaderboardEntry{
+			{Rank: 1, UserId: 3, Score: 5},
+			{Rank: 2, UserId: 4, Score: 4},
+			{Rank: 9, UserId: 1, Score: 1},
+		}, nil)
+
+		board, err := service.GetQuizLeaderboard(7, "", 2, 1)
+
+		if err != nil || board.Window != entity.LeaderboardAllTime || board.Since != nil {
+			t.FailNow()
+		}
+
+		if len(board.Entries) != 2 || board.Me == nil || board.Me.Rank != 9 {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuizLeaderboard should return the rank of a caller among the top entries", func(t *testing.T) {
+		mockRepo.EXPECT().FindQuizEntries(int64(7), time.Time{}, 2, int64(4)).Return([]*entity.LeaderboardEntry{
+			{Rank: 1, UserId: 3, Score: 5},
+			{Rank: 2, UserId: 4, Score: 4},
+		}, nil)
+
+		board, err := service.GetQuizLeaderboard(7, entity.LeaderboardAllTime, 2, 4)
+
+		if err != nil || len(board.Entries) != 2 || board.Me != board.Entries[1] {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuizLeaderboard should leave out the rank of a caller without attempts", func(t *testing.T) {
+		mockRepo.EXPECT().FindQuizEntries(int64(7), time.Time{}, 10, int64(5)).Return([]*entity.LeaderboardEntry{
+			{Rank: 1, UserId: 3, Score: 5},
+		}, nil)
+
+		board, err := service.GetQuizLeaderboard(7, entity.LeaderboardAllTime, 10, 5)
+
+		if err != nil || len(board.Entries) != 1 || board.Me != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestGetGlobalLeaderboard(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockLeaderboard.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, mockQuiz.NewMockRepository(mockCtrl))
+
+	// a Sunday, which belongs to the week starting on the Monday before:
+	now := time.Date(2024, time.May, 19, 23, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	t.Run("GetGlobalLeaderboard should count attempts since Monday for the weekly window", func(t *testing.T) {
+		since := time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)
+		mockRepo.EXPECT().FindGlobalEntries(since, 10, int64(1)).Return([]*entity.LeaderboardEntry{
+			{Rank: 1, UserId: 1, Score: 12, Quizzes: 3},
+		}, nil)
+
+		board, err := service.GetGlobalLeaderboard(entity.LeaderboardWeekly, 0, 1)
+
+		if err != nil || board.QuizId != 0 || len(board.Entries) != 1 || board.Me == nil || board.Me.Quizzes != 3 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package live
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"log"
+	"quiz-app/pkg/entity"
+	"sync"
+	"time"
+)
+
+const (
+	maxMessageSize = 64 << 10
+	sendBuffer     = 32
+
+	writeWait  = 10 * time.Second
+	closeWait  = time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+)
+
+// client is the connection of a user to a live session. Events are queued on send and written
+// by writeLoop, which is the only writer of the connection besides control messages.
+type client struct {
+	conn     *websocket.Conn
+	userId   int64
+	username string
+	send     chan *entity.LiveEvent
+	done     chan struct{}
+	once     sync.Once
+}
+
+func newClient(conn *websocket.Conn, user *entity.User) *client {
+	return &client{
+		conn:     conn,
+		userId:   user.Id,
+		username: user.Username,
+		send:     make(chan *entity.LiveEvent, sendBuffer),
+		done:     make(chan struct{}),
+	}
+}
+
+// push queues the event without blocking. A client that cannot keep up is disconnected and
+// gets the current state when it connects again.
+func (c *client) push(event *entity.LiveEvent) {
+	select {
+	case <-c.done:
+	case c.send <- event:
+	default:
+		log.Printf("live client of user %d is too slow, closing its connection", c.userId)
+		c.close()
+	}
+}
+
+// replace tells the client that the user connected again elsewhere and closes its connection.
+func (c *client) replace() {
+	c.closeWith(websocket.ClosePolicyViolation, "connected again elsewhere")
+}
+
+// closeWith tells the client why its connection is closed before closing it.
+func (c *client) closeWith(code int, reason string) {
+	message := websocket.FormatCloseMessage(code, reason)
+	if err := c.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(closeWait)); err != nil {
+		log.Println(err)
+	}
+
+	c.close()
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		if err := c.conn.Close(); err != nil {
+			log.Println(err)
+		}
+	})
+}
+
+// writeLoop writes the queued events and pings the client until the client is closed.
+func (c *client) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case event := <-c.send:
+			if err := c.conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+				log.Println(err)
+			}
+
+			if err := c.conn.WriteJSON(event); err != nil {
+				log.Println(err)
+				c.close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				log.Println(err)
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// readLoop passes the commands of the client to handle until the connection closes. A client
+// that neither sends nor answers pings for pongWait is disconnected.
+func (c *client) readLoop(handle func(cmd *entity.LiveCommand)) {
+	extend := func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	}
+	c.conn.SetPongHandler(extend)
+
+	for {
+		if err := extend(""); err != nil {
+			log.Println(err)
+			return
+		}
+
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Println(err)
+			}
+
+			return
+		}
+
+		var cmd entity.LiveCommand
+		if err := json.Unmarshal(message, &cmd); err != nil {
+			c.push(errorEvent(entity.ErrLiveCommand))
+			continue
+		}
+
+		handle(&cmd)
+	}
+}
+
+// reject tells a client why it cannot join before its connection is closed.
+func reject(conn *websocket.Conn, err *entity.AppError) {
+	if err := conn.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if err := conn.WriteJSON(errorEvent(err)); err != nil {
+		log.Println(err)
+		return
+	}
+
+	message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error())
+	if err := conn.WriteMessage(websocket.CloseMessage, message); err != nil {
+		log.Println(err)
+	}
+}
+
+func errorEvent(err *entity.AppError) *entity.LiveEvent {
+	return &entity.LiveEvent{Type: entity.LiveEventError, Error: err.Error()}
+}
@@ -0,0 +1,10 @@
+package live
+
+import (
+	"quiz-app/pkg/entity"
+)
+
+// Authenticator verifies the access token a client sends as the first message of its connection.
+type Authenticator interface {
+	Authenticate(tokenString string) (*entity.JwtClaims, *entity.User, *entity.AppError)
+}
@@ -0,0 +1,249 @@
+package live
+
+import (
+	"crypto/rand"
+	"errors"
+	"github.com/gorilla/websocket"
+	"log"
+	"math/big"
+	mathRand "math/rand"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/quiz"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	codeLength   = 6
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeAttempts = 10
+
+	defaultQuestionTime = 20 * time.Second
+	authTimeout         = 10 * time.Second
+
+	// finished sessions can still be looked at for a while, and no session lives longer than a day:
+	finishedRetention = time.Hour
+	maxSessionAge     = 24 * time.Hour
+)
+
+type Service struct {
+	quizzes      quiz.Reader
+	auth         Authenticator
+	now          func() time.Time
+	newSeed      func() int64
+	questionTime time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func InitService(q quiz.Reader, a Authenticator) *Service {
+	return &Service{
+		quizzes:      q,
+		auth:         a,
+		now:          time.Now,
+		newSeed:      mathRand.Int63,
+		questionTime: defaultQuestionTime,
+		sessions:     make(map[string]*session),
+	}
+}
+
+// CreateSession opens the lobby of a live session of the quiz, run by the host. Questions the
+// quiz draws from the question bank are drawn once, so that every player gets the same ones.
+func (s *Service) CreateSession(quizId int64, hostId int64) (*entity.LiveSession, *entity.AppError) {
+
+	q, err := s.quizzes.FindByID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.findQuestions(q)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return nil, entity.ErrEmptyLiveQuiz
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeExpired()
+
+	code, err := s.newCode()
+	if err != nil {
+		return nil, err
+	}
+
+	sess := newSession(entity.LiveSession{
+		Code:          code,
+		QuizId:        q.Id,
+		Title:         q.Title,
+		HostId:        hostId,
+		State:         entity.LiveLobby,
+		QuestionCount: len(questions),
+		CreatedAt:     s.now(),
+	}, questions, s.now, s.questionTime)
+	s.sessions[code] = sess
+
+	return sess.snapshotInfo(), nil
+}
+
+// GetSession returns the live session with the join code, which is not case-sensitive.
+func (s *Service) GetSession(code string) (*entity.LiveSession, *entity.AppError) {
+	sess := s.findSession(code)
+	if sess == nil {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	return sess.snapshotInfo(), nil
+}
+
+// Serve runs the connection of a client to the live session until it closes. The first message
+// of the client has to authenticate it with an access token. The host of the session runs it and
+// every other user plays. A user that connects again replaces their previous connection and
+// resumes the session where they left it.
+func (s *Service) Serve(code string, conn *websocket.Conn) {
+	defer conn.Close()
+
+	sess := s.findSession(code)
+	if sess == nil {
+		reject(conn, entity.ErrEntityNotFound)
+		return
+	}
+
+	user, err := s.authenticate(conn)
+	if err != nil {
+		log.Println(err)
+		reject(conn, entity.ErrAppToken)
+		return
+	}
+
+	c := newClient(conn, user)
+	go c.writeLoop()
+	defer c.close()
+
+	sess.join(c)
+	defer sess.leave(c)
+
+	c.readLoop(func(cmd *entity.LiveCommand) {
+		sess.handle(c, cmd)
+	})
+}
+
+// Close ends every live session and disconnects its clients, for when the server shuts down.
+// Live sessions only live in memory, so they cannot outlast the server.
+func (s *Service) Close() {
+	s.mu.Lock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for code, sess := range s.sessions {
+		sessions = append(sessions, sess)
+		delete(s.sessions, code)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, sess := range sessions {
+		wg.Add(1)
+		go func(sess *session) {
+			defer wg.Done()
+			sess.close(websocket.CloseGoingAway, "server is shutting down")
+		}(sess)
+	}
+	wg.Wait()
+}
+
+// authenticate reads the auth command the client has to send first and returns its user.
+func (s *Service) authenticate(conn *websocket.Conn) (*entity.User, *entity.AppError) {
+	conn.SetReadLimit(maxMessageSize)
+	if err := conn.SetReadDeadline(time.Now().Add(authTimeout)); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	var cmd entity.LiveCommand
+	if err := conn.ReadJSON(&cmd); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if cmd.Type != entity.LiveAuth {
+		return nil, entity.ErrAppToken
+	}
+
+	_, user, err := s.auth.Authenticate(cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// findQuestions returns the questions of the quiz followed by the questions its draw rules pick
+// from the question bank of its owner.
+func (s *Service) findQuestions(q *entity.Quiz) ([]*entity.Question, *entity.AppError) {
+	questions, err := s.quizzes.FindQuestionsByQuizID(q.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.quizzes.FindDrawRules(q.Id)
+	if err != nil || len(rules) == 0 {
+		return questions, err
+	}
+
+	bank, err := s.quizzes.FindBankQuestions(&entity.BankQuery{OwnerId: q.OwnerId})
+	if err != nil {
+		return nil, err
+	}
+
+	drawn, err := quiz.Draw(rules, bank, s.newSeed())
+	if err != nil {
+		return nil, err
+	}
+
+	return append(questions, drawn...), nil
+}
+
+func (s *Service) findSession(code string) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sessions[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// removeExpired removes the sessions that finished more than finishedRetention ago and the ones
+// older than maxSessionAge, and closes them in the background. The caller has to hold s.mu.
+func (s *Service) removeExpired() {
+	now := s.now()
+
+	for code, sess := range s.sessions {
+		if sess.expired(now) {
+			go sess.close(websocket.CloseGoingAway, "session expired")
+			delete(s.sessions, code)
+		}
+	}
+}
+
+// newCode returns a random join code that no open session uses. Letters and digits that are
+// easily confused, such as O and 0, are left out. The caller has to hold s.mu.
+func (s *Service) newCode() (string, *entity.AppError) {
+	max := big.NewInt(int64(len(codeAlphabet)))
+
+	for attempt := 0; attempt < codeAttempts; attempt++ {
+		var code strings.Builder
+		for i := 0; i < codeLength; i++ {
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return "", entity.NewAppError(err)
+			}
+			code.WriteByte(codeAlphabet[n.Int64()])
+		}
+
+		if _, ok := s.sessions[code.String()]; !ok {
+			return code.String(), nil
+		}
+	}
+
+	return "", entity.NewAppError(errors.New("unable to find an unused join code"))
+}
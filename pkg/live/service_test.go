@@ -0,0 +1,356 @@
+package live
+
+import (
+	"github.com/gorilla/websocket"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"quiz-app/pkg/entity"
+	mockLive "quiz-app/pkg/mocks/live"
+	mockQuiz "quiz-app/pkg/mocks/quiz"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestQuestions() []*entity.Question {
+	return []*entity.Question{
+		{
+			Id: 11, QuizId: 7, Type: entity.QuestionSingleChoice, Text: "What is the capital of France?",
+			Options: []*entity.AnswerOption{{Id: 1, Text: "Paris", IsCorrect: true}, {Id: 2, Text: "Lyon"}},
+		},
+		{
+			Id: 12, QuizId: 7, Type: entity.QuestionSingleChoice, Text: "What is the capital of Italy?",
+			Options: []*entity.AnswerOption{{Id: 3, Text: "Milan"}, {Id: 4, Text: "Rome", IsCorrect: true}},
+		},
+	}
+}
+
+// newTestServer serves the live sessions of the service at /live/{code} for in-process clients.
+func newTestServer(service *Service) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		service.Serve(strings.TrimPrefix(r.URL.Path, "/live/"), conn)
+	}))
+}
+
+// connect joins the session as the user of the token and returns the connection with the state
+// event it got first.
+func connect(t *testing.T, server *httptest.Server, code string, token string) (*websocket.Conn, *entity.LiveEvent) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/live/" + code
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("unable to connect: [%s]", err)
+	}
+
+	send(t, conn, &entity.LiveCommand{Type: entity.LiveAuth, Token: token})
+	return conn, expect(t, conn, entity.LiveEventState)
+}
+
+func send(t *testing.T, conn *websocket.Conn, cmd *entity.LiveCommand) {
+	if err := conn.WriteJSON(cmd); err != nil {
+		t.Fatalf("unable to send %s: [%s]", cmd.Type, err)
+	}
+}
+
+// expect reads events until one of the type arrives, skipping the others, such as players events.
+func expect(t *testing.T, conn *websocket.Conn, eventType string) *entity.LiveEvent {
+	if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		var event entity.LiveEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			t.Fatalf("expected a %s event: [%s]", eventType, err)
+		}
+
+		if event.Type == eventType {
+			return &event
+		}
+
+		if event.Type == entity.LiveEventError {
+			t.Fatalf("expected a %s event but got the error %q", eventType, event.Error)
+		}
+	}
+}
+
+func answer(t *testing.T, conn *websocket.Conn, questionId int64, optionId int64) {
+	send(t, conn, &entity.LiveCommand{Type: entity.LiveAnswer, QuestionId: questionId, Response: &entity.Response{OptionIds: []int64{optionId}}})
+	expect(t, conn, entity.LiveEventAnswered)
+}
+
+func TestLiveSession(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockQuizzes := mockQuiz.NewMockReader(mockCtrl)
+	mockAuth := mockLive.NewMockAuthenticator(mockCtrl)
+	service := InitService(mockQuizzes, mockAuth)
+
+	server := newTestServer(service)
+	defer server.Close()
+
+	mockQuizzes.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7, OwnerId: 1, Title: "Capitals"}, nil).AnyTimes()
+	mockQuizzes.EXPECT().FindQuestionsByQuizID(int64(7)).DoAndReturn(func(int64) ([]*entity.Question, *entity.AppError) {
+		return newTestQuestions(), nil
+	}).AnyTimes()
+	mockQuizzes.EXPECT().FindDrawRules(int64(7)).Return([]*entity.DrawRule{}, nil).AnyTimes()
+
+	mockAuth.EXPECT().Authenticate("host").Return(nil, &entity.User{Id: 1, Username: "host"}, nil).AnyTimes()
+	mockAuth.EXPECT().Authenticate("alice").Return(nil, &entity.User{Id: 2, Username: "alice"}, nil).AnyTimes()
+	mockAuth.EXPECT().Authenticate("bob").Return(nil, &entity.User{Id: 3, Username: "bob"}, nil).AnyTimes()
+	mockAuth.EXPECT().Authenticate("revoked").Return(nil, nil, entity.ErrTokenRevoked).AnyTimes()
+
+	t.Run("CreateSession should fail for a quiz without questions", func(t *testing.T) {
+		mockQuizzes.EXPECT().FindByID(int64(8)).Return(&entity.Quiz{Id: 8, OwnerId: 1}, nil)
+		mockQuizzes.EXPECT().FindQuestionsByQuizID(int64(8)).Return([]*entity.Question{}, nil)
+		mockQuizzes.EXPECT().FindDrawRules(int64(8)).Return([]*entity.DrawRule{}, nil)
+
+		session, err := service.CreateSession(8, 1)
+
+		if session != nil || err != entity.ErrEmptyLiveQuiz {
+			t.Fail()
+		}
+	})
+
+	t.Run("CreateSession should open a lobby that can be found by its join code", func(t *testing.T) {
+		session, err := service.CreateSession(7, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(session.Code) != codeLength || session.State != entity.LiveLobby || session.QuestionCount != 2 || session.HostId != 1 {
+			t.Fail()
+		}
+
+		found, err := service.GetSession(strings.ToLower(session.Code))
+		if err != nil || found.Code != session.Code {
+			t.Fail()
+		}
+
+		if _, err := service.GetSession("NOPE42"); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("Serve should reject a connection with an invalid token", func(t *testing.T) {
+		session, _ := service.CreateSession(7, 1)
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/live/" + session.Code
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		send(t, conn, &entity.LiveCommand{Type: entity.LiveAuth, Token: "revoked"})
+
+		var event entity.LiveEvent
+		if err := conn.ReadJSON(&event); err != nil || event.Type != entity.LiveEventError || event.Error != entity.ErrAppToken.Error() {
+			t.Fail()
+		}
+
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Serve should only let the host run the session", func(t *testing.T) {
+		session, _ := service.CreateSession(7, 1)
+
+		alice, _ := connect(t, server, session.Code, "alice")
+		defer alice.Close()
+
+		send(t, alice, &entity.LiveCommand{Type: entity.LiveStart})
+
+		if event := expect(t, alice, entity.LiveEventError); event.Error != entity.ErrNotLiveHost.Error() {
+			t.Fail()
+		}
+	})
+
+	t.Run("Serve should push every question to everyone and rank the players by correctness and speed", func(t *testing.T) {
+		session, _ := service.CreateSession(7, 1)
+
+		host, _ := connect(t, server, session.Code, "host")
+		defer host.Close()
+		alice, _ := connect(t, server, session.Code, "alice")
+		defer alice.Close()
+		bob, _ := connect(t, server, session.Code, "bob")
+		defer bob.Close()
+
+		send(t, host, &entity.LiveCommand{Type: entity.LiveStart})
+
+		hostQuestion := expect(t, host, entity.LiveEventQuestion)
+		aliceQuestion := expect(t, alice, entity.LiveEventQuestion)
+		bobQuestion := expect(t, bob, entity.LiveEventQuestion)
+
+		if aliceQuestion.Question.Id != 11 || !aliceQuestion.Deadline.Equal(*bobQuestion.Deadline) || !hostQuestion.Deadline.Equal(*bobQuestion.Deadline) {
+			t.Fatal("the question was not pushed to everyone at once")
+		}
+
+		for _, option := range aliceQuestion.Question.Options {
+			if option.IsCorrect {
+				t.Fatal("the question gave its answer away")
+			}
+		}
+
+		answer(t, alice, 11, 1)
+		answer(t, bob, 11, 2)
+
+		aliceResults := expect(t, alice, entity.LiveEventResults)
+		bobResults := expect(t, bob, entity.LiveEventResults)
+		hostResults := expect(t, host, entity.LiveEventResults)
+
+		if !aliceResults.Result.Result.IsCorrect || aliceResults.Result.Points <= maxPoints/2 || bobResults.Result.Points != 0 {
+			t.Error("results", aliceResults.Result, bobResults.Result)
+		}
+
+		if standings := hostResults.Standings; len(standings) != 2 || standings[0].UserId != 2 || standings[0].Rank != 1 || standings[1].Rank != 2 {
+			t.Error("standings", standings)
+		}
+
+		if !hostResults.Question.Options[0].IsCorrect {
+			t.Error("the results did not reveal the answer")
+		}
+
+		// the host closes the second round before everyone answered:
+		send(t, host, &entity.LiveCommand{Type: entity.LiveNext})
+		expect(t, bob, entity.LiveEventQuestion)
+		answer(t, bob, 12, 4)
+
+		send(t, host, &entity.LiveCommand{Type: entity.LiveNext})
+		if event := expect(t, alice, entity.LiveEventResults); event.Result.Answered || event.Result.Points != 0 {
+			t.Error("alice did not answer", event.Result)
+		}
+
+		send(t, host, &entity.LiveCommand{Type: entity.LiveNext})
+		finished := expect(t, bob, entity.LiveEventFinished)
+
+		if finished.Session.State != entity.LiveFinished || len(finished.Standings) != 2 || finished.Standings[0].Score == 0 {
+			t.Error("finished", finished.Session, finished.Standings)
+		}
+	})
+
+	t.Run("Serve should end a round when its time is up", func(t *testing.T) {
+		service.questionTime = 50 * time.Millisecond
+		defer func() { service.questionTime = defaultQuestionTime }()
+		session, _ := service.CreateSession(7, 1)
+
+		host, _ := connect(t, server, session.Code, "host")
+		defer host.Close()
+		alice, _ := connect(t, server, session.Code, "alice")
+		defer alice.Close()
+
+		send(t, host, &entity.LiveCommand{Type: entity.LiveStart})
+		expect(t, alice, entity.LiveEventQuestion)
+
+		if event := expect(t, alice, entity.LiveEventResults); event.Result.Answered || event.Session.State != entity.LiveResults {
+			t.Fail()
+		}
+
+		send(t, alice, &entity.LiveCommand{Type: entity.LiveAnswer, QuestionId: 11, Response: &entity.Response{OptionIds: []int64{1}}})
+		if event := expect(t, alice, entity.LiveEventError); event.Error != entity.ErrLiveCommand.Error() {
+			t.Fail()
+		}
+	})
+
+	t.Run("Serve should resume the state of a player that reconnects", func(t *testing.T) {
+		session, _ := service.CreateSession(7, 1)
+
+		host, _ := connect(t, server, session.Code, "host")
+		defer host.Close()
+		alice, _ := connect(t, server, session.Code, "alice")
+		defer alice.Close()
+		bob, _ := connect(t, server, session.Code, "bob")
+		defer bob.Close()
+
+		send(t, host, &entity.LiveCommand{Type: entity.LiveStart})
+		expect(t, alice, entity.LiveEventQuestion)
+		answer(t, alice, 11, 1)
+
+		again, state := connect(t, server, session.Code, "alice")
+		defer again.Close()
+
+		if state.Session.State != entity.LiveQuestion || state.Session.Round != 1 || state.Question.Id != 11 || state.Deadline == nil {
+			t.Error("state", state.Session, state.Question)
+		}
+
+		if state.Result == nil || !state.Result.Answered || state.Result.Result != nil {
+			t.Error("result", state.Result)
+		}
+
+		// the previous connection is closed:
+		for {
+			if _, _, err := alice.ReadMessage(); err != nil {
+				break
+			}
+		}
+
+		// the answer counts, so that the round ends with the answer of bob:
+		send(t, again, &entity.LiveCommand{Type: entity.LiveAnswer, QuestionId: 11, Response: &entity.Response{OptionIds: []int64{1}}})
+		if event := expect(t, again, entity.LiveEventError); event.Error != entity.ErrAlreadyAnswered.Error() {
+			t.Fail()
+		}
+
+		answer(t, bob, 11, 1)
+
+		if event := expect(t, again, entity.LiveEventResults); !event.Result.Result.IsCorrect || event.Standings[0].UserId != 2 {
+			t.Error("results", event.Result, event.Standings)
+		}
+	})
+
+	t.Run("Close should end every session and tell its clients the server is going away", func(t *testing.T) {
+		session, _ := service.CreateSession(7, 1)
+
+		host, _ := connect(t, server, session.Code, "host")
+		defer host.Close()
+
+		service.Close()
+
+		for {
+			if _, _, err := host.ReadMessage(); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+					t.Error(err)
+				}
+				break
+			}
+		}
+
+		if _, err := service.GetSession(session.Code); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+}
+
+func TestPoints(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		score    float64
+		elapsed  time.Duration
+		expected int
+	}{
+		{name: "points should give all points for a correct answer at once", score: 1, elapsed: 0, expected: 1000},
+		{name: "points should give half the points for a correct answer at the deadline", score: 1, elapsed: 10 * time.Second, expected: 500},
+		{name: "points should give the share of a partially correct answer", score: 0.5, elapsed: 5 * time.Second, expected: 375},
+		{name: "points should give nothing for a wrong answer", score: 0, elapsed: time.Second, expected: 0},
+		{name: "points should not go below half the points for a late answer", score: 1, elapsed: time.Minute, expected: 500},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := points(test.score, test.elapsed, 10*time.Second); actual != test.expected {
+				t.Errorf("expected %d but got %d", test.expected, actual)
+			}
+		})
+	}
+}
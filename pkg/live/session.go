@@ -0,0 +1,434 @@
+package live
+
+import (
+	"math"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/grader"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxPoints is what a correct answer given the moment its question opens is worth. Half of it
+// is lost over the time the question is open.
+const maxPoints = 1000
+
+// player is a user playing a live session. client is nil while they are disconnected, round is
+// their result for the question of the current round.
+type player struct {
+	userId   int64
+	username string
+	score    int
+	client   *client
+	round    *entity.LiveRoundResult
+}
+
+// session is the state of a live session. Every change happens under mu, and events are
+// pushed to the clients before it is released, so that every client sees them in order.
+type session struct {
+	mu           sync.Mutex
+	info         entity.LiveSession
+	questions    []*entity.Question
+	now          func() time.Time
+	questionTime time.Duration
+
+	host       *client
+	players    map[int64]*player
+	openedAt   time.Time
+	deadline   time.Time
+	timer      *time.Timer
+	finishedAt time.Time
+}
+
+func newSession(info entity.LiveSession, questions []*entity.Question, now func() time.Time, questionTime time.Duration) *session {
+	return &session{
+		info:         info,
+		questions:    questions,
+		now:          now,
+		questionTime: questionTime,
+		players:      make(map[int64]*player),
+	}
+}
+
+func (s *session) snapshotInfo() *entity.LiveSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.info
+	return &info
+}
+
+// join connects the client as the host or as a player and sends it the current state. A
+// previous connection of the same user is replaced, which may block on a slow network and is
+// therefore closed in the background.
+func (s *session) join(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c.userId == s.info.HostId {
+		if s.host != nil {
+			go s.host.replace()
+		}
+		s.host = c
+		c.push(s.stateEvent(nil))
+		return
+	}
+
+	p, ok := s.players[c.userId]
+	if !ok {
+		p = &player{userId: c.userId, username: c.username}
+		if s.info.State == entity.LiveQuestion {
+			p.round = &entity.LiveRoundResult{QuestionId: s.currentQuestion().Id}
+		}
+		s.players[c.userId] = p
+	}
+
+	if p.client != nil {
+		go p.client.replace()
+	}
+	p.client = c
+
+	c.push(s.stateEvent(p))
+	s.broadcast(&entity.LiveEvent{Type: entity.LiveEventPlayers, Standings: s.standings()})
+}
+
+// leave disconnects the client unless it has already been replaced. The player keeps their
+// score, and a round that only waited for them ends.
+func (s *session) leave(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.host == c {
+		s.host = nil
+		return
+	}
+
+	p, ok := s.players[c.userId]
+	if !ok || p.client != c {
+		return
+	}
+
+	p.client = nil
+	s.broadcast(&entity.LiveEvent{Type: entity.LiveEventPlayers, Standings: s.standings()})
+
+	if s.info.State == entity.LiveQuestion && s.allAnswered() {
+		s.endRound()
+	}
+}
+
+// handle runs a command of the client. The host starts the session, moves on with next, which
+// also ends an open round early, and may end the session at any time. Players answer.
+func (s *session) handle(c *client, cmd *entity.LiveCommand) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	isHost := c == s.host
+	switch cmd.Type {
+	case entity.LiveStart, entity.LiveNext, entity.LiveEnd:
+		if !isHost {
+			c.push(errorEvent(entity.ErrNotLiveHost))
+			return
+		}
+	case entity.LiveAnswer:
+		if isHost {
+			c.push(errorEvent(entity.ErrLiveCommand))
+			return
+		}
+	}
+
+	switch {
+	case cmd.Type == entity.LiveStart && s.info.State == entity.LiveLobby:
+		s.openRound()
+	case cmd.Type == entity.LiveNext && s.info.State == entity.LiveQuestion:
+		s.endRound()
+	case cmd.Type == entity.LiveNext && s.info.State == entity.LiveResults:
+		if s.info.Round < len(s.questions) {
+			s.openRound()
+		} else {
+			s.finish()
+		}
+	case cmd.Type == entity.LiveEnd && s.info.State != entity.LiveFinished:
+		s.finish()
+	case cmd.Type == entity.LiveAnswer:
+		s.answer(c, cmd)
+	default:
+		c.push(errorEvent(entity.ErrLiveCommand))
+	}
+}
+
+// answer grades the answer of a player to the open question and scores it by how quickly it came.
+func (s *session) answer(c *client, cmd *entity.LiveCommand) {
+	p, ok := s.players[c.userId]
+	if !ok || p.client != c {
+		return
+	}
+
+	if s.info.State != entity.LiveQuestion || s.currentQuestion().Id != cmd.QuestionId {
+		c.push(errorEvent(entity.ErrLiveCommand))
+		return
+	}
+
+	if p.round.Answered {
+		c.push(errorEvent(entity.ErrAlreadyAnswered))
+		return
+	}
+
+	now := s.now()
+	if now.After(s.deadline) {
+		c.push(errorEvent(entity.ErrQuestionExpired))
+		return
+	}
+
+	if cmd.Response == nil {
+		c.push(errorEvent(entity.ErrInvalidAnswer))
+		return
+	}
+
+	question := s.currentQuestion()
+	result, err := grader.Grade(question, cmd.Response)
+	if err != nil {
+		c.push(errorEvent(err))
+		return
+	}
+
+	p.round = &entity.LiveRoundResult{
+		QuestionId: question.Id,
+		Answered:   true,
+		Result:     result,
+		Points:     points(result.Score, now.Sub(s.openedAt), s.deadline.Sub(s.openedAt)),
+	}
+	p.score += p.round.Points
+
+	// the result is revealed with the results of the round:
+	c.push(&entity.LiveEvent{Type: entity.LiveEventAnswered, Result: &entity.LiveRoundResult{QuestionId: question.Id, Answered: true}})
+
+	if s.allAnswered() {
+		s.endRound()
+	}
+}
+
+// openRound pushes the next question to every client at once and ends the round when its time is up.
+func (s *session) openRound() {
+	s.info.State = entity.LiveQuestion
+	s.info.Round++
+
+	question := s.currentQuestion()
+	limit := s.questionTime
+	if question.TimeLimitSeconds > 0 {
+		limit = time.Duration(question.TimeLimitSeconds) * time.Second
+	}
+
+	s.openedAt = s.now()
+	s.deadline = s.openedAt.Add(limit)
+	for _, p := range s.players {
+		p.round = &entity.LiveRoundResult{QuestionId: question.Id}
+	}
+
+	round := s.info.Round
+	s.timer = time.AfterFunc(limit, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.info.State == entity.LiveQuestion && s.info.Round == round {
+			s.endRound()
+		}
+	})
+
+	s.broadcast(s.questionEvent(entity.LiveEventQuestion))
+}
+
+// endRound closes the open question and sends everyone the answers and the standings, and every
+// player their result.
+func (s *session) endRound() {
+	s.stopTimer()
+	s.info.State = entity.LiveResults
+
+	info := s.info
+	question := s.currentQuestion()
+	standings := s.standings()
+
+	if s.host != nil {
+		s.host.push(&entity.LiveEvent{Type: entity.LiveEventResults, Session: &info, Question: question, Standings: standings})
+	}
+
+	for _, p := range s.players {
+		if p.client != nil {
+			p.client.push(&entity.LiveEvent{Type: entity.LiveEventResults, Session: &info, Question: question, Standings: standings, Result: p.round})
+		}
+	}
+}
+
+func (s *session) finish() {
+	s.stopTimer()
+	s.info.State = entity.LiveFinished
+	s.finishedAt = s.now()
+
+	info := s.info
+	s.broadcast(&entity.LiveEvent{Type: entity.LiveEventFinished, Session: &info, Standings: s.standings()})
+}
+
+// stateEvent is the snapshot a client gets when it connects: the session, the standings and,
+// during a round, its question and the result of the player.
+func (s *session) stateEvent(p *player) *entity.LiveEvent {
+	event := &entity.LiveEvent{Type: entity.LiveEventState, Standings: s.standings()}
+
+	switch s.info.State {
+	case entity.LiveQuestion:
+		event = s.questionEvent(entity.LiveEventState)
+		event.Standings = s.standings()
+		if p != nil && p.round.Answered {
+			event.Result = &entity.LiveRoundResult{QuestionId: p.round.QuestionId, Answered: true}
+		}
+	case entity.LiveResults:
+		event.Question = s.currentQuestion()
+		if p != nil {
+			event.Result = p.round
+		}
+	}
+
+	info := s.info
+	event.Session = &info
+	return event
+}
+
+func (s *session) questionEvent(eventType string) *entity.LiveEvent {
+	info := s.info
+	deadline := s.deadline
+	remaining := entity.RemainingSeconds(deadline, s.now())
+
+	return &entity.LiveEvent{
+		Type:             eventType,
+		Session:          &info,
+		Question:         s.currentQuestion().WithoutAnswers(),
+		Deadline:         &deadline,
+		RemainingSeconds: &remaining,
+	}
+}
+
+// standings ranks the players by their score. Players with equal scores share their rank and
+// are listed by username.
+func (s *session) standings() []*entity.LiveStanding {
+	standings := make([]*entity.LiveStanding, 0, len(s.players))
+	for _, p := range s.players {
+		standings = append(standings, &entity.LiveStanding{UserId: p.userId, Username: p.username, Score: p.score, Connected: p.client != nil})
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		if standings[i].Username != standings[j].Username {
+			return standings[i].Username < standings[j].Username
+		}
+
+		return standings[i].UserId < standings[j].UserId
+	})
+
+	for i, standing := range standings {
+		standing.Rank = i + 1
+		if i > 0 && standing.Score == standings[i-1].Score {
+			standing.Rank = standings[i-1].Rank
+		}
+	}
+
+	return standings
+}
+
+// allAnswered reports whether every connected player answered the open question.
+func (s *session) allAnswered() bool {
+	for _, p := range s.players {
+		if p.client != nil && !p.round.Answered {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *session) currentQuestion() *entity.Question {
+	if s.info.Round == 0 {
+		return nil
+	}
+
+	return s.questions[s.info.Round-1]
+}
+
+func (s *session) broadcast(event *entity.LiveEvent) {
+	if s.host != nil {
+		s.host.push(event)
+	}
+
+	for _, p := range s.players {
+		if p.client != nil {
+			p.client.push(event)
+		}
+	}
+}
+
+func (s *session) stopTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+}
+
+// expired reports whether the session finished more than finishedRetention ago or is older
+// than maxSessionAge.
+func (s *session) expired(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.info.State == entity.LiveFinished && now.Sub(s.finishedAt) > finishedRetention {
+		return true
+	}
+
+	return now.Sub(s.info.CreatedAt) > maxSessionAge
+}
+
+// close disconnects every client of a session that is removed, with the close code and reason.
+// The clients are told at the same time and without holding s.mu, since every one of them may
+// take up to closeWait on a slow network.
+func (s *session) close(code int, reason string) {
+	s.mu.Lock()
+	s.stopTimer()
+	clients := make([]*client, 0, len(s.players)+1)
+	if s.host != nil {
+		clients = append(clients, s.host)
+	}
+
+	for _, p := range s.players {
+		if p.client != nil {
+			clients = append(clients, p.client)
+		}
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *client) {
+			defer wg.Done()
+			c.closeWith(code, reason)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// points rewards the share of a correct answer given by score with up to maxPoints, of which
+// half are lost over the time limit of the question.
+func points(score float64, elapsed time.Duration, limit time.Duration) int {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	if elapsed > limit {
+		elapsed = limit
+	}
+
+	speed := 1.0
+	if limit > 0 {
+		speed -= float64(elapsed) / float64(limit) / 2
+	}
+
+	return int(math.Round(maxPoints * score * speed))
+}
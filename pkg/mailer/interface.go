@@ -0,0 +1,14 @@
+package mailer
+
+import "quiz-app/pkg/entity"
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers plain text messages to a single recipient.
+type Mailer interface {
+	Send(msg *Message) *entity.AppError
+}
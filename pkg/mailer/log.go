@@ -0,0 +1,33 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"quiz-app/pkg/entity"
+	"sync"
+	"time"
+)
+
+// LogMailer writes messages to w instead of delivering them, for local development and tests.
+type LogMailer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func InitLogMailer(w io.Writer) *LogMailer {
+	return &LogMailer{
+		w: w,
+	}
+}
+
+func (m *LogMailer) Send(msg *Message) *entity.AppError {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := fmt.Fprintf(m.w, "--- %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().UTC().Format(time.RFC3339), msg.To, msg.Subject, msg.Body)
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package access_control
+
+import (
+	"context"
+	"quiz-app/pkg/entity"
+)
+
+type contextKey int
+
+const (
+	claimsContextKey contextKey = iota
+	userContextKey
+)
+
+// NewContext returns a copy of ctx carrying the verified token claims and the calling user.
+func NewContext(ctx context.Context, claims *entity.JwtClaims, user *entity.User) context.Context {
+	ctx = context.WithValue(ctx, claimsContextKey, claims)
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// ClaimsFromContext returns the token claims stored by the access control middleware.
+func ClaimsFromContext(ctx context.Context) (*entity.JwtClaims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*entity.JwtClaims)
+	return claims, ok && claims != nil
+}
+
+// UserFromContext returns the calling user stored by the access control middleware.
+func UserFromContext(ctx context.Context) (*entity.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*entity.User)
+	return user, ok && user != nil
+}
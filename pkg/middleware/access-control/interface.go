@@ -1,6 +1,9 @@
 package access_control
 
-import "quiz-app/pkg/entity"
+import (
+	"quiz-app/pkg/entity"
+	"time"
+)
 
 type reader interface {
 	FindById(id int64) (*entity.User, *entity.AppError)
@@ -13,3 +16,10 @@ type Repository interface {
 	reader
 	writer
 }
+
+// RevocationStore keeps the ids (jti) of access tokens that were revoked before they expired.
+// Entries only need to be kept until expiresAt, after which the token is rejected anyway.
+type RevocationStore interface {
+	Revoke(jti string, expiresAt time.Time) *entity.AppError
+	IsRevoked(jti string) (bool, *entity.AppError)
+}
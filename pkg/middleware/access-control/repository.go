@@ -20,7 +20,7 @@ func (r *Repo) FindById(id int64) (*entity.User, *entity.AppError) {
 
 	var userName string
 	var createdAt time.Time
-	var lastLoginAt time.Time
+	var lastLoginAt sql.NullTime
 
 	queryStmt := "select id, username, created_at, last_login_at from users where id=$1"
 
@@ -34,10 +34,43 @@ func (r *Repo) FindById(id int64) (*entity.User, *entity.AppError) {
 		return nil, entity.NewAppError(err)
 	}
 
+	roles, appErr := r.findRoles(id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
 	return &entity.User{
 		Id:          id,
 		Username:    userName,
+		Roles:       roles,
 		CreatedAt:   createdAt,
-		LastLoginAt: lastLoginAt,
+		LastLoginAt: lastLoginAt.Time,
 	}, nil
 }
+
+func (r *Repo) findRoles(id int64) ([]string, *entity.AppError) {
+
+	queryStmt := "select role from user_roles where user_id=$1 order by role"
+
+	rows, err := r.pool.Query(queryStmt, id)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	roles := make([]string, 0)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return roles, nil
+}
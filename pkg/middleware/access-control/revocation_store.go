@@ -0,0 +1,81 @@
+package access_control
+
+import (
+	"database/sql"
+	"quiz-app/pkg/entity"
+	"sync"
+	"time"
+)
+
+type PGRevocationStore struct {
+	pool *sql.DB
+}
+
+func InitPGRevocationStore(p *sql.DB) *PGRevocationStore {
+	return &PGRevocationStore{
+		pool: p,
+	}
+}
+
+func (s *PGRevocationStore) Revoke(jti string, expiresAt time.Time) *entity.AppError {
+
+	// drop entries whose tokens have expired on their own:
+	if _, err := s.pool.Exec("delete from revoked_tokens where expires_at < $1", time.Now().UTC()); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	queryStmt := "insert into revoked_tokens (jti, expires_at, revoked_at) values ($1, $2, $3) on conflict (jti) do nothing"
+
+	if _, err := s.pool.Exec(queryStmt, jti, expiresAt.UTC(), time.Now().UTC()); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+func (s *PGRevocationStore) IsRevoked(jti string) (bool, *entity.AppError) {
+	var isRevoked bool
+
+	queryStmt := "select exists(select 1 from revoked_tokens where jti=$1)"
+
+	if err := s.pool.QueryRow(queryStmt, jti).Scan(&isRevoked); err != nil {
+		return false, entity.NewAppError(err)
+	}
+
+	return isRevoked, nil
+}
+
+// MemoryRevocationStore is a RevocationStore for tests and single instance deployments.
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+}
+
+func InitMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		revoked: make(map[string]time.Time),
+	}
+}
+
+func (s *MemoryRevocationStore) Revoke(jti string, expiresAt time.Time) *entity.AppError {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, exp := range s.revoked {
+		if exp.Before(now) {
+			delete(s.revoked, id)
+		}
+	}
+
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(jti string) (bool, *entity.AppError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, isRevoked := s.revoked[jti]
+	return isRevoked, nil
+}
@@ -5,23 +5,31 @@ import (
 	"github.com/dgrijalva/jwt-go"
 	"log"
 	"net/http"
-	"os"
 	"quiz-app/pkg/entity"
+	"quiz-app/pkg/keyring"
+	"time"
 )
 
 type Service struct {
-	repo Repository
+	repo            Repository
+	revocationStore RevocationStore
+	keyring         *keyring.Keyring
 }
 
-func InitService(r Repository) *Service {
+func InitService(r Repository, rs RevocationStore, kr *keyring.Keyring) *Service {
 	return &Service{
-		repo: r,
+		repo:            r,
+		revocationStore: rs,
+		keyring:         kr,
 	}
 }
 
+// IsUserAuthenticated verifies the request token and stores its claims and the calling user in
+// the request context, see ClaimsFromContext and UserFromContext.
 func (s *Service) IsUserAuthenticated(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if err := s.isUserAuthenticated(w, r); err != nil {
+		r, err := s.isUserAuthenticated(w, r)
+		if err != nil {
 			log.Println(err.Error())
 			return
 		}
@@ -30,6 +38,107 @@ func (s *Service) IsUserAuthenticated(next http.Handler) http.Handler {
 	})
 }
 
+// RequireRole only lets requests through from users with at least one of the roles. The roles
+// are the ones stored for the user, so that revoking a role takes effect before the token expires.
+func (s *Service) RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return s.requireUser(func(user *entity.User) bool {
+		return entity.HasRole(user.Roles, roles...)
+	})
+}
+
+// RequirePermission only lets requests through from users whose stored roles grant all of the
+// permissions.
+func (s *Service) RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return s.requireUser(func(user *entity.User) bool {
+		for _, permission := range permissions {
+			if !entity.HasPermission(user.Roles, permission) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+// RequireOwnership only lets requests through from the user that owns the requested resource.
+// ownerOf returns the id of that owner, ErrEntityNotFound answers with 404.
+func (s *Service) RequireOwnership(ownerOf func(r *http.Request) (int64, *entity.AppError)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, err := s.isResourceOwner(w, r, ownerOf)
+			if err != nil {
+				log.Println(err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *Service) requireUser(isAllowed func(user *entity.User) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, err := s.isUserAuthorized(w, r, isAllowed)
+			if err != nil {
+				log.Println(err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AllowTicket lets requests authenticate with a ticket from IssueTicket in the ticket query
+// parameter instead of the Authorization header. audienceOf returns the audience the ticket has
+// to be issued for. Requests with a valid ticket go on as if they carried the access token.
+func (s *Service) AllowTicket(audienceOf func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ticket := r.URL.Query().Get("ticket")
+			if ticket == "" || r.Header.Get("Authorization") != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, err := s.parseToken(ticket, audienceOf(r))
+			if err != nil {
+				log.Println(err.Error())
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			claims := token.Claims.(*entity.JwtClaims)
+			user, err := s.findClaimsUser(w, claims)
+			if err != nil {
+				log.Println(err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), claims, user)))
+		})
+	}
+}
+
+// IssueTicket returns a ticket for requests to the audience on behalf of the access token with
+// the claims, see AllowTicket. A ticket only works for its audience, but as often as needed until
+// its access token expires, since clients such as EventSource reconnect with the same url. It is
+// revoked together with the access token.
+func (s *Service) IssueTicket(claims *entity.JwtClaims, audience string) (*entity.Ticket, *entity.AppError) {
+	ticketClaims := *claims
+	ticketClaims.Audience = audience
+	ticketClaims.IssuedAt = time.Now().Unix()
+
+	ticket, err := s.keyring.Sign(&ticketClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Ticket{Ticket: ticket, ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC()}, nil
+}
+
+// GetUser passes the calling user to next. Prefer IsUserAuthenticated together with UserFromContext.
 func (s *Service) GetUser(next func(w http.ResponseWriter, r *http.Request, user *entity.User)) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -44,9 +153,41 @@ func (s *Service) GetUser(next func(w http.ResponseWriter, r *http.Request, user
 	})
 }
 
-func (s *Service) isUserAuthenticated(w http.ResponseWriter, r *http.Request) error {
+// RevokeToken revokes the access token with the given claims until it expires.
+func (s *Service) RevokeToken(claims *entity.JwtClaims) *entity.AppError {
+	return s.revocationStore.Revoke(claims.Id, time.Unix(claims.ExpiresAt, 0))
+}
 
-	_, err := getParsedToken(r)
+// Authenticate verifies a token that does not come with a request, such as the first message
+// of a WebSocket connection, and returns its claims and the user it was issued to.
+func (s *Service) Authenticate(tokenString string) (*entity.JwtClaims, *entity.User, *entity.AppError) {
+	token, err := s.parseToken(tokenString, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims := token.Claims.(*entity.JwtClaims)
+	if claims.UserId == 0 {
+		return nil, nil, entity.NewAppError(errors.New("unable to access jwt claims"))
+	}
+
+	user, err := s.repo.FindById(claims.UserId)
+	if err != nil {
+		return nil, nil, entity.NewAppError(err).Wrap(errors.New("unable to get user"))
+	}
+
+	return claims, user, nil
+}
+
+// isUserAuthenticated returns the request with the verified claims and user in its context.
+// Requests that already passed through the middleware are returned as they are.
+func (s *Service) isUserAuthenticated(w http.ResponseWriter, r *http.Request) (*http.Request, error) {
+
+	if _, ok := UserFromContext(r.Context()); ok {
+		return r, nil
+	}
+
+	token, err := s.getParsedToken(r)
 	if err != nil {
 		if errors.Is(err, entity.ErrAppToken) {
 			w.WriteHeader(http.StatusBadRequest)
@@ -54,26 +195,90 @@ func (s *Service) isUserAuthenticated(w http.ResponseWriter, r *http.Request) er
 			w.WriteHeader(http.StatusForbidden)
 		}
 
-		return err
+		return nil, err
+	}
+
+	claims := token.Claims.(*entity.JwtClaims)
+	user, err := s.findClaimsUser(w, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.WithContext(NewContext(r.Context(), claims, user)), nil
+}
+
+func (s *Service) isUserAuthorized(w http.ResponseWriter, r *http.Request, isAllowed func(user *entity.User) bool) (*http.Request, error) {
+
+	r, err := s.isUserAuthenticated(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _ := UserFromContext(r.Context())
+	if !isAllowed(user) {
+		w.WriteHeader(http.StatusForbidden)
+		return nil, entity.ErrForbidden
+	}
+
+	return r, nil
+}
+
+func (s *Service) isResourceOwner(w http.ResponseWriter, r *http.Request, ownerOf func(r *http.Request) (int64, *entity.AppError)) (*http.Request, error) {
+
+	r, err := s.isUserAuthenticated(w, r)
+	if err != nil {
+		return nil, err
+	}
+
+	ownerId, ownerErr := ownerOf(r)
+	if ownerErr == entity.ErrEntityNotFound {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, ownerErr
+	}
+
+	if ownerErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, ownerErr
 	}
 
-	return nil
+	claims, _ := ClaimsFromContext(r.Context())
+	if claims.UserId != ownerId {
+		w.WriteHeader(http.StatusForbidden)
+		return nil, entity.ErrForbidden
+	}
+
+	return r, nil
 }
 
 func (s *Service) getUser(w http.ResponseWriter, r *http.Request) (*entity.User, error) {
-	token, err := getParsedToken(r)
+	token, err := s.getParsedToken(r)
 	if err != nil {
 		w.WriteHeader(http.StatusForbidden)
 		return nil, entity.NewAppError(err).Wrap(errors.New("unable to get token"))
 	}
 
-	userId := token.Claims.(*entity.JwtClaims).UserId
-	if userId == 0 {
+	user, err := s.findClaimsUser(w, token.Claims.(*entity.JwtClaims))
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// findClaimsUser loads the user a token was issued to. A user that no longer exists is
+// treated like an invalid token.
+func (s *Service) findClaimsUser(w http.ResponseWriter, claims *entity.JwtClaims) (*entity.User, *entity.AppError) {
+	if claims.UserId == 0 {
 		w.WriteHeader(http.StatusInternalServerError)
 		return nil, entity.NewAppError(errors.New("unable to access jwt claims"))
 	}
 
-	user, err := s.repo.FindById(userId)
+	user, err := s.repo.FindById(claims.UserId)
+
+	if err == entity.ErrEntityNotFound {
+		w.WriteHeader(http.StatusForbidden)
+		return nil, entity.NewAppError(err).Wrap(errors.New("unable to get user"))
+	}
 
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -83,45 +288,58 @@ func (s *Service) getUser(w http.ResponseWriter, r *http.Request) (*entity.User,
 	return user, nil
 }
 
-func getParsedToken(r *http.Request) (*jwt.Token, *entity.AppError) {
+func (s *Service) getParsedToken(r *http.Request) (*jwt.Token, *entity.AppError) {
 	//get token from header:
-	tokenString := r.Header.Get("Authorization")
+	return s.parseToken(r.Header.Get("Authorization"), "")
+}
+
+// parseToken verifies the token string and rejects tokens that have been revoked or that were
+// issued for another audience. Access tokens have an empty audience.
+func (s *Service) parseToken(tokenString string, audience string) (*jwt.Token, *entity.AppError) {
 	if tokenString == "" {
 		return nil, entity.NewAppError(errors.New("unable to find token"))
 	}
 
 	// verify token string:
-	token, err := parseJwt(tokenString)
+	token, err := parseJwt(tokenString, s.keyring)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return token, nil
-}
+	// a ticket is no access token, and a ticket for one request no ticket for another:
+	if token.Claims.(*entity.JwtClaims).Audience != audience {
+		return nil, entity.NewAppError(errors.New("jwt token was issued for another audience"))
+	}
 
-func parseJwt(tokenString string) (*jwt.Token, *entity.AppError) {
-	token, err := jwt.ParseWithClaims(tokenString, &entity.JwtClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// check token method:
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, entity.NewAppError(errors.New("unable to determine token algorithm method"))
-		}
+	// reject tokens that cannot be revoked or have been revoked:
+	jti := token.Claims.(*entity.JwtClaims).Id
+	if jti == "" {
+		return nil, entity.NewAppError(errors.New("jwt token has no id"))
+	}
 
-		// get secret key:
-		key, isFound := os.LookupEnv("SECRET_KEY")
-		if isFound == false {
-			return nil, entity.NewAppError(errors.New("unable to access secret key"))
-		}
+	isRevoked, err := s.revocationStore.IsRevoked(jti)
+	if err != nil {
+		return nil, err
+	}
 
-		return []byte(key), nil
-	})
+	if isRevoked {
+		return nil, entity.ErrTokenRevoked
+	}
+
+	return token, nil
+}
+
+// parseJwt verifies the token with the keyring key named by its kid header.
+func parseJwt(tokenString string, kr *keyring.Keyring) (*jwt.Token, *entity.AppError) {
+	token, err := jwt.ParseWithClaims(tokenString, &entity.JwtClaims{}, kr.Keyfunc)
 
 	if err, ok := err.(*jwt.ValidationError); ok {
 		if err.Errors&jwt.ValidationErrorMalformed != 0 {
 			return nil, entity.NewAppError(errors.New("jwt token has been malformed"))
 		}
 
-		if err.Errors&jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet != 0 {
+		if err.Errors&(jwt.ValidationErrorExpired|jwt.ValidationErrorNotValidYet) != 0 {
 			return nil, entity.NewAppError(errors.New("jwt token has expired or is not valid yet"))
 		}
 	}
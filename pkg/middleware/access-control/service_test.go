@@ -9,18 +9,49 @@ import (
 	"go.uber.org/mock/gomock"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"quiz-app/pkg/entity"
+	"quiz-app/pkg/keyring"
 	mockAccessCtrl "quiz-app/pkg/mocks/access-control"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
 
+func newTestKeyring(t *testing.T) *keyring.Keyring {
+	kr := keyring.New()
+
+	key, err := keyring.GenerateKey("test")
+	if err != nil {
+		t.Fatalf("unable to generate signing key: [%s]", err)
+	}
+
+	if err := kr.Add(key); err != nil {
+		t.Fatalf("unable to add signing key: [%s]", err)
+	}
+
+	if err := kr.SetSigningKey(key.Id); err != nil {
+		t.Fatalf("unable to set signing key: [%s]", err)
+	}
+
+	return kr
+}
+
+func signClaims(t *testing.T, kr *keyring.Keyring, claims jwt.Claims) string {
+	tokenString, err := kr.Sign(claims)
+	if err != nil {
+		t.Fatalf("unable to create jwt authentication string: [%s]", err)
+	}
+
+	return tokenString
+}
+
 func TestParseJwt(t *testing.T) {
 
+	kr := newTestKeyring(t)
+
 	t.Run("parseJwt should return an error when token is an empty string", func(t *testing.T) {
-		_, err := parseJwt("")
+		_, err := parseJwt("", kr)
 
 		if err == nil {
 			t.Fail()
@@ -41,6 +72,7 @@ func TestParseJwt(t *testing.T) {
 		}
 
 		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		token.Header["kid"] = "test"
 
 		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
 		if err != nil {
@@ -52,51 +84,107 @@ func TestParseJwt(t *testing.T) {
 			t.Fatalf("unable to create jwt authentication string: [%s]", err)
 		}
 
-		_, err = parseJwt(tokenString)
+		_, parseErr := parseJwt(tokenString, kr)
 
-		if err == nil {
-			t.Fail()
+		if parseErr == nil {
+			t.FailNow()
 		}
 
-		if err.Error() != "jwt token has expired or is not valid yet" {
+		if !strings.HasPrefix(parseErr.Error(), "jwt token could not be validated") {
 			t.Fail()
 		}
 	})
 
-	t.Run("parseJwt should return an error when SECRET_KEY environment value cant be found", func(t *testing.T) {
+	t.Run("parseJwt should return an error when an HMAC token reuses a public key id", func(t *testing.T) {
 
-		claims := struct {
-			hello string
-			jwt.StandardClaims
-		}{
-			hello: "hello",
+		claims := entity.JwtClaims{
+			StandardClaims: jwt.StandardClaims{
+				Id: "hello",
+			},
 		}
 
 		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = "test"
 
 		tokenString, err := token.SignedString([]byte("hello"))
 		if err != nil {
 			t.Fatalf("unable to create jwt authentication string: [%s]", err)
 		}
 
-		if err := os.Unsetenv("SECRET_KEY"); err != nil {
-			t.Fatalf("unable to unset env secret key: [%s]", err)
+		parsedToken, parseErr := parseJwt(tokenString, kr)
+
+		if parsedToken != nil || parseErr == nil {
+			t.Fail()
 		}
+	})
 
-		_, err = parseJwt(tokenString)
+	t.Run("parseJwt should return an error when the key id is unknown", func(t *testing.T) {
 
-		if err == nil {
+		otherKr := newTestKeyring(t)
+		key, err := keyring.GenerateKey("other")
+		if err != nil {
+			t.Fatalf("unable to generate signing key: [%s]", err)
+		}
+
+		if err := otherKr.Add(key); err != nil {
+			t.Fatalf("unable to add signing key: [%s]", err)
+		}
+
+		if err := otherKr.SetSigningKey("other"); err != nil {
+			t.Fatalf("unable to set signing key: [%s]", err)
+		}
+
+		tokenString := signClaims(t, otherKr, entity.JwtClaims{})
+
+		_, parseErr := parseJwt(tokenString, kr)
+
+		if parseErr == nil {
+			t.FailNow()
+		}
+
+		if !strings.Contains(parseErr.Error(), "unknown key id other") {
+			t.Fail()
+		}
+	})
+
+	t.Run("parseJwt should accept tokens signed with a retired key that is still in the keyring", func(t *testing.T) {
+
+		rotatedKr := newTestKeyring(t)
+		oldTokenString := signClaims(t, rotatedKr, entity.JwtClaims{})
+
+		key, err := keyring.GenerateKey("next")
+		if err != nil {
+			t.Fatalf("unable to generate signing key: [%s]", err)
+		}
+
+		if err := rotatedKr.Add(key); err != nil {
+			t.Fatalf("unable to add signing key: [%s]", err)
+		}
+
+		if err := rotatedKr.SetSigningKey("next"); err != nil {
+			t.Fatalf("unable to set signing key: [%s]", err)
+		}
+
+		if _, err := parseJwt(oldTokenString, rotatedKr); err != nil {
+			t.Fail()
+		}
+
+		if _, err := parseJwt(signClaims(t, rotatedKr, entity.JwtClaims{}), rotatedKr); err != nil {
 			t.Fail()
 		}
 
-		if err.Error() != "jwt token has expired or is not valid yet" {
+		if err := rotatedKr.Remove("test"); err != nil {
+			t.Fatalf("unable to remove key: [%s]", err)
+		}
+
+		if _, err := parseJwt(oldTokenString, rotatedKr); err == nil {
 			t.Fail()
 		}
 	})
 
 	t.Run("parseJwt should return an error when token is invalid format", func(t *testing.T) {
 
-		token, err := parseJwt("hello.hello")
+		token, err := parseJwt("hello.hello", kr)
 
 		if token != nil {
 			t.Fail()
@@ -119,20 +207,11 @@ func TestParseJwt(t *testing.T) {
 			hello: "hello",
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
-		}
-
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
-		}
+		tokenString := signClaims(t, kr, claims)
 
-		token, err = parseJwt(tokenString)
+		token, parseErr := parseJwt(tokenString, kr)
 
-		if err != nil {
+		if parseErr != nil {
 			t.Fail()
 		}
 
@@ -143,10 +222,15 @@ func TestParseJwt(t *testing.T) {
 }
 
 func TestGetToken(t *testing.T) {
+
+	kr := newTestKeyring(t)
+	revocationStore := InitMemoryRevocationStore()
+	service := InitService(nil, revocationStore, kr)
+
 	t.Run("getParsedToken should return error if request header does not have value for Authorization", func(t *testing.T) {
 
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
-		token, err := getParsedToken(r)
+		token, err := service.getParsedToken(r)
 
 		if token != nil {
 			t.Fail()
@@ -156,7 +240,7 @@ func TestGetToken(t *testing.T) {
 			t.Fail()
 		}
 
-		if !errors.Is(err, entity.NewAppError("unable to find token")) {
+		if !errors.Is(err, entity.NewAppError(errors.New("unable to find token"))) {
 			t.Fail()
 		}
 	})
@@ -166,7 +250,7 @@ func TestGetToken(t *testing.T) {
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 		r.Header.Set("Authorization", "hello.hello")
 
-		token, err := getParsedToken(r)
+		token, err := service.getParsedToken(r)
 
 		if token != nil {
 			t.Fail()
@@ -176,7 +260,8 @@ func TestGetToken(t *testing.T) {
 			t.Fail()
 		}
 
-		if !errors.As(err, &entity.AppError{}) {
+		var appErr *entity.AppError
+		if !errors.As(err, &appErr) {
 			t.Fail()
 		}
 	})
@@ -187,34 +272,77 @@ func TestGetToken(t *testing.T) {
 			hello string
 			jwt.StandardClaims
 		}{
-			hello: "hello",
+			hello:          "hello",
+			StandardClaims: jwt.StandardClaims{Id: "hello"},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
+		tokenString := signClaims(t, kr, claims)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", tokenString)
+
+		token, parseErr := service.getParsedToken(r)
+
+		if token == nil {
+			t.FailNow()
 		}
 
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
+		if parseErr != nil {
+			t.Fail()
 		}
 
+		if !token.Valid {
+			t.Fail()
+		}
+	})
+
+	t.Run("getParsedToken should return error if token has no id", func(t *testing.T) {
+
+		tokenString := signClaims(t, kr, entity.JwtClaims{})
+
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 		r.Header.Set("Authorization", tokenString)
 
-		token, err = getParsedToken(r)
+		token, parseErr := service.getParsedToken(r)
 
-		if token == nil {
+		if token != nil {
 			t.Fail()
 		}
 
-		if err != nil {
+		if !errors.Is(parseErr, entity.NewAppError(errors.New("jwt token has no id"))) {
 			t.Fail()
 		}
+	})
 
-		if !token.Valid {
+	t.Run("getParsedToken should return error if token has been revoked", func(t *testing.T) {
+
+		claims := entity.JwtClaims{
+			StandardClaims: jwt.StandardClaims{
+				Id:        "revoked",
+				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
+			},
+		}
+
+		tokenString := signClaims(t, kr, claims)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", tokenString)
+
+		if _, err := service.getParsedToken(r); err != nil {
+			t.Fatalf("unexpected error before revocation: [%s]", err)
+		}
+
+		if err := service.RevokeToken(&claims); err != nil {
+			t.Fatalf("unable to revoke token: [%s]", err)
+		}
+
+		token, parseErr := service.getParsedToken(r)
+
+		if token != nil {
+			t.Fail()
+		}
+
+		if parseErr != entity.ErrTokenRevoked {
 			t.Fail()
 		}
 	})
@@ -226,19 +354,16 @@ func TestIsUserAuthenticated(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockRepo := mockAccessCtrl.NewMockRepository(mockCtrl)
-	service := InitService(mockRepo)
+	kr := newTestKeyring(t)
+	service := InitService(mockRepo, InitMemoryRevocationStore(), kr)
 
 	t.Run("isUserAuthenticated should return error if token authentication fails", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 		r.Header.Add("Authorization", "hello.hello")
 
-		if err := service.isUserAuthenticated(w, r); err != nil {
-			wErr := entity.WrapAppError(
-				"unable to get token",
-				entity.WrapAppError(
-					"unable to authenticate token",
-					entity.NewAppError("jwt token has been malformed")))
+		if _, err := service.isUserAuthenticated(w, r); err != nil {
+			wErr := entity.NewAppError(errors.New("jwt token has been malformed"))
 			if !errors.Is(err, wErr) {
 				t.Fail()
 			}
@@ -255,27 +380,14 @@ func TestIsUserAuthenticated(t *testing.T) {
 			},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
-		}
-
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
-		}
+		tokenString := signClaims(t, kr, claims)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 		r.Header.Add("Authorization", tokenString)
 
-		if err := service.isUserAuthenticated(w, r); err != nil {
-			wErr := entity.WrapAppError(
-				"unable to get token",
-				entity.WrapAppError(
-					"unable to authenticate token",
-					entity.NewAppError("jwt token has expired or is not valid yet")))
+		if _, err := service.isUserAuthenticated(w, r); err != nil {
+			wErr := entity.NewAppError(errors.New("jwt token has expired or is not valid yet"))
 			if !errors.Is(err, wErr) {
 				t.Fail()
 			}
@@ -284,30 +396,68 @@ func TestIsUserAuthenticated(t *testing.T) {
 		}
 	})
 
-	t.Run("isUserAuthenticated should return nil if token is valid", func(t *testing.T) {
+	t.Run("isUserAuthenticated should return error if the user no longer exists", func(t *testing.T) {
 
 		claims := entity.JwtClaims{
+			UserId: 2,
 			StandardClaims: jwt.StandardClaims{
+				Id:        "hello",
 				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
 			},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
+		tokenString := signClaims(t, kr, claims)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Add("Authorization", tokenString)
+
+		mockRepo.EXPECT().FindById(int64(2)).Return(nil, entity.ErrEntityNotFound)
+
+		if _, err := service.isUserAuthenticated(w, r); err == nil {
+			t.Fail()
 		}
 
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
+		if w.Code != http.StatusForbidden {
+			t.Fail()
 		}
+	})
+
+	t.Run("isUserAuthenticated should store claims and user in the request context if token is valid", func(t *testing.T) {
+
+		claims := entity.JwtClaims{
+			UserId: 1,
+			StandardClaims: jwt.StandardClaims{
+				Id:        "hello",
+				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
+			},
+		}
+
+		tokenString := signClaims(t, kr, claims)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 		r.Header.Add("Authorization", tokenString)
 
-		if err := service.isUserAuthenticated(w, r); err != nil {
+		mockRepo.EXPECT().FindById(int64(1)).Return(&entity.User{Id: 1, Username: "munens"}, nil)
+
+		r, err := service.isUserAuthenticated(w, r)
+		if err != nil {
+			t.FailNow()
+		}
+
+		ctxClaims, ok := ClaimsFromContext(r.Context())
+		if !ok || ctxClaims.UserId != 1 || ctxClaims.Id != "hello" {
+			t.Fail()
+		}
+
+		user, ok := UserFromContext(r.Context())
+		if !ok || user.Username != "munens" {
+			t.Fail()
+		}
+
+		// a second pass reuses the stored principal without another lookup:
+		if _, err := service.isUserAuthenticated(w, r); err != nil {
 			t.Fail()
 		}
 	})
@@ -319,7 +469,8 @@ func TestGetUser(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockRepo := mockAccessCtrl.NewMockRepository(mockCtrl)
-	service := InitService(mockRepo)
+	kr := newTestKeyring(t)
+	service := InitService(mockRepo, InitMemoryRevocationStore(), kr)
 
 	t.Run("getUser should return error if token authentication fails", func(t *testing.T) {
 
@@ -329,11 +480,7 @@ func TestGetUser(t *testing.T) {
 
 		user, err := service.getUser(w, r)
 		if err != nil {
-			wErr := entity.WrapAppError(
-				"unable to get token",
-				entity.WrapAppError(
-					"unable to authenticate token",
-					entity.NewAppError("jwt token has been malformed")))
+			wErr := entity.NewAppError(errors.New("jwt token has been malformed")).Wrap(errors.New("unable to get token"))
 			if !errors.Is(err, wErr) {
 				t.Fail()
 			}
@@ -352,16 +499,7 @@ func TestGetUser(t *testing.T) {
 			},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
-		}
-
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
-		}
+		tokenString := signClaims(t, kr, claims)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -369,11 +507,7 @@ func TestGetUser(t *testing.T) {
 
 		user, err := service.getUser(w, r)
 		if err != nil {
-			wErr := entity.WrapAppError(
-				"unable to get token",
-				entity.WrapAppError(
-					"unable to authenticate token",
-					entity.NewAppError("jwt token has expired or is not valid yet")))
+			wErr := entity.NewAppError(errors.New("jwt token has expired or is not valid yet")).Wrap(errors.New("unable to get token"))
 			if !errors.Is(err, wErr) {
 				t.Fail()
 			}
@@ -386,18 +520,9 @@ func TestGetUser(t *testing.T) {
 
 	t.Run("getUser should return error if jwt claims cant be retrieved", func(t *testing.T) {
 
-		claims := jwt.StandardClaims{}
+		claims := jwt.StandardClaims{Id: "hello"}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
-		}
-
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
-		}
+		tokenString := signClaims(t, kr, claims)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -405,7 +530,7 @@ func TestGetUser(t *testing.T) {
 
 		user, err := service.getUser(w, r)
 		if err != nil {
-			if !errors.Is(err, entity.NewAppError("unable to access jwt claims")) {
+			if !errors.Is(err, entity.NewAppError(errors.New("unable to access jwt claims"))) {
 				t.Fail()
 			}
 		}
@@ -420,30 +545,22 @@ func TestGetUser(t *testing.T) {
 		claims := entity.JwtClaims{
 			UserId: 1,
 			StandardClaims: jwt.StandardClaims{
+				Id:        "hello",
 				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
 			},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
-		}
-
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
-		}
+		tokenString := signClaims(t, kr, claims)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
 		r.Header.Add("Authorization", tokenString)
 
-		mockRepo.EXPECT().FindById(int64(1)).Return(nil, entity.MlErrEntityNotFound)
+		mockRepo.EXPECT().FindById(int64(1)).Return(nil, entity.ErrEntityNotFound)
 
 		user, err := service.getUser(w, r)
 		if err != nil {
-			if !errors.Is(err, entity.WrapAppError("unable to get user", entity.MlErrEntityNotFound)) {
+			if !errors.Is(err, entity.NewAppError(entity.ErrEntityNotFound).Wrap(errors.New("unable to get user"))) {
 				t.Fail()
 			}
 		}
@@ -460,20 +577,12 @@ func TestGetUser(t *testing.T) {
 			Username: username,
 			UserId:   userId,
 			StandardClaims: jwt.StandardClaims{
+				Id:        "hello",
 				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
 			},
 		}
 
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		key := "hello"
-		tokenString, err := token.SignedString([]byte(key))
-		if err != nil {
-			t.Fatalf("unable to create jwt authentication string: [%s]", err)
-		}
-
-		if err := os.Setenv("SECRET_KEY", key); err != nil {
-			t.Fatalf("unable to set SECRET_KEY: [%s]", err)
-		}
+		tokenString := signClaims(t, kr, claims)
 
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -503,3 +612,263 @@ func TestGetUser(t *testing.T) {
 		}
 	})
 }
+
+func TestAuthenticate(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAccessCtrl.NewMockRepository(mockCtrl)
+	kr := newTestKeyring(t)
+	revocationStore := InitMemoryRevocationStore()
+	service := InitService(mockRepo, revocationStore, kr)
+
+	newClaims := func(id string) entity.JwtClaims {
+		return entity.JwtClaims{
+			UserId: 1,
+			StandardClaims: jwt.StandardClaims{
+				Id:        id,
+				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
+			},
+		}
+	}
+
+	t.Run("Authenticate should return the claims and user of a valid token", func(t *testing.T) {
+		tokenString := signClaims(t, kr, newClaims("valid"))
+
+		mockRepo.EXPECT().FindById(int64(1)).Return(&entity.User{Id: 1, Username: "munens"}, nil)
+
+		claims, user, err := service.Authenticate(tokenString)
+		if err != nil || claims.UserId != 1 || user.Username != "munens" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Authenticate should reject a missing token", func(t *testing.T) {
+		if _, _, err := service.Authenticate(""); err == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Authenticate should reject a revoked token", func(t *testing.T) {
+		claims := newClaims("revoked")
+		tokenString := signClaims(t, kr, claims)
+
+		if err := service.RevokeToken(&claims); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, _, err := service.Authenticate(tokenString); err != entity.ErrTokenRevoked {
+			t.Fail()
+		}
+	})
+}
+
+func TestTicket(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockAccessCtrl.NewMockRepository(mockCtrl)
+	mockRepo.EXPECT().FindById(int64(1)).Return(&entity.User{Id: 1, Username: "munens"}, nil).AnyTimes()
+
+	kr := newTestKeyring(t)
+	service := InitService(mockRepo, InitMemoryRevocationStore(), kr)
+
+	claims := &entity.JwtClaims{
+		UserId: 1,
+		StandardClaims: jwt.StandardClaims{
+			Id:        "access",
+			ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
+		},
+	}
+
+	next := service.IsUserAuthenticated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	handler := service.AllowTicket(func(r *http.Request) string { return "quiz-events:7" })(next)
+
+	ticket, err := service.IssueTicket(claims, "quiz-events:7")
+	if err != nil || ticket.ExpiresAt.Unix() != claims.ExpiresAt {
+		t.Fatal(err, ticket)
+	}
+
+	t.Run("AllowTicket should authenticate a request with a ticket for its audience", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?ticket="+ticket.Ticket, nil))
+
+		if w.Code != http.StatusOK {
+			t.Fail()
+		}
+	})
+
+	t.Run("AllowTicket should accept the ticket again when the client reconnects", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?ticket="+ticket.Ticket, nil))
+
+			if w.Code != http.StatusOK {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("AllowTicket should reject a ticket for another audience", func(t *testing.T) {
+		other, _ := service.IssueTicket(claims, "quiz-events:8")
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?ticket="+other.Ticket, nil))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("A ticket should not pass as an access token", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Add("Authorization", ticket.Ticket)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("A ticket should be revoked with its access token", func(t *testing.T) {
+		if err := service.RevokeToken(claims); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?ticket="+ticket.Ticket, nil))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	users := map[int64]*entity.User{
+		1: {Id: 1, Username: "munens", Roles: []string{entity.RoleUser}},
+		2: {Id: 2, Username: "admin", Roles: []string{entity.RoleAdmin}},
+	}
+
+	mockRepo := mockAccessCtrl.NewMockRepository(mockCtrl)
+	mockRepo.EXPECT().FindById(gomock.Any()).DoAndReturn(func(id int64) (*entity.User, *entity.AppError) {
+		return users[id], nil
+	}).AnyTimes()
+
+	kr := newTestKeyring(t)
+	service := InitService(mockRepo, InitMemoryRevocationStore(), kr)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// newRequest signs a token for the user that carries the roles, which authorization ignores:
+	newRequest := func(userId int64, roles ...string) *http.Request {
+		claims := entity.JwtClaims{
+			UserId: userId,
+			Roles:  roles,
+			StandardClaims: jwt.StandardClaims{
+				Id:        "hello",
+				ExpiresAt: time.Now().AddDate(0, 0, 1).Unix(),
+			},
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Add("Authorization", signClaims(t, kr, claims))
+		return r
+	}
+
+	t.Run("RequireRole should reject a request without a token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		service.RequireRole(entity.RoleAdmin)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequireRole should reject a user without any of the roles", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		service.RequireRole(entity.RoleAdmin)(next).ServeHTTP(w, newRequest(1, entity.RoleUser))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequireRole should accept a user with one of the roles", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		service.RequireRole(entity.RoleAdmin, entity.RoleUser)(next).ServeHTTP(w, newRequest(1, entity.RoleUser))
+
+		if w.Code != http.StatusOK {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequireRole should reject a role the token still carries after it was revoked", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		service.RequireRole(entity.RoleAdmin)(next).ServeHTTP(w, newRequest(1, entity.RoleAdmin))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequirePermission should reject a user whose roles miss one of the permissions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		service.RequirePermission(entity.PermissionUsersRead, entity.PermissionUsersList)(next).ServeHTTP(w, newRequest(1, entity.RoleAdmin))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequirePermission should accept a user whose roles grant all permissions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		service.RequirePermission(entity.PermissionUsersRead, entity.PermissionUsersList)(next).ServeHTTP(w, newRequest(2))
+
+		if w.Code != http.StatusOK {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequireOwnership should answer 404 when the resource does not exist", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ownerOf := func(r *http.Request) (int64, *entity.AppError) { return 0, entity.ErrEntityNotFound }
+		service.RequireOwnership(ownerOf)(next).ServeHTTP(w, newRequest(1))
+
+		if w.Code != http.StatusNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequireOwnership should reject a user that does not own the resource", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ownerOf := func(r *http.Request) (int64, *entity.AppError) { return 2, nil }
+		service.RequireOwnership(ownerOf)(next).ServeHTTP(w, newRequest(1))
+
+		if w.Code != http.StatusForbidden {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequireOwnership should accept the owner of the resource", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		ownerOf := func(r *http.Request) (int64, *entity.AppError) { return 1, nil }
+		service.RequireOwnership(ownerOf)(next).ServeHTTP(w, newRequest(1))
+
+		if w.Code != http.StatusOK {
+			t.Fail()
+		}
+	})
+}
@@ -2,13 +2,15 @@ package middleware
 
 import (
 	"github.com/rs/cors"
-	"quiz-app/config"
 )
 
-var Cors = cors.New(cors.Options{
-	AllowedOrigins:   []string{config.RequestOriginURL},
-	AllowedHeaders:   []string{"*"},
-	AllowCredentials: true,
-	ExposedHeaders:   []string{"Authorization", "Access-Control-Allow-Origin"},
-	MaxAge:           5,
-})
+// NewCors allows the app at allowedOrigin to call the api with credentials.
+func NewCors(allowedOrigin string) *cors.Cors {
+	return cors.New(cors.Options{
+		AllowedOrigins:   []string{allowedOrigin},
+		AllowedHeaders:   []string{"*"},
+		AllowCredentials: true,
+		ExposedHeaders:   []string{"Authorization", "Access-Control-Allow-Origin"},
+		MaxAge:           5,
+	})
+}
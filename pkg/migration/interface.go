@@ -0,0 +1,23 @@
+package migration
+
+import (
+	"context"
+	"quiz-app/pkg/entity"
+)
+
+type Reader interface {
+	FindApplied(ctx context.Context) ([]*entity.AppliedMigration, *entity.AppError)
+}
+
+type Writer interface {
+	CreateVersionTable() *entity.AppError
+	Apply(migration *entity.Migration) *entity.AppError
+	Revert(migration *entity.Migration) *entity.AppError
+}
+
+// Repository interface
+type Repository interface {
+	Reader
+	Writer
+	Lock() (func() *entity.AppError, *entity.AppError)
+}
@@ -0,0 +1,149 @@
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"quiz-app/pkg/entity"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Dir is where the migrations are kept in the source tree, relative to its root.
+const Dir = "pkg/migration/migrations"
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Embedded returns the migrations built into the server.
+func Embedded() ([]*entity.Migration, *entity.AppError) {
+	migrations, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return Load(migrations)
+}
+
+// Load reads the migrations of the directory by version. Every migration is a pair of files
+// named like 0001_create_users.up.sql and 0001_create_users.down.sql.
+func Load(dir fs.FS) ([]*entity.Migration, *entity.AppError) {
+	files, err := fs.ReadDir(dir, ".")
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	byVersion := make(map[int64]*entity.Migration)
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(file.Name())
+		if match == nil {
+			return nil, entity.NewInvalidMigrationError(file.Name() + " is not named like 0001_name.up.sql")
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &entity.Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, entity.NewInvalidMigrationError(fmt.Sprintf("version %d is used by %s and %s", version, migration.Name, match[2]))
+		}
+
+		content, err := fs.ReadFile(dir, file.Name())
+		if err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]*entity.Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, entity.NewInvalidMigrationError(Label(migration) + " needs an up and a down file that are not empty")
+		}
+
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Create adds the empty up and down files of a new migration to the directory, with the version
+// after the latest one, and returns their paths.
+func Create(dir string, name string) ([]string, *entity.AppError) {
+	if !namePattern.MatchString(name) {
+		return nil, entity.NewInvalidMigrationError("names may only have lowercase letters, digits and underscores")
+	}
+
+	migrations, err := Load(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	migration := &entity.Migration{Version: 1, Name: name}
+	if len(migrations) > 0 {
+		migration.Version = migrations[len(migrations)-1].Version + 1
+	}
+
+	paths := make([]string, 0, 2)
+	files := []struct{ direction, comment string }{
+		{direction: "up", comment: "the sql that makes the change"},
+		{direction: "down", comment: "the sql that undoes the change"},
+	}
+
+	for _, f := range files {
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s.sql", Label(migration), f.direction))
+		content := fmt.Sprintf("-- %s: %s\n", Label(migration), f.comment)
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, entity.NewInvalidMigrationError(path + " already exists")
+		}
+
+		if err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		_, err = file.WriteString(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// Label names the migration like its files, such as 0001_create_users.
+func Label(migration *entity.Migration) string {
+	return fmt.Sprintf("%04d_%s", migration.Version, migration.Name)
+}
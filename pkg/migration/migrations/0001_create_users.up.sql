@@ -0,0 +1,7 @@
+create table if not exists users (
+    id            bigserial primary key,
+    username      text        not null unique,
+    password      text        not null,
+    created_at    timestamptz not null default now(),
+    last_login_at timestamptz
+);
@@ -0,0 +1,13 @@
+create table if not exists refresh_tokens (
+    id         bigserial primary key,
+    user_id    bigint      not null references users (id) on delete cascade,
+    family_id  text        not null,
+    token_hash text        not null unique,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now(),
+    used_at    timestamptz,
+    revoked_at timestamptz
+);
+
+create index if not exists refresh_tokens_family_id_idx on refresh_tokens (family_id);
+create index if not exists refresh_tokens_user_id_idx on refresh_tokens (user_id);
@@ -0,0 +1,6 @@
+create table if not exists user_roles (
+    user_id    bigint      not null references users (id) on delete cascade,
+    role       text        not null,
+    created_at timestamptz not null default now(),
+    primary key (user_id, role)
+);
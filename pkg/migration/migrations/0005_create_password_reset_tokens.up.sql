@@ -0,0 +1,10 @@
+create table if not exists password_reset_tokens (
+    id         bigserial primary key,
+    user_id    bigint      not null references users (id) on delete cascade,
+    token_hash text        not null unique,
+    expires_at timestamptz not null,
+    created_at timestamptz not null default now(),
+    used_at    timestamptz
+);
+
+create index if not exists password_reset_tokens_user_id_idx on password_reset_tokens (user_id);
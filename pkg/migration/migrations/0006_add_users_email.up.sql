@@ -0,0 +1,3 @@
+alter table users add column if not exists email text;
+
+create unique index if not exists users_email_idx on users (lower(email));
@@ -0,0 +1,3 @@
+drop table if exists answer_options;
+drop table if exists questions;
+drop table if exists quizzes;
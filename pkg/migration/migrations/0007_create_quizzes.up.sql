@@ -0,0 +1,29 @@
+create table if not exists quizzes (
+    id          bigserial primary key,
+    owner_id    bigint      not null references users (id) on delete cascade,
+    title       text        not null,
+    description text        not null default '',
+    created_at  timestamptz not null default now(),
+    updated_at  timestamptz not null default now()
+);
+
+create index if not exists quizzes_owner_id_idx on quizzes (owner_id);
+
+create table if not exists questions (
+    id       bigserial primary key,
+    quiz_id  bigint  not null references quizzes (id) on delete cascade,
+    position integer not null,
+    text     text    not null
+);
+
+create index if not exists questions_quiz_id_idx on questions (quiz_id, position);
+
+create table if not exists answer_options (
+    id          bigserial primary key,
+    question_id bigint  not null references questions (id) on delete cascade,
+    position    integer not null,
+    text        text    not null,
+    is_correct  boolean not null default false
+);
+
+create index if not exists answer_options_question_id_idx on answer_options (question_id, position);
@@ -0,0 +1,23 @@
+create table if not exists attempts (
+    id           bigserial primary key,
+    quiz_id      bigint           not null references quizzes (id) on delete cascade,
+    user_id      bigint           not null references users (id) on delete cascade,
+    status       text             not null default 'in_progress',
+    score        double precision not null default 0,
+    max_score    double precision not null,
+    started_at   timestamptz      not null default now(),
+    submitted_at timestamptz
+);
+
+create index if not exists attempts_user_id_idx on attempts (user_id);
+create index if not exists attempts_quiz_id_idx on attempts (quiz_id);
+
+create table if not exists attempt_answers (
+    attempt_id  bigint           not null references attempts (id) on delete cascade,
+    question_id bigint           not null references questions (id) on delete cascade,
+    response    jsonb            not null,
+    is_correct  boolean          not null,
+    score       double precision not null,
+    answered_at timestamptz      not null default now(),
+    primary key (attempt_id, question_id)
+);
@@ -0,0 +1,4 @@
+alter table answer_options drop column if exists match;
+
+alter table questions drop column if exists answer;
+alter table questions drop column if exists type;
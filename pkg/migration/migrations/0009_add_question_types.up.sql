@@ -0,0 +1,4 @@
+alter table questions add column if not exists type text not null default 'multiple_choice';
+alter table questions add column if not exists answer jsonb;
+
+alter table answer_options add column if not exists match text not null default '';
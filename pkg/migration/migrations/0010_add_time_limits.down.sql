@@ -0,0 +1,6 @@
+drop table if exists attempt_questions;
+
+alter table attempts drop column if exists deadline;
+
+alter table questions drop column if exists time_limit_seconds;
+alter table quizzes drop column if exists time_limit_seconds;
@@ -0,0 +1,11 @@
+alter table quizzes add column if not exists time_limit_seconds integer not null default 0;
+alter table questions add column if not exists time_limit_seconds integer not null default 0;
+
+alter table attempts add column if not exists deadline timestamptz;
+
+create table if not exists attempt_questions (
+    attempt_id  bigint      not null references attempts (id) on delete cascade,
+    question_id bigint      not null references questions (id) on delete cascade,
+    opened_at   timestamptz not null default now(),
+    primary key (attempt_id, question_id)
+);
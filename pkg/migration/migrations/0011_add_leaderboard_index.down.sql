@@ -0,0 +1 @@
+drop index if exists attempts_leaderboard_idx;
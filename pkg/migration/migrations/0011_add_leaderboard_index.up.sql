@@ -0,0 +1 @@
+create index if not exists attempts_leaderboard_idx on attempts (status, submitted_at, quiz_id);
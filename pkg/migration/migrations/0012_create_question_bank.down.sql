@@ -0,0 +1,16 @@
+drop table if exists attempt_draws;
+
+alter table attempts drop column if exists seed;
+
+drop table if exists quiz_draw_rules;
+
+drop index if exists questions_tags_idx;
+drop index if exists questions_bank_idx;
+
+-- questions of the question bank have no quiz to belong to once it is gone:
+delete from questions where quiz_id is null;
+
+alter table questions drop column if exists tags;
+alter table questions drop column if exists difficulty;
+alter table questions drop column if exists owner_id;
+alter table questions alter column quiz_id set not null;
@@ -0,0 +1,26 @@
+-- questions without a quiz belong to the question bank of their owner:
+alter table questions alter column quiz_id drop not null;
+alter table questions add column if not exists owner_id bigint references users (id) on delete cascade;
+alter table questions add column if not exists difficulty text not null default 'medium';
+alter table questions add column if not exists tags jsonb not null default '[]';
+
+create index if not exists questions_bank_idx on questions (owner_id, id) where quiz_id is null;
+create index if not exists questions_tags_idx on questions using gin (tags);
+
+create table if not exists quiz_draw_rules (
+    quiz_id    bigint  not null references quizzes (id) on delete cascade,
+    position   integer not null,
+    tag        text    not null default '',
+    difficulty text    not null default '',
+    count      integer not null,
+    primary key (quiz_id, position)
+);
+
+alter table attempts add column if not exists seed bigint;
+
+create table if not exists attempt_draws (
+    attempt_id  bigint  not null references attempts (id) on delete cascade,
+    question_id bigint  not null references questions (id) on delete cascade,
+    position    integer not null,
+    primary key (attempt_id, question_id)
+);
@@ -0,0 +1,14 @@
+-- the questions of the quiz when the attempt started, so that questions added later cannot be answered:
+create table if not exists attempt_questions (
+    attempt_id  bigint  not null references attempts (id) on delete cascade,
+    question_id bigint  not null references questions (id) on delete cascade,
+    position    integer not null,
+    primary key (attempt_id, question_id)
+);
+
+-- attempts in progress get the questions their quiz has now:
+insert into attempt_questions (attempt_id, question_id, position)
+select a.id, q.id, row_number() over (partition by a.id order by q.position, q.id)
+from attempts a join questions q on q.quiz_id = a.quiz_id
+where a.status = 'in_progress'
+on conflict do nothing;
@@ -0,0 +1 @@
+drop index if exists refresh_tokens_user_id_idx;
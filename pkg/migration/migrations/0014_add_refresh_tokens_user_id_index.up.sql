@@ -0,0 +1,2 @@
+-- databases migrated before 0002_create_refresh_tokens created the index:
+create index if not exists refresh_tokens_user_id_idx on refresh_tokens (user_id);
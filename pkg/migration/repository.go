@@ -0,0 +1,132 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"quiz-app/pkg/entity"
+	"time"
+)
+
+// lockKey is the key of the advisory lock that keeps servers starting at the same time from
+// running the migrations twice.
+const lockKey int64 = 7301942271
+
+type PGRepository struct {
+	pool *sql.DB
+}
+
+func InitRepo(p *sql.DB) *PGRepository {
+	return &PGRepository{
+		pool: p,
+	}
+}
+
+// Lock waits for the advisory lock of the migrations and returns the function that releases
+// it. The lock belongs to a connection, so it holds one of the pool until it is released.
+func (r PGRepository) Lock() (func() *entity.AppError, *entity.AppError) {
+	ctx := context.Background()
+
+	conn, err := r.pool.Conn(ctx)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "select pg_advisory_lock($1)", lockKey); err != nil {
+		conn.Close()
+		return nil, entity.NewAppError(err)
+	}
+
+	unlock := func() *entity.AppError {
+		defer conn.Close()
+
+		if _, err := conn.ExecContext(ctx, "select pg_advisory_unlock($1)", lockKey); err != nil {
+			return entity.NewAppError(err)
+		}
+
+		return nil
+	}
+
+	return unlock, nil
+}
+
+func (r PGRepository) CreateVersionTable() *entity.AppError {
+	query := "create table if not exists schema_migrations (version bigint primary key, name text not null, applied_at timestamptz not null default now())"
+
+	if _, err := r.pool.Exec(query); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+// FindApplied returns the applied migrations by version, and none before the version table
+// is created.
+func (r PGRepository) FindApplied(ctx context.Context) ([]*entity.AppliedMigration, *entity.AppError) {
+	applied := make([]*entity.AppliedMigration, 0)
+
+	var exists bool
+	if err := r.pool.QueryRowContext(ctx, "select to_regclass('schema_migrations') is not null").Scan(&exists); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := r.pool.QueryContext(ctx, "select version, name, applied_at from schema_migrations order by version")
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var migration entity.AppliedMigration
+		if err := rows.Scan(&migration.Version, &migration.Name, &migration.AppliedAt); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		applied = append(applied, &migration)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return applied, nil
+}
+
+// Apply runs the up sql of the migration and records its version in one transaction.
+func (r PGRepository) Apply(migration *entity.Migration) *entity.AppError {
+	query := "insert into schema_migrations (version, name, applied_at) values ($1, $2, $3)"
+	return r.inTransaction(migration.Up, query, migration.Version, migration.Name, time.Now().UTC())
+}
+
+// Revert runs the down sql of the migration and removes its version in one transaction.
+func (r PGRepository) Revert(migration *entity.Migration) *entity.AppError {
+	query := "delete from schema_migrations where version=$1"
+	return r.inTransaction(migration.Down, query, migration.Version)
+}
+
+// inTransaction runs the statements of the migration, which cannot take arguments, and then
+// the query that records it.
+func (r PGRepository) inTransaction(statements string, query string, args ...interface{}) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(statements); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	if _, err := tx.Exec(query, args...); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
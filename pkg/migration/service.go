@@ -0,0 +1,156 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"quiz-app/pkg/entity"
+)
+
+type Service struct {
+	repo       Repository
+	migrations []*entity.Migration
+}
+
+// InitService creates the service that migrates the database with the migrations, which are
+// sorted by version, such as the ones Embedded returns.
+func InitService(r Repository, migrations []*entity.Migration) *Service {
+	return &Service{
+		repo:       r,
+		migrations: migrations,
+	}
+}
+
+// Up applies the pending migrations in the order of their versions and returns them. A
+// migration that fails is rolled back and stops the ones after it.
+func (s *Service) Up() ([]*entity.Migration, *entity.AppError) {
+	done := make([]*entity.Migration, 0)
+
+	err := s.locked(func(applied []*entity.AppliedMigration) *entity.AppError {
+		versions := make(map[int64]bool)
+		for _, migration := range applied {
+			versions[migration.Version] = true
+		}
+
+		for _, migration := range s.migrations {
+			if versions[migration.Version] {
+				continue
+			}
+
+			if err := s.repo.Apply(migration); err != nil {
+				return entity.NewAppError(fmt.Errorf("applying %s: %w", Label(migration), err))
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts up to steps of the applied migrations, latest first, and returns them.
+func (s *Service) Down(steps int) ([]*entity.Migration, *entity.AppError) {
+	done := make([]*entity.Migration, 0)
+
+	err := s.locked(func(applied []*entity.AppliedMigration) *entity.AppError {
+		for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := s.find(applied[i].Version)
+			if migration == nil {
+				return entity.ErrUnknownMigration
+			}
+
+			if err := s.repo.Revert(migration); err != nil {
+				return entity.NewAppError(fmt.Errorf("reverting %s: %w", Label(migration), err))
+			}
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Status lists every migration with when it was applied, followed by the applied migrations
+// this version of the server does not know. The query stops when ctx is done.
+func (s *Service) Status(ctx context.Context) ([]*entity.MigrationStatus, *entity.AppError) {
+	applied, err := s.repo.FindApplied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedAt := make(map[int64]*entity.AppliedMigration)
+	for _, migration := range applied {
+		appliedAt[migration.Version] = migration
+	}
+
+	statuses := make([]*entity.MigrationStatus, 0, len(s.migrations))
+	for _, migration := range s.migrations {
+		status := &entity.MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if done, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &done.AppliedAt
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	for _, migration := range applied {
+		if s.find(migration.Version) == nil {
+			statuses = append(statuses, &entity.MigrationStatus{Version: migration.Version, Name: migration.Name, AppliedAt: &migration.AppliedAt, Unknown: true})
+		}
+	}
+
+	return statuses, nil
+}
+
+// Pending counts the migrations that are not applied yet.
+func (s *Service) Pending(ctx context.Context) (int, *entity.AppError) {
+	statuses, err := s.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// locked runs fn with the applied migrations while holding the migration lock.
+func (s *Service) locked(fn func(applied []*entity.AppliedMigration) *entity.AppError) (err *entity.AppError) {
+	unlock, err := s.repo.Lock()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if unlockErr := unlock(); unlockErr != nil && err == nil {
+			err = unlockErr
+		}
+	}()
+
+	if err := s.repo.CreateVersionTable(); err != nil {
+		return err
+	}
+
+	applied, err := s.repo.FindApplied(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return fn(applied)
+}
+
+func (s *Service) find(version int64) *entity.Migration {
+	for _, migration := range s.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+
+	return nil
+}
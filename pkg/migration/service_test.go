@@ -0,0 +1,226 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"os"
+	"path/filepath"
+	"quiz-app/pkg/entity"
+	mockMigration "quiz-app/pkg/mocks/migration"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newTestMigrations() []*entity.Migration {
+	return []*entity.Migration{
+		{Version: 1, Name: "create_users", Up: "create table users ();", Down: "drop table users;"},
+		{Version: 2, Name: "create_quizzes", Up: "create table quizzes ();", Down: "drop table quizzes;"},
+		{Version: 3, Name: "add_tags", Up: "alter table quizzes add column tags jsonb;", Down: "alter table quizzes drop column tags;"},
+	}
+}
+
+func labels(migrations []*entity.Migration) string {
+	names := make([]string, 0, len(migrations))
+	for _, migration := range migrations {
+		names = append(names, Label(migration))
+	}
+
+	return strings.Join(names, ",")
+}
+
+func TestMigrate(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockMigration.NewMockRepository(mockCtrl)
+	migrations := newTestMigrations()
+	service := InitService(mockRepo, migrations)
+
+	appliedAt := time.Date(2024, time.May, 15, 13, 0, 0, 0, time.UTC)
+	applied := []*entity.AppliedMigration{{Version: 1, Name: "create_users", AppliedAt: appliedAt}}
+
+	// expectLock expects the lock to be taken and released once:
+	expectLock := func() {
+		unlocked := false
+		mockRepo.EXPECT().Lock().Return(func() *entity.AppError {
+			unlocked = true
+			return nil
+		}, nil)
+		t.Cleanup(func() {
+			if !unlocked {
+				t.Error("lock was not released")
+			}
+		})
+		mockRepo.EXPECT().CreateVersionTable().Return(nil)
+	}
+
+	t.Run("Up should apply the pending migrations in order while holding the lock", func(t *testing.T) {
+		expectLock()
+		mockRepo.EXPECT().FindApplied(gomock.Any()).Return(applied, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().Apply(migrations[1]).Return(nil),
+			mockRepo.EXPECT().Apply(migrations[2]).Return(nil),
+		)
+
+		done, err := service.Up()
+
+		if err != nil || labels(done) != "0002_create_quizzes,0003_add_tags" {
+			t.Fatal(err, labels(done))
+		}
+	})
+
+	t.Run("Up should stop at a migration that fails", func(t *testing.T) {
+		expectLock()
+		mockRepo.EXPECT().FindApplied(gomock.Any()).Return(applied, nil)
+		mockRepo.EXPECT().Apply(migrations[1]).Return(entity.NewAppError(errors.New("syntax error")))
+
+		done, err := service.Up()
+
+		if err == nil || !strings.Contains(err.Error(), "0002_create_quizzes: syntax error") || len(done) != 0 {
+			t.Fatal(err, labels(done))
+		}
+	})
+
+	t.Run("Up should fail when it cannot take the lock", func(t *testing.T) {
+		mockRepo.EXPECT().Lock().Return(nil, entity.NewAppError(errors.New("connection refused")))
+
+		if _, err := service.Up(); err == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Down should revert the latest migrations first", func(t *testing.T) {
+		expectLock()
+		mockRepo.EXPECT().FindApplied(gomock.Any()).Return([]*entity.AppliedMigration{{Version: 1}, {Version: 2}, {Version: 3}}, nil)
+		gomock.InOrder(
+			mockRepo.EXPECT().Revert(migrations[2]).Return(nil),
+			mockRepo.EXPECT().Revert(migrations[1]).Return(nil),
+		)
+
+		done, err := service.Down(2)
+
+		if err != nil || labels(done) != "0003_add_tags,0002_create_quizzes" {
+			t.Fatal(err, labels(done))
+		}
+	})
+
+	t.Run("Down should not revert a migration it does not know", func(t *testing.T) {
+		expectLock()
+		mockRepo.EXPECT().FindApplied(gomock.Any()).Return([]*entity.AppliedMigration{{Version: 1}, {Version: 4, Name: "from_the_future"}}, nil)
+
+		if _, err := service.Down(1); err != entity.ErrUnknownMigration {
+			t.Fail()
+		}
+	})
+
+	t.Run("Status should list the pending, applied and unknown migrations", func(t *testing.T) {
+		mockRepo.EXPECT().FindApplied(gomock.Any()).Return([]*entity.AppliedMigration{applied[0], {Version: 4, Name: "from_the_future", AppliedAt: appliedAt}}, nil)
+
+		statuses, err := service.Status(context.Background())
+
+		if err != nil || len(statuses) != 4 {
+			t.Fatal(err, statuses)
+		}
+
+		if statuses[0].AppliedAt == nil || !statuses[0].AppliedAt.Equal(appliedAt) || statuses[1].AppliedAt != nil || !statuses[3].Unknown || statuses[3].Name != "from_the_future" {
+			t.Fail()
+		}
+	})
+
+	t.Run("Pending should count the migrations that are not applied", func(t *testing.T) {
+		mockRepo.EXPECT().FindApplied(gomock.Any()).Return(applied, nil)
+
+		if pending, err := service.Pending(context.Background()); err != nil || pending != 2 {
+			t.Fail()
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+
+	t.Run("Load should pair the up and down files by version", func(t *testing.T) {
+		dir := fstest.MapFS{
+			"0002_create_quizzes.up.sql":   {Data: []byte("create table quizzes ();")},
+			"0002_create_quizzes.down.sql": {Data: []byte("drop table quizzes;")},
+			"0001_create_users.up.sql":     {Data: []byte("create table users ();")},
+			"0001_create_users.down.sql":   {Data: []byte("drop table users;")},
+			"README.md":                    {Data: []byte("not a migration")},
+		}
+
+		migrations, err := Load(dir)
+
+		if err != nil || labels(migrations) != "0001_create_users,0002_create_quizzes" || migrations[0].Down != "drop table users;" {
+			t.Fatal(err, labels(migrations))
+		}
+	})
+
+	t.Run("Load should reject migrations without a down file", func(t *testing.T) {
+		dir := fstest.MapFS{"0001_create_users.up.sql": {Data: []byte("create table users ();")}}
+
+		if _, err := Load(dir); !errors.Is(err, entity.ErrInvalidMigration) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Load should reject two migrations with the same version", func(t *testing.T) {
+		dir := fstest.MapFS{
+			"0001_create_users.up.sql":     {Data: []byte("create table users ();")},
+			"0001_create_quizzes.down.sql": {Data: []byte("drop table quizzes;")},
+		}
+
+		if _, err := Load(dir); !errors.Is(err, entity.ErrInvalidMigration) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Load should reject sql files that are not named like migrations", func(t *testing.T) {
+		dir := fstest.MapFS{"create_users.sql": {Data: []byte("create table users ();")}}
+
+		if _, err := Load(dir); !errors.Is(err, entity.ErrInvalidMigration) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Embedded should start with the users table", func(t *testing.T) {
+		migrations, err := Embedded()
+
+		if err != nil || len(migrations) == 0 || Label(migrations[0]) != "0001_create_users" {
+			t.Fatal(err)
+		}
+
+		for i, migration := range migrations {
+			if migration.Version != int64(i+1) {
+				t.Error("missing version", i+1)
+			}
+		}
+	})
+}
+
+func TestCreate(t *testing.T) {
+
+	t.Run("Create should add the files of the next version", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "0007_create_users.up.sql"), []byte("create table users ();"), 0644)
+		os.WriteFile(filepath.Join(dir, "0007_create_users.down.sql"), []byte("drop table users;"), 0644)
+
+		paths, err := Create(dir, "add_tags")
+
+		if err != nil || len(paths) != 2 || filepath.Base(paths[0]) != "0008_add_tags.up.sql" || filepath.Base(paths[1]) != "0008_add_tags.down.sql" {
+			t.Fatal(err, paths)
+		}
+
+		if migrations, err := Load(os.DirFS(dir)); err != nil || len(migrations) != 2 {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Create should reject names that do not fit a file name", func(t *testing.T) {
+		if _, err := Create(t.TempDir(), "Add Tags"); !errors.Is(err, entity.ErrInvalidMigration) {
+			t.Fail()
+		}
+	})
+}
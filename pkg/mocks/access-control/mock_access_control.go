@@ -11,6 +11,7 @@ package mock_access_control
 import (
 	entity "quiz-app/pkg/entity"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -39,11 +40,11 @@ func (m *Mockreader) EXPECT() *MockreaderMockRecorder {
 }
 
 // FindById mocks base method.
-func (m *Mockreader) FindById(id int64) (*entity.User, error) {
+func (m *Mockreader) FindById(id int64) (*entity.User, *entity.AppError) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FindById", id)
 	ret0, _ := ret[0].(*entity.User)
-	ret1, _ := ret[1].(error)
+	ret1, _ := ret[1].(*entity.AppError)
 	return ret0, ret1
 }
 
@@ -100,11 +101,11 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 }
 
 // FindById mocks base method.
-func (m *MockRepository) FindById(id int64) (*entity.User, error) {
+func (m *MockRepository) FindById(id int64) (*entity.User, *entity.AppError) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FindById", id)
 	ret0, _ := ret[0].(*entity.User)
-	ret1, _ := ret[1].(error)
+	ret1, _ := ret[1].(*entity.AppError)
 	return ret0, ret1
 }
 
@@ -113,3 +114,55 @@ func (mr *MockRepositoryMockRecorder) FindById(id any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindById", reflect.TypeOf((*MockRepository)(nil).FindById), id)
 }
+
+// MockRevocationStore is a mock of RevocationStore interface.
+type MockRevocationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevocationStoreMockRecorder
+}
+
+// MockRevocationStoreMockRecorder is the mock recorder for MockRevocationStore.
+type MockRevocationStoreMockRecorder struct {
+	mock *MockRevocationStore
+}
+
+// NewMockRevocationStore creates a new mock instance.
+func NewMockRevocationStore(ctrl *gomock.Controller) *MockRevocationStore {
+	mock := &MockRevocationStore{ctrl: ctrl}
+	mock.recorder = &MockRevocationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevocationStore) EXPECT() *MockRevocationStoreMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockRevocationStore) IsRevoked(jti string) (bool, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", jti)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockRevocationStoreMockRecorder) IsRevoked(jti any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockRevocationStore)(nil).IsRevoked), jti)
+}
+
+// Revoke mocks base method.
+func (m *MockRevocationStore) Revoke(jti string, expiresAt time.Time) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", jti, expiresAt)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockRevocationStoreMockRecorder) Revoke(jti, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockRevocationStore)(nil).Revoke), jti, expiresAt)
+}
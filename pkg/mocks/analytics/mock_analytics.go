@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/analytics/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/analytics/interface.go -destination pkg/mocks/analytics/mock_analytics.go
+//
+// Package mock_analytics is a generated GoMock package.
+package mock_analytics
+
+import (
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// FindItemResponses mocks base method.
+func (m *MockReader) FindItemResponses(quiz_id int64) ([]*entity.ItemResponse, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindItemResponses", quiz_id)
+	ret0, _ := ret[0].([]*entity.ItemResponse)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindItemResponses indicates an expected call of FindItemResponses.
+func (mr *MockReaderMockRecorder) FindItemResponses(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindItemResponses", reflect.TypeOf((*MockReader)(nil).FindItemResponses), quiz_id)
+}
+
+// FindSubmittedAttempts mocks base method.
+func (m *MockReader) FindSubmittedAttempts(quiz_id int64) ([]*entity.Attempt, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubmittedAttempts", quiz_id)
+	ret0, _ := ret[0].([]*entity.Attempt)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindSubmittedAttempts indicates an expected call of FindSubmittedAttempts.
+func (mr *MockReaderMockRecorder) FindSubmittedAttempts(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubmittedAttempts", reflect.TypeOf((*MockReader)(nil).FindSubmittedAttempts), quiz_id)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindItemResponses mocks base method.
+func (m *MockRepository) FindItemResponses(quiz_id int64) ([]*entity.ItemResponse, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindItemResponses", quiz_id)
+	ret0, _ := ret[0].([]*entity.ItemResponse)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindItemResponses indicates an expected call of FindItemResponses.
+func (mr *MockRepositoryMockRecorder) FindItemResponses(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindItemResponses", reflect.TypeOf((*MockRepository)(nil).FindItemResponses), quiz_id)
+}
+
+// FindSubmittedAttempts mocks base method.
+func (m *MockRepository) FindSubmittedAttempts(quiz_id int64) ([]*entity.Attempt, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubmittedAttempts", quiz_id)
+	ret0, _ := ret[0].([]*entity.Attempt)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindSubmittedAttempts indicates an expected call of FindSubmittedAttempts.
+func (mr *MockRepositoryMockRecorder) FindSubmittedAttempts(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubmittedAttempts", reflect.TypeOf((*MockRepository)(nil).FindSubmittedAttempts), quiz_id)
+}
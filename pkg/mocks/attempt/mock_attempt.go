@@ -0,0 +1,330 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/attempt/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/attempt/interface.go -destination pkg/mocks/attempt/mock_attempt.go
+//
+// Package mock_attempt is a generated GoMock package.
+package mock_attempt
+
+import (
+	sql "database/sql"
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// FindAnswersByAttemptID mocks base method.
+func (m *MockReader) FindAnswersByAttemptID(attempt_id int64) ([]*entity.AttemptAnswer, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAnswersByAttemptID", attempt_id)
+	ret0, _ := ret[0].([]*entity.AttemptAnswer)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindAnswersByAttemptID indicates an expected call of FindAnswersByAttemptID.
+func (mr *MockReaderMockRecorder) FindAnswersByAttemptID(attempt_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAnswersByAttemptID", reflect.TypeOf((*MockReader)(nil).FindAnswersByAttemptID), attempt_id)
+}
+
+// FindByID mocks base method.
+func (m *MockReader) FindByID(attempt_id int64) (*entity.Attempt, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", attempt_id)
+	ret0, _ := ret[0].(*entity.Attempt)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockReaderMockRecorder) FindByID(attempt_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockReader)(nil).FindByID), attempt_id)
+}
+
+// FindQuestionOpenedAt mocks base method.
+func (m *MockReader) FindQuestionOpenedAt(attempt_id, question_id int64) (time.Time, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuestionOpenedAt", attempt_id, question_id)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuestionOpenedAt indicates an expected call of FindQuestionOpenedAt.
+func (mr *MockReaderMockRecorder) FindQuestionOpenedAt(attempt_id, question_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuestionOpenedAt", reflect.TypeOf((*MockReader)(nil).FindQuestionOpenedAt), attempt_id, question_id)
+}
+
+// MockWriter is a mock of Writer interface.
+type MockWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWriterMockRecorder
+}
+
+// MockWriterMockRecorder is the mock recorder for MockWriter.
+type MockWriterMockRecorder struct {
+	mock *MockWriter
+}
+
+// NewMockWriter creates a new mock instance.
+func NewMockWriter(ctrl *gomock.Controller) *MockWriter {
+	mock := &MockWriter{ctrl: ctrl}
+	mock.recorder = &MockWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWriter) EXPECT() *MockWriterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWriter) Create(attempt *entity.Attempt) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", attempt)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWriterMockRecorder) Create(attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWriter)(nil).Create), attempt)
+}
+
+// OpenQuestion mocks base method.
+func (m *MockWriter) OpenQuestion(attempt_id, question_id int64, openedAt time.Time) (time.Time, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenQuestion", attempt_id, question_id, openedAt)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// OpenQuestion indicates an expected call of OpenQuestion.
+func (mr *MockWriterMockRecorder) OpenQuestion(attempt_id, question_id, openedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenQuestion", reflect.TypeOf((*MockWriter)(nil).OpenQuestion), attempt_id, question_id, openedAt)
+}
+
+// SaveAnswer mocks base method.
+func (m *MockWriter) SaveAnswer(answer *entity.AttemptAnswer) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAnswer", answer)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// SaveAnswer indicates an expected call of SaveAnswer.
+func (mr *MockWriterMockRecorder) SaveAnswer(answer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAnswer", reflect.TypeOf((*MockWriter)(nil).SaveAnswer), answer)
+}
+
+// Submit mocks base method.
+func (m *MockWriter) Submit(attempt_id int64, submittedAt time.Time) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Submit", attempt_id, submittedAt)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Submit indicates an expected call of Submit.
+func (mr *MockWriterMockRecorder) Submit(attempt_id, submittedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Submit", reflect.TypeOf((*MockWriter)(nil).Submit), attempt_id, submittedAt)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(attempt *entity.Attempt) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", attempt)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), attempt)
+}
+
+// FindAnswersByAttemptID mocks base method.
+func (m *MockRepository) FindAnswersByAttemptID(attempt_id int64) ([]*entity.AttemptAnswer, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAnswersByAttemptID", attempt_id)
+	ret0, _ := ret[0].([]*entity.AttemptAnswer)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindAnswersByAttemptID indicates an expected call of FindAnswersByAttemptID.
+func (mr *MockRepositoryMockRecorder) FindAnswersByAttemptID(attempt_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAnswersByAttemptID", reflect.TypeOf((*MockRepository)(nil).FindAnswersByAttemptID), attempt_id)
+}
+
+// FindByID mocks base method.
+func (m *MockRepository) FindByID(attempt_id int64) (*entity.Attempt, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", attempt_id)
+	ret0, _ := ret[0].(*entity.Attempt)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockRepositoryMockRecorder) FindByID(attempt_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), attempt_id)
+}
+
+// FindQuestionOpenedAt mocks base method.
+func (m *MockRepository) FindQuestionOpenedAt(attempt_id, question_id int64) (time.Time, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuestionOpenedAt", attempt_id, question_id)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuestionOpenedAt indicates an expected call of FindQuestionOpenedAt.
+func (mr *MockRepositoryMockRecorder) FindQuestionOpenedAt(attempt_id, question_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuestionOpenedAt", reflect.TypeOf((*MockRepository)(nil).FindQuestionOpenedAt), attempt_id, question_id)
+}
+
+// OpenQuestion mocks base method.
+func (m *MockRepository) OpenQuestion(attempt_id, question_id int64, openedAt time.Time) (time.Time, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenQuestion", attempt_id, question_id, openedAt)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// OpenQuestion indicates an expected call of OpenQuestion.
+func (mr *MockRepositoryMockRecorder) OpenQuestion(attempt_id, question_id, openedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenQuestion", reflect.TypeOf((*MockRepository)(nil).OpenQuestion), attempt_id, question_id, openedAt)
+}
+
+// SaveAnswer mocks base method.
+func (m *MockRepository) SaveAnswer(answer *entity.AttemptAnswer) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAnswer", answer)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// SaveAnswer indicates an expected call of SaveAnswer.
+func (mr *MockRepositoryMockRecorder) SaveAnswer(answer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAnswer", reflect.TypeOf((*MockRepository)(nil).SaveAnswer), answer)
+}
+
+// Submit mocks base method.
+func (m *MockRepository) Submit(attempt_id int64, submittedAt time.Time) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Submit", attempt_id, submittedAt)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Submit indicates an expected call of Submit.
+func (mr *MockRepositoryMockRecorder) Submit(attempt_id, submittedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Submit", reflect.TypeOf((*MockRepository)(nil).Submit), attempt_id, submittedAt)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// AttemptSubmitted mocks base method.
+func (m *MockNotifier) AttemptSubmitted(attempt *entity.Attempt) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AttemptSubmitted", attempt)
+}
+
+// AttemptSubmitted indicates an expected call of AttemptSubmitted.
+func (mr *MockNotifierMockRecorder) AttemptSubmitted(attempt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AttemptSubmitted", reflect.TypeOf((*MockNotifier)(nil).AttemptSubmitted), attempt)
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/leaderboard/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/leaderboard/interface.go -destination pkg/mocks/leaderboard/mock_leaderboard.go
+//
+// Package mock_leaderboard is a generated GoMock package.
+package mock_leaderboard
+
+import (
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// FindGlobalEntries mocks base method.
+func (m *MockReader) FindGlobalEntries(since time.Time, limit int, user_id int64) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGlobalEntries", since, limit, user_id)
+	ret0, _ := ret[0].([]*entity.LeaderboardEntry)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindGlobalEntries indicates an expected call of FindGlobalEntries.
+func (mr *MockReaderMockRecorder) FindGlobalEntries(since, limit, user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGlobalEntries", reflect.TypeOf((*MockReader)(nil).FindGlobalEntries), since, limit, user_id)
+}
+
+// FindQuizEntries mocks base method.
+func (m *MockReader) FindQuizEntries(quiz_id int64, since time.Time, limit int, user_id int64) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuizEntries", quiz_id, since, limit, user_id)
+	ret0, _ := ret[0].([]*entity.LeaderboardEntry)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuizEntries indicates an expected call of FindQuizEntries.
+func (mr *MockReaderMockRecorder) FindQuizEntries(quiz_id, since, limit, user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuizEntries", reflect.TypeOf((*MockReader)(nil).FindQuizEntries), quiz_id, since, limit, user_id)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindGlobalEntries mocks base method.
+func (m *MockRepository) FindGlobalEntries(since time.Time, limit int, user_id int64) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindGlobalEntries", since, limit, user_id)
+	ret0, _ := ret[0].([]*entity.LeaderboardEntry)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindGlobalEntries indicates an expected call of FindGlobalEntries.
+func (mr *MockRepositoryMockRecorder) FindGlobalEntries(since, limit, user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindGlobalEntries", reflect.TypeOf((*MockRepository)(nil).FindGlobalEntries), since, limit, user_id)
+}
+
+// FindQuizEntries mocks base method.
+func (m *MockRepository) FindQuizEntries(quiz_id int64, since time.Time, limit int, user_id int64) ([]*entity.LeaderboardEntry, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuizEntries", quiz_id, since, limit, user_id)
+	ret0, _ := ret[0].([]*entity.LeaderboardEntry)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuizEntries indicates an expected call of FindQuizEntries.
+func (mr *MockRepositoryMockRecorder) FindQuizEntries(quiz_id, since, limit, user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuizEntries", reflect.TypeOf((*MockRepository)(nil).FindQuizEntries), quiz_id, since, limit, user_id)
+}
@@ -0,0 +1,215 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/migration/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/migration/interface.go -destination pkg/mocks/migration/mock_migration.go
+//
+// Package mock_migration is a generated GoMock package.
+package mock_migration
+
+import (
+	context "context"
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// FindApplied mocks base method.
+func (m *MockReader) FindApplied(ctx context.Context) ([]*entity.AppliedMigration, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindApplied", ctx)
+	ret0, _ := ret[0].([]*entity.AppliedMigration)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindApplied indicates an expected call of FindApplied.
+func (mr *MockReaderMockRecorder) FindApplied(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApplied", reflect.TypeOf((*MockReader)(nil).FindApplied), ctx)
+}
+
+// MockWriter is a mock of Writer interface.
+type MockWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWriterMockRecorder
+}
+
+// MockWriterMockRecorder is the mock recorder for MockWriter.
+type MockWriterMockRecorder struct {
+	mock *MockWriter
+}
+
+// NewMockWriter creates a new mock instance.
+func NewMockWriter(ctrl *gomock.Controller) *MockWriter {
+	mock := &MockWriter{ctrl: ctrl}
+	mock.recorder = &MockWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWriter) EXPECT() *MockWriterMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockWriter) Apply(migration *entity.Migration) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", migration)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockWriterMockRecorder) Apply(migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockWriter)(nil).Apply), migration)
+}
+
+// CreateVersionTable mocks base method.
+func (m *MockWriter) CreateVersionTable() *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVersionTable")
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateVersionTable indicates an expected call of CreateVersionTable.
+func (mr *MockWriterMockRecorder) CreateVersionTable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVersionTable", reflect.TypeOf((*MockWriter)(nil).CreateVersionTable))
+}
+
+// Revert mocks base method.
+func (m *MockWriter) Revert(migration *entity.Migration) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revert", migration)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Revert indicates an expected call of Revert.
+func (mr *MockWriterMockRecorder) Revert(migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revert", reflect.TypeOf((*MockWriter)(nil).Revert), migration)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method.
+func (m *MockRepository) Apply(migration *entity.Migration) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", migration)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Apply indicates an expected call of Apply.
+func (mr *MockRepositoryMockRecorder) Apply(migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockRepository)(nil).Apply), migration)
+}
+
+// CreateVersionTable mocks base method.
+func (m *MockRepository) CreateVersionTable() *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVersionTable")
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateVersionTable indicates an expected call of CreateVersionTable.
+func (mr *MockRepositoryMockRecorder) CreateVersionTable() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVersionTable", reflect.TypeOf((*MockRepository)(nil).CreateVersionTable))
+}
+
+// FindApplied mocks base method.
+func (m *MockRepository) FindApplied(ctx context.Context) ([]*entity.AppliedMigration, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindApplied", ctx)
+	ret0, _ := ret[0].([]*entity.AppliedMigration)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindApplied indicates an expected call of FindApplied.
+func (mr *MockRepositoryMockRecorder) FindApplied(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindApplied", reflect.TypeOf((*MockRepository)(nil).FindApplied), ctx)
+}
+
+// Lock mocks base method.
+func (m *MockRepository) Lock() (func() *entity.AppError, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock")
+	ret0, _ := ret[0].(func() *entity.AppError)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockRepositoryMockRecorder) Lock() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockRepository)(nil).Lock))
+}
+
+// Revert mocks base method.
+func (m *MockRepository) Revert(migration *entity.Migration) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revert", migration)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Revert indicates an expected call of Revert.
+func (mr *MockRepositoryMockRecorder) Revert(migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revert", reflect.TypeOf((*MockRepository)(nil).Revert), migration)
+}
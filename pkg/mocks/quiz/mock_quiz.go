@@ -0,0 +1,496 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/quiz/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/quiz/interface.go -destination pkg/mocks/quiz/mock_quiz.go
+//
+// Package mock_quiz is a generated GoMock package.
+package mock_quiz
+
+import (
+	sql "database/sql"
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// FindBankQuestions mocks base method.
+func (m *MockReader) FindBankQuestions(query *entity.BankQuery) ([]*entity.Question, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBankQuestions", query)
+	ret0, _ := ret[0].([]*entity.Question)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindBankQuestions indicates an expected call of FindBankQuestions.
+func (mr *MockReaderMockRecorder) FindBankQuestions(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBankQuestions", reflect.TypeOf((*MockReader)(nil).FindBankQuestions), query)
+}
+
+// FindByID mocks base method.
+func (m *MockReader) FindByID(quiz_id int64) (*entity.Quiz, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", quiz_id)
+	ret0, _ := ret[0].(*entity.Quiz)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockReaderMockRecorder) FindByID(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockReader)(nil).FindByID), quiz_id)
+}
+
+// FindDrawRules mocks base method.
+func (m *MockReader) FindDrawRules(quiz_id int64) ([]*entity.DrawRule, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDrawRules", quiz_id)
+	ret0, _ := ret[0].([]*entity.DrawRule)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindDrawRules indicates an expected call of FindDrawRules.
+func (mr *MockReaderMockRecorder) FindDrawRules(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDrawRules", reflect.TypeOf((*MockReader)(nil).FindDrawRules), quiz_id)
+}
+
+// FindQuestionByID mocks base method.
+func (m *MockReader) FindQuestionByID(question_id int64) (*entity.Question, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuestionByID", question_id)
+	ret0, _ := ret[0].(*entity.Question)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuestionByID indicates an expected call of FindQuestionByID.
+func (mr *MockReaderMockRecorder) FindQuestionByID(question_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuestionByID", reflect.TypeOf((*MockReader)(nil).FindQuestionByID), question_id)
+}
+
+// FindQuestionsByQuizID mocks base method.
+func (m *MockReader) FindQuestionsByQuizID(quiz_id int64) ([]*entity.Question, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuestionsByQuizID", quiz_id)
+	ret0, _ := ret[0].([]*entity.Question)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuestionsByQuizID indicates an expected call of FindQuestionsByQuizID.
+func (mr *MockReaderMockRecorder) FindQuestionsByQuizID(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuestionsByQuizID", reflect.TypeOf((*MockReader)(nil).FindQuestionsByQuizID), quiz_id)
+}
+
+// List mocks base method.
+func (m *MockReader) List(query *entity.QuizListQuery) ([]*entity.Quiz, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", query)
+	ret0, _ := ret[0].([]*entity.Quiz)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReaderMockRecorder) List(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReader)(nil).List), query)
+}
+
+// MockWriter is a mock of Writer interface.
+type MockWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWriterMockRecorder
+}
+
+// MockWriterMockRecorder is the mock recorder for MockWriter.
+type MockWriterMockRecorder struct {
+	mock *MockWriter
+}
+
+// NewMockWriter creates a new mock instance.
+func NewMockWriter(ctrl *gomock.Controller) *MockWriter {
+	mock := &MockWriter{ctrl: ctrl}
+	mock.recorder = &MockWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWriter) EXPECT() *MockWriterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWriter) Create(quiz *entity.Quiz) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", quiz)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWriterMockRecorder) Create(quiz any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWriter)(nil).Create), quiz)
+}
+
+// CreateBankQuestion mocks base method.
+func (m *MockWriter) CreateBankQuestion(question *entity.Question) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBankQuestion", question)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateBankQuestion indicates an expected call of CreateBankQuestion.
+func (mr *MockWriterMockRecorder) CreateBankQuestion(question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBankQuestion", reflect.TypeOf((*MockWriter)(nil).CreateBankQuestion), question)
+}
+
+// CreateQuestion mocks base method.
+func (m *MockWriter) CreateQuestion(question *entity.Question) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuestion", question)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateQuestion indicates an expected call of CreateQuestion.
+func (mr *MockWriterMockRecorder) CreateQuestion(question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuestion", reflect.TypeOf((*MockWriter)(nil).CreateQuestion), question)
+}
+
+// Delete mocks base method.
+func (m *MockWriter) Delete(quiz_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", quiz_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWriterMockRecorder) Delete(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWriter)(nil).Delete), quiz_id)
+}
+
+// DeleteQuestion mocks base method.
+func (m *MockWriter) DeleteQuestion(question_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQuestion", question_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// DeleteQuestion indicates an expected call of DeleteQuestion.
+func (mr *MockWriterMockRecorder) DeleteQuestion(question_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQuestion", reflect.TypeOf((*MockWriter)(nil).DeleteQuestion), question_id)
+}
+
+// SetDrawRules mocks base method.
+func (m *MockWriter) SetDrawRules(quiz_id int64, rules []*entity.DrawRule) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDrawRules", quiz_id, rules)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// SetDrawRules indicates an expected call of SetDrawRules.
+func (mr *MockWriterMockRecorder) SetDrawRules(quiz_id, rules any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDrawRules", reflect.TypeOf((*MockWriter)(nil).SetDrawRules), quiz_id, rules)
+}
+
+// Update mocks base method.
+func (m *MockWriter) Update(quiz *entity.Quiz) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", quiz)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockWriterMockRecorder) Update(quiz any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWriter)(nil).Update), quiz)
+}
+
+// UpdateQuestion mocks base method.
+func (m *MockWriter) UpdateQuestion(question *entity.Question) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuestion", question)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// UpdateQuestion indicates an expected call of UpdateQuestion.
+func (mr *MockWriterMockRecorder) UpdateQuestion(question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuestion", reflect.TypeOf((*MockWriter)(nil).UpdateQuestion), question)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(quiz *entity.Quiz) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", quiz)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(quiz any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), quiz)
+}
+
+// CreateBankQuestion mocks base method.
+func (m *MockRepository) CreateBankQuestion(question *entity.Question) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBankQuestion", question)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateBankQuestion indicates an expected call of CreateBankQuestion.
+func (mr *MockRepositoryMockRecorder) CreateBankQuestion(question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBankQuestion", reflect.TypeOf((*MockRepository)(nil).CreateBankQuestion), question)
+}
+
+// CreateQuestion mocks base method.
+func (m *MockRepository) CreateQuestion(question *entity.Question) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuestion", question)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateQuestion indicates an expected call of CreateQuestion.
+func (mr *MockRepositoryMockRecorder) CreateQuestion(question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuestion", reflect.TypeOf((*MockRepository)(nil).CreateQuestion), question)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(quiz_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", quiz_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), quiz_id)
+}
+
+// DeleteQuestion mocks base method.
+func (m *MockRepository) DeleteQuestion(question_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQuestion", question_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// DeleteQuestion indicates an expected call of DeleteQuestion.
+func (mr *MockRepositoryMockRecorder) DeleteQuestion(question_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQuestion", reflect.TypeOf((*MockRepository)(nil).DeleteQuestion), question_id)
+}
+
+// FindBankQuestions mocks base method.
+func (m *MockRepository) FindBankQuestions(query *entity.BankQuery) ([]*entity.Question, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBankQuestions", query)
+	ret0, _ := ret[0].([]*entity.Question)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindBankQuestions indicates an expected call of FindBankQuestions.
+func (mr *MockRepositoryMockRecorder) FindBankQuestions(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBankQuestions", reflect.TypeOf((*MockRepository)(nil).FindBankQuestions), query)
+}
+
+// FindByID mocks base method.
+func (m *MockRepository) FindByID(quiz_id int64) (*entity.Quiz, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", quiz_id)
+	ret0, _ := ret[0].(*entity.Quiz)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockRepositoryMockRecorder) FindByID(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), quiz_id)
+}
+
+// FindDrawRules mocks base method.
+func (m *MockRepository) FindDrawRules(quiz_id int64) ([]*entity.DrawRule, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDrawRules", quiz_id)
+	ret0, _ := ret[0].([]*entity.DrawRule)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindDrawRules indicates an expected call of FindDrawRules.
+func (mr *MockRepositoryMockRecorder) FindDrawRules(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDrawRules", reflect.TypeOf((*MockRepository)(nil).FindDrawRules), quiz_id)
+}
+
+// FindQuestionByID mocks base method.
+func (m *MockRepository) FindQuestionByID(question_id int64) (*entity.Question, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuestionByID", question_id)
+	ret0, _ := ret[0].(*entity.Question)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuestionByID indicates an expected call of FindQuestionByID.
+func (mr *MockRepositoryMockRecorder) FindQuestionByID(question_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuestionByID", reflect.TypeOf((*MockRepository)(nil).FindQuestionByID), question_id)
+}
+
+// FindQuestionsByQuizID mocks base method.
+func (m *MockRepository) FindQuestionsByQuizID(quiz_id int64) ([]*entity.Question, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindQuestionsByQuizID", quiz_id)
+	ret0, _ := ret[0].([]*entity.Question)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindQuestionsByQuizID indicates an expected call of FindQuestionsByQuizID.
+func (mr *MockRepositoryMockRecorder) FindQuestionsByQuizID(quiz_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindQuestionsByQuizID", reflect.TypeOf((*MockRepository)(nil).FindQuestionsByQuizID), quiz_id)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(query *entity.QuizListQuery) ([]*entity.Quiz, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", query)
+	ret0, _ := ret[0].([]*entity.Quiz)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), query)
+}
+
+// SetDrawRules mocks base method.
+func (m *MockRepository) SetDrawRules(quiz_id int64, rules []*entity.DrawRule) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDrawRules", quiz_id, rules)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// SetDrawRules indicates an expected call of SetDrawRules.
+func (mr *MockRepositoryMockRecorder) SetDrawRules(quiz_id, rules any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDrawRules", reflect.TypeOf((*MockRepository)(nil).SetDrawRules), quiz_id, rules)
+}
+
+// Update mocks base method.
+func (m *MockRepository) Update(quiz *entity.Quiz) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", quiz)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockRepositoryMockRecorder) Update(quiz any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRepository)(nil).Update), quiz)
+}
+
+// UpdateQuestion mocks base method.
+func (m *MockRepository) UpdateQuestion(question *entity.Question) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateQuestion", question)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// UpdateQuestion indicates an expected call of UpdateQuestion.
+func (mr *MockRepositoryMockRecorder) UpdateQuestion(question any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateQuestion", reflect.TypeOf((*MockRepository)(nil).UpdateQuestion), question)
+}
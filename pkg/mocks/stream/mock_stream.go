@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/stream/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/stream/interface.go -destination pkg/mocks/stream/mock_stream.go
+//
+// Package mock_stream is a generated GoMock package.
+package mock_stream
+
+import (
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLeaderboardReader is a mock of LeaderboardReader interface.
+type MockLeaderboardReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockLeaderboardReaderMockRecorder
+}
+
+// MockLeaderboardReaderMockRecorder is the mock recorder for MockLeaderboardReader.
+type MockLeaderboardReaderMockRecorder struct {
+	mock *MockLeaderboardReader
+}
+
+// NewMockLeaderboardReader creates a new mock instance.
+func NewMockLeaderboardReader(ctrl *gomock.Controller) *MockLeaderboardReader {
+	mock := &MockLeaderboardReader{ctrl: ctrl}
+	mock.recorder = &MockLeaderboardReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLeaderboardReader) EXPECT() *MockLeaderboardReaderMockRecorder {
+	return m.recorder
+}
+
+// GetQuizLeaderboard mocks base method.
+func (m *MockLeaderboardReader) GetQuizLeaderboard(quizId int64, window string, limit int, callerId int64) (*entity.Leaderboard, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuizLeaderboard", quizId, window, limit, callerId)
+	ret0, _ := ret[0].(*entity.Leaderboard)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// GetQuizLeaderboard indicates an expected call of GetQuizLeaderboard.
+func (mr *MockLeaderboardReaderMockRecorder) GetQuizLeaderboard(quizId, window, limit, callerId any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuizLeaderboard", reflect.TypeOf((*MockLeaderboardReader)(nil).GetQuizLeaderboard), quizId, window, limit, callerId)
+}
@@ -0,0 +1,772 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: pkg/user/interface.go
+//
+// Generated by this command:
+//
+//	mockgen -source pkg/user/interface.go -destination pkg/mocks/user/mock_user.go
+//
+// Package mock_user is a generated GoMock package.
+package mock_user
+
+import (
+	sql "database/sql"
+	entity "quiz-app/pkg/entity"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReader is a mock of Reader interface.
+type MockReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockReaderMockRecorder
+}
+
+// MockReaderMockRecorder is the mock recorder for MockReader.
+type MockReaderMockRecorder struct {
+	mock *MockReader
+}
+
+// NewMockReader creates a new mock instance.
+func NewMockReader(ctrl *gomock.Controller) *MockReader {
+	mock := &MockReader{ctrl: ctrl}
+	mock.recorder = &MockReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReader) EXPECT() *MockReaderMockRecorder {
+	return m.recorder
+}
+
+// FindByEmail mocks base method.
+func (m *MockReader) FindByEmail(email string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEmail", email)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockReaderMockRecorder) FindByEmail(email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockReader)(nil).FindByEmail), email)
+}
+
+// FindByID mocks base method.
+func (m *MockReader) FindByID(user_id int64) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", user_id)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockReaderMockRecorder) FindByID(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockReader)(nil).FindByID), user_id)
+}
+
+// FindByIDAndReturnPassword mocks base method.
+func (m *MockReader) FindByIDAndReturnPassword(user_id int64) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDAndReturnPassword", user_id)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByIDAndReturnPassword indicates an expected call of FindByIDAndReturnPassword.
+func (mr *MockReaderMockRecorder) FindByIDAndReturnPassword(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDAndReturnPassword", reflect.TypeOf((*MockReader)(nil).FindByIDAndReturnPassword), user_id)
+}
+
+// FindByUsername mocks base method.
+func (m *MockReader) FindByUsername(username string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUsername", username)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByUsername indicates an expected call of FindByUsername.
+func (mr *MockReaderMockRecorder) FindByUsername(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUsername", reflect.TypeOf((*MockReader)(nil).FindByUsername), username)
+}
+
+// FindByUsernameAndReturnPassword mocks base method.
+func (m *MockReader) FindByUsernameAndReturnPassword(username string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUsernameAndReturnPassword", username)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByUsernameAndReturnPassword indicates an expected call of FindByUsernameAndReturnPassword.
+func (mr *MockReaderMockRecorder) FindByUsernameAndReturnPassword(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUsernameAndReturnPassword", reflect.TypeOf((*MockReader)(nil).FindByUsernameAndReturnPassword), username)
+}
+
+// FindPasswordResetTokenByHash mocks base method.
+func (m *MockReader) FindPasswordResetTokenByHash(tokenHash string) (*entity.PasswordResetToken, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPasswordResetTokenByHash", tokenHash)
+	ret0, _ := ret[0].(*entity.PasswordResetToken)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindPasswordResetTokenByHash indicates an expected call of FindPasswordResetTokenByHash.
+func (mr *MockReaderMockRecorder) FindPasswordResetTokenByHash(tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPasswordResetTokenByHash", reflect.TypeOf((*MockReader)(nil).FindPasswordResetTokenByHash), tokenHash)
+}
+
+// FindRefreshTokenByHash mocks base method.
+func (m *MockReader) FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRefreshTokenByHash", tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindRefreshTokenByHash indicates an expected call of FindRefreshTokenByHash.
+func (mr *MockReaderMockRecorder) FindRefreshTokenByHash(tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefreshTokenByHash", reflect.TypeOf((*MockReader)(nil).FindRefreshTokenByHash), tokenHash)
+}
+
+// FindRolesByUserID mocks base method.
+func (m *MockReader) FindRolesByUserID(user_id int64) ([]string, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRolesByUserID", user_id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindRolesByUserID indicates an expected call of FindRolesByUserID.
+func (mr *MockReaderMockRecorder) FindRolesByUserID(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRolesByUserID", reflect.TypeOf((*MockReader)(nil).FindRolesByUserID), user_id)
+}
+
+// List mocks base method.
+func (m *MockReader) List(query *entity.UserListQuery) ([]*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", query)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockReaderMockRecorder) List(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockReader)(nil).List), query)
+}
+
+// MockWriter is a mock of Writer interface.
+type MockWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWriterMockRecorder
+}
+
+// MockWriterMockRecorder is the mock recorder for MockWriter.
+type MockWriterMockRecorder struct {
+	mock *MockWriter
+}
+
+// NewMockWriter creates a new mock instance.
+func NewMockWriter(ctrl *gomock.Controller) *MockWriter {
+	mock := &MockWriter{ctrl: ctrl}
+	mock.recorder = &MockWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWriter) EXPECT() *MockWriterMockRecorder {
+	return m.recorder
+}
+
+// AddRole mocks base method.
+func (m *MockWriter) AddRole(user_id int64, role string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRole", user_id, role)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// AddRole indicates an expected call of AddRole.
+func (mr *MockWriterMockRecorder) AddRole(user_id, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockWriter)(nil).AddRole), user_id, role)
+}
+
+// Create mocks base method.
+func (m *MockWriter) Create(username, email, passwordHash, role string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", username, email, passwordHash, role)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWriterMockRecorder) Create(username, email, passwordHash, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWriter)(nil).Create), username, email, passwordHash, role)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockWriter) CreatePasswordResetToken(token *entity.PasswordResetToken) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", token)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockWriterMockRecorder) CreatePasswordResetToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockWriter)(nil).CreatePasswordResetToken), token)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockWriter) CreateRefreshToken(token *entity.RefreshToken) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", token)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockWriterMockRecorder) CreateRefreshToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockWriter)(nil).CreateRefreshToken), token)
+}
+
+// Delete mocks base method.
+func (m *MockWriter) Delete(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWriterMockRecorder) Delete(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWriter)(nil).Delete), user_id)
+}
+
+// InvalidatePasswordResetTokens mocks base method.
+func (m *MockWriter) InvalidatePasswordResetTokens(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidatePasswordResetTokens", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// InvalidatePasswordResetTokens indicates an expected call of InvalidatePasswordResetTokens.
+func (mr *MockWriterMockRecorder) InvalidatePasswordResetTokens(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidatePasswordResetTokens", reflect.TypeOf((*MockWriter)(nil).InvalidatePasswordResetTokens), user_id)
+}
+
+// MarkPasswordResetTokenUsed mocks base method.
+func (m *MockWriter) MarkPasswordResetTokenUsed(token_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPasswordResetTokenUsed", token_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// MarkPasswordResetTokenUsed indicates an expected call of MarkPasswordResetTokenUsed.
+func (mr *MockWriterMockRecorder) MarkPasswordResetTokenUsed(token_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPasswordResetTokenUsed", reflect.TypeOf((*MockWriter)(nil).MarkPasswordResetTokenUsed), token_id)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockWriter) MarkRefreshTokenUsed(token_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", token_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockWriterMockRecorder) MarkRefreshTokenUsed(token_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockWriter)(nil).MarkRefreshTokenUsed), token_id)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockWriter) RevokeRefreshTokenFamily(family_id string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", family_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockWriterMockRecorder) RevokeRefreshTokenFamily(family_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockWriter)(nil).RevokeRefreshTokenFamily), family_id)
+}
+
+// RevokeRefreshTokensByUserID mocks base method.
+func (m *MockWriter) RevokeRefreshTokensByUserID(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokensByUserID", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// RevokeRefreshTokensByUserID indicates an expected call of RevokeRefreshTokensByUserID.
+func (mr *MockWriterMockRecorder) RevokeRefreshTokensByUserID(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokensByUserID", reflect.TypeOf((*MockWriter)(nil).RevokeRefreshTokensByUserID), user_id)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockWriter) UpdateEmail(user_id int64, email string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", user_id, email)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockWriterMockRecorder) UpdateEmail(user_id, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockWriter)(nil).UpdateEmail), user_id, email)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockWriter) UpdatePassword(user_id int64, passwordHash string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", user_id, passwordHash)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockWriterMockRecorder) UpdatePassword(user_id, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockWriter)(nil).UpdatePassword), user_id, passwordHash)
+}
+
+// UpdateUsername mocks base method.
+func (m *MockWriter) UpdateUsername(user_id int64, username string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUsername", user_id, username)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdateUsername indicates an expected call of UpdateUsername.
+func (mr *MockWriterMockRecorder) UpdateUsername(user_id, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsername", reflect.TypeOf((*MockWriter)(nil).UpdateUsername), user_id, username)
+}
+
+// UpdateWithLastLoginAt mocks base method.
+func (m *MockWriter) UpdateWithLastLoginAt(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithLastLoginAt", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdateWithLastLoginAt indicates an expected call of UpdateWithLastLoginAt.
+func (mr *MockWriterMockRecorder) UpdateWithLastLoginAt(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithLastLoginAt", reflect.TypeOf((*MockWriter)(nil).UpdateWithLastLoginAt), user_id)
+}
+
+// MockRepository is a mock of Repository interface.
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository.
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance.
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddRole mocks base method.
+func (m *MockRepository) AddRole(user_id int64, role string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRole", user_id, role)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// AddRole indicates an expected call of AddRole.
+func (mr *MockRepositoryMockRecorder) AddRole(user_id, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRole", reflect.TypeOf((*MockRepository)(nil).AddRole), user_id, role)
+}
+
+// Create mocks base method.
+func (m *MockRepository) Create(username, email, passwordHash, role string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", username, email, passwordHash, role)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRepositoryMockRecorder) Create(username, email, passwordHash, role any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), username, email, passwordHash, role)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockRepository) CreatePasswordResetToken(token *entity.PasswordResetToken) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", token)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockRepositoryMockRecorder) CreatePasswordResetToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockRepository)(nil).CreatePasswordResetToken), token)
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockRepository) CreateRefreshToken(token *entity.RefreshToken) *entity.AppError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", token)
+	ret0, _ := ret[0].(*entity.AppError)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockRepositoryMockRecorder) CreateRefreshToken(token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockRepository)(nil).CreateRefreshToken), token)
+}
+
+// Delete mocks base method.
+func (m *MockRepository) Delete(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockRepositoryMockRecorder) Delete(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), user_id)
+}
+
+// FindByEmail mocks base method.
+func (m *MockRepository) FindByEmail(email string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByEmail", email)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByEmail indicates an expected call of FindByEmail.
+func (mr *MockRepositoryMockRecorder) FindByEmail(email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByEmail", reflect.TypeOf((*MockRepository)(nil).FindByEmail), email)
+}
+
+// FindByID mocks base method.
+func (m *MockRepository) FindByID(user_id int64) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByID", user_id)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByID indicates an expected call of FindByID.
+func (mr *MockRepositoryMockRecorder) FindByID(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByID", reflect.TypeOf((*MockRepository)(nil).FindByID), user_id)
+}
+
+// FindByIDAndReturnPassword mocks base method.
+func (m *MockRepository) FindByIDAndReturnPassword(user_id int64) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByIDAndReturnPassword", user_id)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByIDAndReturnPassword indicates an expected call of FindByIDAndReturnPassword.
+func (mr *MockRepositoryMockRecorder) FindByIDAndReturnPassword(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByIDAndReturnPassword", reflect.TypeOf((*MockRepository)(nil).FindByIDAndReturnPassword), user_id)
+}
+
+// FindByUsername mocks base method.
+func (m *MockRepository) FindByUsername(username string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUsername", username)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByUsername indicates an expected call of FindByUsername.
+func (mr *MockRepositoryMockRecorder) FindByUsername(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUsername", reflect.TypeOf((*MockRepository)(nil).FindByUsername), username)
+}
+
+// FindByUsernameAndReturnPassword mocks base method.
+func (m *MockRepository) FindByUsernameAndReturnPassword(username string) (*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUsernameAndReturnPassword", username)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindByUsernameAndReturnPassword indicates an expected call of FindByUsernameAndReturnPassword.
+func (mr *MockRepositoryMockRecorder) FindByUsernameAndReturnPassword(username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUsernameAndReturnPassword", reflect.TypeOf((*MockRepository)(nil).FindByUsernameAndReturnPassword), username)
+}
+
+// FindPasswordResetTokenByHash mocks base method.
+func (m *MockRepository) FindPasswordResetTokenByHash(tokenHash string) (*entity.PasswordResetToken, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPasswordResetTokenByHash", tokenHash)
+	ret0, _ := ret[0].(*entity.PasswordResetToken)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindPasswordResetTokenByHash indicates an expected call of FindPasswordResetTokenByHash.
+func (mr *MockRepositoryMockRecorder) FindPasswordResetTokenByHash(tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPasswordResetTokenByHash", reflect.TypeOf((*MockRepository)(nil).FindPasswordResetTokenByHash), tokenHash)
+}
+
+// FindRefreshTokenByHash mocks base method.
+func (m *MockRepository) FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRefreshTokenByHash", tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindRefreshTokenByHash indicates an expected call of FindRefreshTokenByHash.
+func (mr *MockRepositoryMockRecorder) FindRefreshTokenByHash(tokenHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRefreshTokenByHash", reflect.TypeOf((*MockRepository)(nil).FindRefreshTokenByHash), tokenHash)
+}
+
+// FindRolesByUserID mocks base method.
+func (m *MockRepository) FindRolesByUserID(user_id int64) ([]string, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRolesByUserID", user_id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// FindRolesByUserID indicates an expected call of FindRolesByUserID.
+func (mr *MockRepositoryMockRecorder) FindRolesByUserID(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRolesByUserID", reflect.TypeOf((*MockRepository)(nil).FindRolesByUserID), user_id)
+}
+
+// InvalidatePasswordResetTokens mocks base method.
+func (m *MockRepository) InvalidatePasswordResetTokens(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidatePasswordResetTokens", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// InvalidatePasswordResetTokens indicates an expected call of InvalidatePasswordResetTokens.
+func (mr *MockRepositoryMockRecorder) InvalidatePasswordResetTokens(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidatePasswordResetTokens", reflect.TypeOf((*MockRepository)(nil).InvalidatePasswordResetTokens), user_id)
+}
+
+// List mocks base method.
+func (m *MockRepository) List(query *entity.UserListQuery) ([]*entity.User, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", query)
+	ret0, _ := ret[0].([]*entity.User)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockRepositoryMockRecorder) List(query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepository)(nil).List), query)
+}
+
+// MarkPasswordResetTokenUsed mocks base method.
+func (m *MockRepository) MarkPasswordResetTokenUsed(token_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPasswordResetTokenUsed", token_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// MarkPasswordResetTokenUsed indicates an expected call of MarkPasswordResetTokenUsed.
+func (mr *MockRepositoryMockRecorder) MarkPasswordResetTokenUsed(token_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPasswordResetTokenUsed", reflect.TypeOf((*MockRepository)(nil).MarkPasswordResetTokenUsed), token_id)
+}
+
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockRepository) MarkRefreshTokenUsed(token_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", token_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockRepositoryMockRecorder) MarkRefreshTokenUsed(token_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockRepository)(nil).MarkRefreshTokenUsed), token_id)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockRepository) RevokeRefreshTokenFamily(family_id string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", family_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockRepositoryMockRecorder) RevokeRefreshTokenFamily(family_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockRepository)(nil).RevokeRefreshTokenFamily), family_id)
+}
+
+// RevokeRefreshTokensByUserID mocks base method.
+func (m *MockRepository) RevokeRefreshTokensByUserID(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokensByUserID", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// RevokeRefreshTokensByUserID indicates an expected call of RevokeRefreshTokensByUserID.
+func (mr *MockRepositoryMockRecorder) RevokeRefreshTokensByUserID(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokensByUserID", reflect.TypeOf((*MockRepository)(nil).RevokeRefreshTokensByUserID), user_id)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockRepository) UpdateEmail(user_id int64, email string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", user_id, email)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockRepositoryMockRecorder) UpdateEmail(user_id, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockRepository)(nil).UpdateEmail), user_id, email)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockRepository) UpdatePassword(user_id int64, passwordHash string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", user_id, passwordHash)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockRepositoryMockRecorder) UpdatePassword(user_id, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepository)(nil).UpdatePassword), user_id, passwordHash)
+}
+
+// UpdateUsername mocks base method.
+func (m *MockRepository) UpdateUsername(user_id int64, username string) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUsername", user_id, username)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdateUsername indicates an expected call of UpdateUsername.
+func (mr *MockRepositoryMockRecorder) UpdateUsername(user_id, username any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUsername", reflect.TypeOf((*MockRepository)(nil).UpdateUsername), user_id, username)
+}
+
+// UpdateWithLastLoginAt mocks base method.
+func (m *MockRepository) UpdateWithLastLoginAt(user_id int64) (sql.Result, *entity.AppError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWithLastLoginAt", user_id)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(*entity.AppError)
+	return ret0, ret1
+}
+
+// UpdateWithLastLoginAt indicates an expected call of UpdateWithLastLoginAt.
+func (mr *MockRepositoryMockRecorder) UpdateWithLastLoginAt(user_id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWithLastLoginAt", reflect.TypeOf((*MockRepository)(nil).UpdateWithLastLoginAt), user_id)
+}
@@ -0,0 +1,62 @@
+package quiz
+
+import (
+	"math/rand"
+	"quiz-app/pkg/entity"
+	"sort"
+)
+
+// Draw picks the questions of the draw rules from the bank, in the order of the rules. The
+// selection only depends on the seed and the bank, so that it can be reproduced: the bank is
+// sorted by id and every rule draws from the questions that earlier rules left. It fails with
+// ErrNotEnoughQuestions when a rule has fewer questions left to draw from than its count.
+func Draw(rules []*entity.DrawRule, bank []*entity.Question, seed int64) ([]*entity.Question, *entity.AppError) {
+
+	sorted := make([]*entity.Question, len(bank))
+	copy(sorted, bank)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	rng := rand.New(rand.NewSource(seed))
+	drawn := make([]*entity.Question, 0)
+	picked := make(map[int64]bool)
+
+	for _, rule := range rules {
+		candidates := make([]*entity.Question, 0)
+		for _, question := range sorted {
+			if !picked[question.Id] && matchesRule(rule, question) {
+				candidates = append(candidates, question)
+			}
+		}
+
+		if len(candidates) < rule.Count {
+			return nil, entity.ErrNotEnoughQuestions
+		}
+
+		for _, i := range rng.Perm(len(candidates))[:rule.Count] {
+			picked[candidates[i].Id] = true
+			drawn = append(drawn, candidates[i])
+		}
+	}
+
+	return drawn, nil
+}
+
+func matchesRule(rule *entity.DrawRule, question *entity.Question) bool {
+	if rule.Difficulty != "" && rule.Difficulty != question.Difficulty {
+		return false
+	}
+
+	if rule.Tag == "" {
+		return true
+	}
+
+	for _, tag := range question.Tags {
+		if tag == rule.Tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package quiz
+
+import (
+	"database/sql"
+	"quiz-app/pkg/entity"
+)
+
+type Reader interface {
+	FindByID(quiz_id int64) (*entity.Quiz, *entity.AppError)
+	List(query *entity.QuizListQuery) ([]*entity.Quiz, *entity.AppError)
+	FindQuestionsByQuizID(quiz_id int64) ([]*entity.Question, *entity.AppError)
+	FindQuestionByID(question_id int64) (*entity.Question, *entity.AppError)
+	FindBankQuestions(query *entity.BankQuery) ([]*entity.Question, *entity.AppError)
+	FindDrawRules(quiz_id int64) ([]*entity.DrawRule, *entity.AppError)
+}
+
+type Writer interface {
+	Create(quiz *entity.Quiz) *entity.AppError
+	Update(quiz *entity.Quiz) (sql.Result, *entity.AppError)
+	Delete(quiz_id int64) (sql.Result, *entity.AppError)
+	CreateQuestion(question *entity.Question) *entity.AppError
+	UpdateQuestion(question *entity.Question) *entity.AppError
+	CreateBankQuestion(question *entity.Question) *entity.AppError
+	SetDrawRules(quiz_id int64, rules []*entity.DrawRule) *entity.AppError
+	DeleteQuestion(question_id int64) (sql.Result, *entity.AppError)
+}
+
+// Repository interface
+type Repository interface {
+	Reader
+	Writer
+}
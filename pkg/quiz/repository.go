@@ -0,0 +1,526 @@
+package quiz
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/lib/pq"
+	"math/rand"
+	"quiz-app/pkg/entity"
+	"time"
+)
+
+type PGRepository struct {
+	pool *sql.DB
+}
+
+func InitRepo(p *sql.DB) *PGRepository {
+	return &PGRepository{
+		pool: p,
+	}
+}
+
+func (r PGRepository) FindByID(quizId int64) (*entity.Quiz, *entity.AppError) {
+	var quiz entity.Quiz
+
+	query := "select id, owner_id, title, description, time_limit_seconds, created_at, updated_at from quizzes where id=$1"
+	err := r.pool.QueryRow(query, quizId).Scan(&quiz.Id, &quiz.OwnerId, &quiz.Title, &quiz.Description, &quiz.TimeLimitSeconds, &quiz.CreatedAt, &quiz.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return &quiz, nil
+}
+
+// List returns up to query.Limit quizzes with an id above query.AfterId, optionally of one owner.
+func (r PGRepository) List(query *entity.QuizListQuery) ([]*entity.Quiz, *entity.AppError) {
+	sqlQuery := "select id, owner_id, title, description, time_limit_seconds, created_at, updated_at from quizzes " +
+		"where id > $1 and ($2 = 0 or owner_id = $2) order by id limit $3"
+
+	rows, err := r.pool.Query(sqlQuery, query.AfterId, query.OwnerId, query.Limit)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	quizzes := make([]*entity.Quiz, 0)
+	for rows.Next() {
+		var quiz entity.Quiz
+		if err := rows.Scan(&quiz.Id, &quiz.OwnerId, &quiz.Title, &quiz.Description, &quiz.TimeLimitSeconds, &quiz.CreatedAt, &quiz.UpdatedAt); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+		quizzes = append(quizzes, &quiz)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return quizzes, nil
+}
+
+// questionSelect joins the questions with their options, for findQuestions.
+const questionSelect = "select q.id, q.quiz_id, q.owner_id, q.position, q.type, q.text, q.time_limit_seconds, q.answer, q.tags, q.difficulty, " +
+	"o.id, o.position, o.text, o.is_correct, o.match from questions q left join answer_options o on o.question_id = q.id "
+
+// FindQuestionsByQuizID returns the questions of the quiz with their options, both in position order.
+func (r PGRepository) FindQuestionsByQuizID(quizId int64) ([]*entity.Question, *entity.AppError) {
+	query := questionSelect +
+		"where q.quiz_id=$1 order by q.position, q.id, o.position, o.id"
+
+	return r.findQuestions(query, quizId)
+}
+
+func (r PGRepository) FindQuestionByID(questionId int64) (*entity.Question, *entity.AppError) {
+	query := questionSelect +
+		"where q.id=$1 order by o.position, o.id"
+
+	questions, err := r.findQuestions(query, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(questions) == 0 {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	return questions[0], nil
+}
+
+// FindBankQuestions returns the questions in the bank of the owner by ascending id, optionally
+// only those with a tag and/or a difficulty.
+func (r PGRepository) FindBankQuestions(query *entity.BankQuery) ([]*entity.Question, *entity.AppError) {
+	sqlQuery := questionSelect +
+		"where q.quiz_id is null and q.owner_id=$1 and ($2 = '' or q.tags @> jsonb_build_array($2::text)) and ($3 = '' or q.difficulty=$3) " +
+		"order by q.id, o.position, o.id"
+
+	return r.findQuestions(sqlQuery, query.OwnerId, query.Tag, query.Difficulty)
+}
+
+func (r PGRepository) FindDrawRules(quizId int64) ([]*entity.DrawRule, *entity.AppError) {
+	query := "select tag, difficulty, count from quiz_draw_rules where quiz_id=$1 order by position"
+
+	rows, err := r.pool.Query(query, quizId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	rules := make([]*entity.DrawRule, 0)
+	for rows.Next() {
+		var rule entity.DrawRule
+		if err := rows.Scan(&rule.Tag, &rule.Difficulty, &rule.Count); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return rules, nil
+}
+
+// findQuestions groups the rows of a question/option join, ordered by question, into questions.
+func (r PGRepository) findQuestions(query string, args ...interface{}) ([]*entity.Question, *entity.AppError) {
+	rows, err := r.pool.Query(query, args...)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	questions := make([]*entity.Question, 0)
+	var question *entity.Question
+	for rows.Next() {
+		var q entity.Question
+		var quizId sql.NullInt64
+		var ownerId sql.NullInt64
+		var answer []byte
+		var tags []byte
+		var optionId sql.NullInt64
+		var optionPosition sql.NullInt64
+		var optionText sql.NullString
+		var isCorrect sql.NullBool
+		var match sql.NullString
+
+		if err := rows.Scan(&q.Id, &quizId, &ownerId, &q.Position, &q.Type, &q.Text, &q.TimeLimitSeconds, &answer, &tags, &q.Difficulty,
+			&optionId, &optionPosition, &optionText, &isCorrect, &match); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		if question == nil || question.Id != q.Id {
+			q.QuizId = quizId.Int64
+			q.OwnerId = ownerId.Int64
+			if err := json.Unmarshal(tags, &q.Tags); err != nil {
+				return nil, entity.NewAppError(err)
+			}
+
+			if answer != nil {
+				if err := json.Unmarshal(answer, &q.Answer); err != nil {
+					return nil, entity.NewAppError(err)
+				}
+			}
+
+			question = &q
+			question.Options = make([]*entity.AnswerOption, 0)
+			questions = append(questions, question)
+		}
+
+		if optionId.Valid {
+			question.Options = append(question.Options, &entity.AnswerOption{
+				Id:         optionId.Int64,
+				QuestionId: q.Id,
+				Position:   int(optionPosition.Int64),
+				Text:       optionText.String,
+				IsCorrect:  isCorrect.Bool,
+				Match:      match.String,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return questions, nil
+}
+
+// Create stores the quiz with its questions and options in one transaction and fills in their ids.
+func (r PGRepository) Create(quiz *entity.Quiz) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	query := "insert into quizzes (owner_id, title, description, time_limit_seconds, created_at, updated_at) values ($1, $2, $3, $4, $5, $5) returning id, created_at, updated_at"
+	now := time.Now().UTC()
+	if err := tx.QueryRow(query, quiz.OwnerId, quiz.Title, quiz.Description, quiz.TimeLimitSeconds, now).Scan(&quiz.Id, &quiz.CreatedAt, &quiz.UpdatedAt); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	for i, question := range quiz.Questions {
+		question.QuizId = quiz.Id
+		question.Position = i + 1
+
+		if err := insertQuestion(tx, question); err != nil {
+			return err
+		}
+	}
+
+	if err := insertDrawRules(tx, quiz.Id, quiz.DrawRules); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+func (r PGRepository) Update(quiz *entity.Quiz) (sql.Result, *entity.AppError) {
+
+	query := "update quizzes set title=$1, description=$2, time_limit_seconds=$3, updated_at=$4 where id=$5"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, quiz.Title, quiz.Description, quiz.TimeLimitSeconds, now, quiz.Id)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) Delete(quizId int64) (sql.Result, *entity.AppError) {
+
+	query := "delete from quizzes where id=$1"
+
+	res, err := r.pool.Exec(query, quizId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+// CreateQuestion appends the question with its options to the end of its quiz.
+func (r PGRepository) CreateQuestion(question *entity.Question) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	// lock the quiz so that concurrent appends do not get the same position:
+	var quizId int64
+	err = tx.QueryRow("select id from quizzes where id=$1 for update", question.QuizId).Scan(&quizId)
+
+	if err == sql.ErrNoRows {
+		return entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+
+	query := "select coalesce(max(position), 0) + 1 from questions where quiz_id=$1"
+	if err := tx.QueryRow(query, quizId).Scan(&question.Position); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	if err := insertQuestion(tx, question); err != nil {
+		return err
+	}
+
+	if err := touchQuiz(tx, question.QuizId); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+// CreateBankQuestion stores the question with its options in the question bank of its owner.
+func (r PGRepository) CreateBankQuestion(question *entity.Question) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	if err := insertQuestion(tx, question); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+// SetDrawRules replaces the draw rules of the quiz.
+func (r PGRepository) SetDrawRules(quizId int64, rules []*entity.DrawRule) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("delete from quiz_draw_rules where quiz_id=$1", quizId); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	if err := insertDrawRules(tx, quizId, rules); err != nil {
+		return err
+	}
+
+	if err := touchQuiz(tx, quizId); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+// UpdateQuestion changes the type, text, time limit, answer, tags and difficulty of the question
+// and its options, see updateOptions. Questions of the bank have no QuizId.
+func (r PGRepository) UpdateQuestion(question *entity.Question) *entity.AppError {
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	answer, answerErr := marshalAnswer(question)
+	if answerErr != nil {
+		return answerErr
+	}
+
+	tags, tagsErr := json.Marshal(question.Tags)
+	if tagsErr != nil {
+		return entity.NewAppError(tagsErr)
+	}
+
+	query := "update questions set type=$1, text=$2, time_limit_seconds=$3, answer=$4::jsonb, tags=$5::jsonb, difficulty=$6 " +
+		"where id=$7 and quiz_id is not distinct from $8 returning position"
+	err = tx.QueryRow(query, question.Type, question.Text, question.TimeLimitSeconds, answer, string(tags), question.Difficulty,
+		question.Id, nullableID(question.QuizId)).Scan(&question.Position)
+
+	if err == sql.ErrNoRows {
+		return entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+
+	if err := updateOptions(tx, question); err != nil {
+		return err
+	}
+
+	if question.QuizId != 0 {
+		if err := touchQuiz(tx, question.QuizId); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+func (r PGRepository) DeleteQuestion(questionId int64) (sql.Result, *entity.AppError) {
+
+	query := "delete from questions where id=$1"
+
+	res, err := r.pool.Exec(query, questionId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func insertQuestion(tx *sql.Tx, question *entity.Question) *entity.AppError {
+	answer, err := marshalAnswer(question)
+	if err != nil {
+		return err
+	}
+
+	tags, tagsErr := json.Marshal(question.Tags)
+	if tagsErr != nil {
+		return entity.NewAppError(tagsErr)
+	}
+
+	query := "insert into questions (quiz_id, owner_id, position, type, text, time_limit_seconds, answer, tags, difficulty) " +
+		"values ($1, $2, $3, $4, $5, $6, $7::jsonb, $8::jsonb, $9) returning id"
+	if err := tx.QueryRow(query, nullableID(question.QuizId), nullableID(question.OwnerId), question.Position, question.Type, question.Text,
+		question.TimeLimitSeconds, answer, string(tags), question.Difficulty).Scan(&question.Id); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return insertOptions(tx, question)
+}
+
+// insertOptions stores the options in random order, so that their ids do not give away the
+// order of an ordering question.
+func insertOptions(tx *sql.Tx, question *entity.Question) *entity.AppError {
+	for i, option := range question.Options {
+		option.QuestionId = question.Id
+		option.Position = i + 1
+	}
+
+	return insertShuffled(tx, question.Options)
+}
+
+// updateOptions updates the options of the question that keep the id of one of its options in
+// place, so that the answers given to them, by attempts in progress as well as submitted ones,
+// still point at the same options. Options without such an id are added, and the options that
+// are left out are deleted.
+func updateOptions(tx *sql.Tx, question *entity.Question) *entity.AppError {
+	query := "update answer_options set position=$1, text=$2, is_correct=$3, match=$4 where id=$5 and question_id=$6"
+	kept := make([]int64, 0, len(question.Options))
+	added := make([]*entity.AnswerOption, 0)
+	seen := make(map[int64]bool)
+
+	for i, option := range question.Options {
+		option.QuestionId = question.Id
+		option.Position = i + 1
+
+		if option.Id == 0 || seen[option.Id] {
+			added = append(added, option)
+			continue
+		}
+		seen[option.Id] = true
+
+		res, err := tx.Exec(query, option.Position, option.Text, option.IsCorrect, option.Match, option.Id, option.QuestionId)
+		if err != nil {
+			return entity.NewAppError(err)
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return entity.NewAppError(err)
+		}
+
+		// an id of another question does not take over its option:
+		if rows == 0 {
+			added = append(added, option)
+			continue
+		}
+
+		kept = append(kept, option.Id)
+	}
+
+	if _, err := tx.Exec("delete from answer_options where question_id=$1 and not (id = any($2))", question.Id, pq.Array(kept)); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return insertShuffled(tx, added)
+}
+
+// insertShuffled inserts the options, whose positions are set, in random order.
+func insertShuffled(tx *sql.Tx, options []*entity.AnswerOption) *entity.AppError {
+	query := "insert into answer_options (question_id, position, text, is_correct, match) values ($1, $2, $3, $4, $5) returning id"
+	for _, i := range rand.Perm(len(options)) {
+		option := options[i]
+		if err := tx.QueryRow(query, option.QuestionId, option.Position, option.Text, option.IsCorrect, option.Match).Scan(&option.Id); err != nil {
+			return entity.NewAppError(err)
+		}
+	}
+
+	return nil
+}
+
+func insertDrawRules(tx *sql.Tx, quizId int64, rules []*entity.DrawRule) *entity.AppError {
+	query := "insert into quiz_draw_rules (quiz_id, position, tag, difficulty, count) values ($1, $2, $3, $4, $5)"
+	for i, rule := range rules {
+		if _, err := tx.Exec(query, quizId, i+1, rule.Tag, rule.Difficulty, rule.Count); err != nil {
+			return entity.NewAppError(err)
+		}
+	}
+
+	return nil
+}
+
+// nullableID stores the zero id as null.
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// marshalAnswer returns the answer of the question as json, or nil for questions without one.
+func marshalAnswer(question *entity.Question) (interface{}, *entity.AppError) {
+	if question.Answer == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(question.Answer)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return string(b), nil
+}
+
+func touchQuiz(tx *sql.Tx, quizId int64) *entity.AppError {
+	if _, err := tx.Exec("update quizzes set updated_at=$1 where id=$2", time.Now().UTC(), quizId); err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,528 @@
+package quiz
+
+import (
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/grader"
+	"quiz-app/pkg/transfer"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxTitleLength = 200
+	maxTimeLimit   = 24 * 60 * 60
+
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	maxTags      = 20
+	maxTagLength = 50
+	maxDrawRules = 20
+	maxDrawCount = 100
+)
+
+type Service struct {
+	repo Repository
+}
+
+// QuizUpdate holds the changes the owner makes to a quiz. Fields left nil are not changed.
+type QuizUpdate struct {
+	Title            *string `json:"title"`
+	Description      *string `json:"description"`
+	TimeLimitSeconds *int    `json:"timeLimitSeconds"`
+}
+
+func InitService(r Repository) *Service {
+	return &Service{
+		repo: r,
+	}
+}
+
+// CreateQuiz stores a new quiz of the owner together with its questions.
+func (s *Service) CreateQuiz(ownerId int64, quiz *entity.Quiz) (*entity.Quiz, *entity.AppError) {
+
+	quiz.Title = strings.TrimSpace(quiz.Title)
+	if err := validateTitle(quiz.Title); err != nil {
+		return nil, err
+	}
+
+	if err := validateTimeLimit(quiz.TimeLimitSeconds); err != nil {
+		return nil, err
+	}
+
+	for _, question := range quiz.Questions {
+		if err := validateQuestion(question); err != nil {
+			return nil, err
+		}
+		question.OwnerId = 0
+	}
+
+	if err := validateDrawRules(quiz.DrawRules); err != nil {
+		return nil, err
+	}
+
+	quiz.Id = 0
+	quiz.OwnerId = ownerId
+	if quiz.Questions == nil {
+		quiz.Questions = make([]*entity.Question, 0)
+	}
+
+	if err := s.repo.Create(quiz); err != nil {
+		return nil, err
+	}
+
+	return quiz, nil
+}
+
+// GetQuiz returns the quiz with its questions and draw rules. Only the owner gets to see the
+// correct answers and the timed questions, which other users open through their attempt.
+func (s *Service) GetQuiz(quizId int64, callerId int64) (*entity.Quiz, *entity.AppError) {
+
+	quiz, err := s.findQuiz(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz.OwnerId != callerId {
+		return quiz.WithoutAnswers(), nil
+	}
+
+	return quiz, nil
+}
+
+// ImportQuiz reads a quiz from a file of the format and stores it for the owner. A title, when
+// set, replaces the title in the file, which GIFT files from Moodle do not have. The problems
+// with the file and its questions are reported together, at their lines in the file.
+func (s *Service) ImportQuiz(ownerId int64, format string, data []byte, title string) (*entity.Quiz, *entity.AppError) {
+
+	imported, err := transfer.Decode(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	quiz := imported.Quiz
+	if title = strings.TrimSpace(title); title != "" {
+		quiz.Title = title
+	}
+
+	issues := make([]entity.ImportIssue, 0)
+	quiz.Title = strings.TrimSpace(quiz.Title)
+	if err := validateTitle(quiz.Title); err != nil {
+		issues = append(issues, entity.ImportIssue{Message: err.Error()})
+	} else if err := validateTimeLimit(quiz.TimeLimitSeconds); err != nil {
+		issues = append(issues, entity.ImportIssue{Message: err.Error()})
+	}
+
+	for i, question := range quiz.Questions {
+		if err := validateQuestion(question); err != nil {
+			issues = append(issues, entity.ImportIssue{Line: imported.QuestionLines[i], Message: err.Error()})
+		}
+	}
+
+	if err := validateDrawRules(quiz.DrawRules); err != nil {
+		issues = append(issues, entity.ImportIssue{Message: err.Error()})
+	}
+
+	if len(issues) > 0 {
+		return nil, entity.NewImportError(issues)
+	}
+
+	return s.CreateQuiz(ownerId, quiz)
+}
+
+// ExportQuiz writes the quiz with its answers as a file of the format.
+func (s *Service) ExportQuiz(quizId int64, format string) ([]byte, *entity.AppError) {
+
+	if _, err := transfer.For(format); err != nil {
+		return nil, err
+	}
+
+	quiz, err := s.findQuiz(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer.Encode(format, quiz)
+}
+
+// GetOwnerID returns the id of the user that owns the quiz.
+func (s *Service) GetOwnerID(quizId int64) (int64, *entity.AppError) {
+	quiz, err := s.repo.FindByID(quizId)
+	if err != nil {
+		return 0, err
+	}
+
+	return quiz.OwnerId, nil
+}
+
+// ListQuizzes returns one page of quizzes without their questions.
+func (s *Service) ListQuizzes(query *entity.QuizListQuery) (*entity.QuizListPage, *entity.AppError) {
+
+	if query.Limit <= 0 {
+		query.Limit = defaultListLimit
+	}
+
+	if query.Limit > maxListLimit {
+		query.Limit = maxListLimit
+	}
+
+	// fetch one extra quiz to know whether there is a next page:
+	limit := query.Limit
+	query.Limit = limit + 1
+	quizzes, err := s.repo.List(query)
+	query.Limit = limit
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.QuizListPage{Quizzes: quizzes}
+	if len(quizzes) > limit {
+		page.Quizzes = quizzes[:limit]
+		page.NextId = page.Quizzes[limit-1].Id
+	}
+
+	return page, nil
+}
+
+// UpdateQuiz changes the title, description and/or time limit of the quiz. A new time limit
+// applies to attempts started afterwards.
+func (s *Service) UpdateQuiz(quizId int64, update *QuizUpdate) (*entity.Quiz, *entity.AppError) {
+
+	quiz, err := s.repo.FindByID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Title != nil {
+		quiz.Title = strings.TrimSpace(*update.Title)
+		if err := validateTitle(quiz.Title); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Description != nil {
+		quiz.Description = *update.Description
+	}
+
+	if update.TimeLimitSeconds != nil {
+		quiz.TimeLimitSeconds = *update.TimeLimitSeconds
+		if err := validateTimeLimit(quiz.TimeLimitSeconds); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.repo.Update(quiz); err != nil {
+		return nil, err
+	}
+
+	return s.GetQuiz(quizId, quiz.OwnerId)
+}
+
+// SetDrawRules replaces the rules that pick questions from the question bank of the owner for
+// every attempt at the quiz. Attempts that have already started keep their questions.
+func (s *Service) SetDrawRules(quizId int64, rules []*entity.DrawRule) (*entity.Quiz, *entity.AppError) {
+
+	if err := validateDrawRules(rules); err != nil {
+		return nil, err
+	}
+
+	quiz, err := s.repo.FindByID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetDrawRules(quizId, rules); err != nil {
+		return nil, err
+	}
+
+	return s.GetQuiz(quizId, quiz.OwnerId)
+}
+
+// DeleteQuiz deletes the quiz with its questions.
+func (s *Service) DeleteQuiz(quizId int64) *entity.AppError {
+	res, err := s.repo.Delete(quizId)
+	if err != nil {
+		return err
+	}
+
+	if rows, rowsErr := res.RowsAffected(); rowsErr == nil && rows == 0 {
+		return entity.ErrEntityNotFound
+	}
+
+	return nil
+}
+
+// AddQuestion appends the question to the end of the quiz.
+func (s *Service) AddQuestion(quizId int64, question *entity.Question) (*entity.Question, *entity.AppError) {
+
+	if err := validateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	question.Id = 0
+	question.QuizId = quizId
+	question.OwnerId = 0
+	if err := s.repo.CreateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// UpdateQuestion replaces the text and options of a question of the quiz. Options that keep
+// their id are changed in place, so that answers already given to them stay valid.
+func (s *Service) UpdateQuestion(quizId int64, questionId int64, question *entity.Question) (*entity.Question, *entity.AppError) {
+
+	if err := validateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	question.Id = questionId
+	question.QuizId = quizId
+	question.OwnerId = 0
+	if err := s.repo.UpdateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// DeleteQuestion removes a question from the quiz.
+func (s *Service) DeleteQuestion(quizId int64, questionId int64) *entity.AppError {
+
+	// make sure the question belongs to the quiz the caller owns:
+	question, err := s.repo.FindQuestionByID(questionId)
+	if err != nil {
+		return err
+	}
+
+	if question.QuizId != quizId {
+		return entity.ErrEntityNotFound
+	}
+
+	if _, err := s.repo.DeleteQuestion(questionId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddBankQuestion stores the question in the question bank of the owner.
+func (s *Service) AddBankQuestion(ownerId int64, question *entity.Question) (*entity.Question, *entity.AppError) {
+
+	if err := validateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	question.Id = 0
+	question.QuizId = 0
+	question.OwnerId = ownerId
+	question.Position = 0
+	if err := s.repo.CreateBankQuestion(question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// ListBankQuestions returns the questions in the question bank of query.OwnerId, optionally
+// only those with query.Tag and/or query.Difficulty.
+func (s *Service) ListBankQuestions(query *entity.BankQuery) ([]*entity.Question, *entity.AppError) {
+	query.Tag = normalizeTag(query.Tag)
+	return s.repo.FindBankQuestions(query)
+}
+
+// GetBankQuestion returns a question of the question bank.
+func (s *Service) GetBankQuestion(questionId int64) (*entity.Question, *entity.AppError) {
+	question, err := s.repo.FindQuestionByID(questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// questions of quizzes are managed through their quiz:
+	if question.QuizId != 0 {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	return question, nil
+}
+
+// GetBankQuestionOwnerID returns the id of the user whose question bank has the question.
+func (s *Service) GetBankQuestionOwnerID(questionId int64) (int64, *entity.AppError) {
+	question, err := s.GetBankQuestion(questionId)
+	if err != nil {
+		return 0, err
+	}
+
+	return question.OwnerId, nil
+}
+
+// UpdateBankQuestion replaces a question of the question bank. Attempts that drew it are graded
+// against the new version from then on.
+func (s *Service) UpdateBankQuestion(questionId int64, question *entity.Question) (*entity.Question, *entity.AppError) {
+
+	if err := validateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	current, err := s.GetBankQuestion(questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	question.Id = questionId
+	question.QuizId = 0
+	question.OwnerId = current.OwnerId
+	if err := s.repo.UpdateQuestion(question); err != nil {
+		return nil, err
+	}
+
+	return question, nil
+}
+
+// DeleteBankQuestion removes a question from the question bank.
+func (s *Service) DeleteBankQuestion(questionId int64) *entity.AppError {
+	if _, err := s.GetBankQuestion(questionId); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.DeleteQuestion(questionId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// findQuiz returns the quiz with its questions, answers and draw rules.
+func (s *Service) findQuiz(quizId int64) (*entity.Quiz, *entity.AppError) {
+
+	quiz, err := s.repo.FindByID(quizId)
+	if err != nil {
+		return nil, err
+	}
+
+	if quiz.Questions, err = s.repo.FindQuestionsByQuizID(quizId); err != nil {
+		return nil, err
+	}
+
+	if quiz.DrawRules, err = s.repo.FindDrawRules(quizId); err != nil {
+		return nil, err
+	}
+
+	return quiz, nil
+}
+
+func validateTitle(title string) *entity.AppError {
+	if title == "" || utf8.RuneCountInString(title) > maxTitleLength {
+		return entity.ErrInvalidQuiz
+	}
+
+	return nil
+}
+
+// validateTimeLimit accepts no limit (0) or one of at most a day.
+func validateTimeLimit(seconds int) *entity.AppError {
+	if seconds < 0 || seconds > maxTimeLimit {
+		return entity.ErrInvalidQuiz
+	}
+
+	return nil
+}
+
+// validateQuestion checks the question with the grader for its type, which defaults to
+// multiple choice.
+func validateQuestion(question *entity.Question) *entity.AppError {
+	if question == nil {
+		return entity.NewInvalidQuestionError("a question is missing")
+	}
+
+	if question.Type == "" {
+		question.Type = entity.QuestionMultipleChoice
+	}
+
+	if question.Options == nil {
+		question.Options = make([]*entity.AnswerOption, 0)
+	}
+	question.Choices = nil
+
+	if question.TimeLimitSeconds < 0 || question.TimeLimitSeconds > maxTimeLimit {
+		return entity.NewInvalidQuestionError("the time limit cannot be negative or longer than a day")
+	}
+
+	if question.Difficulty == "" {
+		question.Difficulty = entity.DifficultyMedium
+	}
+
+	if !isDifficulty(question.Difficulty) {
+		return entity.NewInvalidQuestionError("the difficulty has to be easy, medium or hard")
+	}
+
+	tags, err := normalizeTags(question.Tags)
+	if err != nil {
+		return err
+	}
+	question.Tags = tags
+
+	return grader.Validate(question)
+}
+
+// validateDrawRules checks that every rule draws a sensible number of questions of a known
+// difficulty, and normalizes their tags.
+func validateDrawRules(rules []*entity.DrawRule) *entity.AppError {
+	if len(rules) > maxDrawRules {
+		return entity.ErrInvalidDrawRule
+	}
+
+	for _, rule := range rules {
+		if rule == nil || rule.Count < 1 || rule.Count > maxDrawCount {
+			return entity.ErrInvalidDrawRule
+		}
+
+		if rule.Difficulty != "" && !isDifficulty(rule.Difficulty) {
+			return entity.ErrInvalidDrawRule
+		}
+
+		rule.Tag = normalizeTag(rule.Tag)
+	}
+
+	return nil
+}
+
+func isDifficulty(difficulty string) bool {
+	switch difficulty {
+	case entity.DifficultyEasy, entity.DifficultyMedium, entity.DifficultyHard:
+		return true
+	default:
+		return false
+	}
+}
+
+// normalizeTags trims and lowercases the tags and drops empty and repeated ones.
+func normalizeTags(tags []string) ([]string, *entity.AppError) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, entity.NewInvalidQuestionError("tags cannot be longer than 50 characters")
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTags {
+		return nil, entity.NewInvalidQuestionError("a question cannot have more than 20 tags")
+	}
+
+	return normalized, nil
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
@@ -0,0 +1,334 @@
+package quiz
+
+import (
+	"database/sql/driver"
+	"errors"
+	"go.uber.org/mock/gomock"
+	"quiz-app/pkg/entity"
+	mockQuiz "quiz-app/pkg/mocks/quiz"
+	"testing"
+)
+
+func newTestQuestion(text string) *entity.Question {
+	return &entity.Question{
+		Text: text,
+		Options: []*entity.AnswerOption{
+			{Text: "yes", IsCorrect: true},
+			{Text: "no"},
+		},
+	}
+}
+
+func TestCreateQuiz(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockQuiz.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo)
+
+	t.Run("CreateQuiz should reject a quiz without a title", func(t *testing.T) {
+		q, err := service.CreateQuiz(1, &entity.Quiz{Title: "  "})
+
+		if q != nil || err != entity.ErrInvalidQuiz {
+			t.Fail()
+		}
+	})
+
+	t.Run("CreateQuiz should reject a question without a correct option", func(t *testing.T) {
+		question := newTestQuestion("Is Go compiled?")
+		question.Options[0].IsCorrect = false
+
+		q, err := service.CreateQuiz(1, &entity.Quiz{Title: "Go", Questions: []*entity.Question{question}})
+
+		if q != nil || !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("CreateQuiz should reject a question with a single option", func(t *testing.T) {
+		question := newTestQuestion("Is Go compiled?")
+		question.Options = question.Options[:1]
+
+		q, err := service.CreateQuiz(1, &entity.Quiz{Title: "Go", Questions: []*entity.Question{question}})
+
+		if q != nil || !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("CreateQuiz should reject an unknown question type", func(t *testing.T) {
+		question := newTestQuestion("Is Go compiled?")
+		question.Type = "essay"
+
+		q, err := service.CreateQuiz(1, &entity.Quiz{Title: "Go", Questions: []*entity.Question{question}})
+
+		if q != nil || !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("CreateQuiz should store the quiz for the caller", func(t *testing.T) {
+		mockRepo.EXPECT().Create(gomock.Any()).DoAndReturn(func(q *entity.Quiz) *entity.AppError {
+			q.Id = 7
+			return nil
+		})
+
+		q, err := service.CreateQuiz(1, &entity.Quiz{Id: 99, OwnerId: 2, Title: " Go ", Questions: []*entity.Question{newTestQuestion("Is Go compiled?")}})
+
+		if err != nil || q.Id != 7 || q.OwnerId != 1 || q.Title != "Go" || q.Questions[0].Type != entity.QuestionMultipleChoice {
+			t.Fail()
+		}
+	})
+}
+
+func TestGetQuiz(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockQuiz.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo)
+
+	mockRepo.EXPECT().FindByID(int64(7)).Return(&entity.Quiz{Id: 7, OwnerId: 1, Title: "Go"}, nil).AnyTimes()
+	mockRepo.EXPECT().FindQuestionsByQuizID(int64(7)).DoAndReturn(func(quizId int64) ([]*entity.Question, *entity.AppError) {
+		timed := newTestQuestion("Is Go garbage collected?")
+		timed.TimeLimitSeconds = 30
+		return []*entity.Question{newTestQuestion("Is Go compiled?"), timed}, nil
+	}).AnyTimes()
+	mockRepo.EXPECT().FindDrawRules(int64(7)).Return([]*entity.DrawRule{}, nil).AnyTimes()
+
+	t.Run("GetQuiz should show the correct answers to the owner", func(t *testing.T) {
+		q, err := service.GetQuiz(7, 1)
+
+		if err != nil || !q.Questions[0].Options[0].IsCorrect {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetQuiz should hide the correct answers from other users", func(t *testing.T) {
+		q, err := service.GetQuiz(7, 2)
+
+		if err != nil || len(q.Questions[0].Options) != 2 {
+			t.FailNow()
+		}
+
+		for _, option := range q.Questions[0].Options {
+			if option.IsCorrect {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("GetQuiz should hide timed questions from other users until they are opened", func(t *testing.T) {
+		q, err := service.GetQuiz(7, 2)
+
+		if err != nil || q.Questions[1].Text != "" || len(q.Questions[1].Options) != 0 || q.Questions[1].TimeLimitSeconds != 30 {
+			t.Fail()
+		}
+
+		if owner, err := service.GetQuiz(7, 1); err != nil || owner.Questions[1].Text != "Is Go garbage collected?" {
+			t.Fail()
+		}
+	})
+}
+
+func TestDeleteQuestion(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockQuiz.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo)
+
+	t.Run("DeleteQuestion should not delete a question of another quiz", func(t *testing.T) {
+		mockRepo.EXPECT().FindQuestionByID(int64(3)).Return(&entity.Question{Id: 3, QuizId: 8}, nil)
+
+		if err := service.DeleteQuestion(7, 3); err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("DeleteQuestion should delete a question of the quiz", func(t *testing.T) {
+		mockRepo.EXPECT().FindQuestionByID(int64(3)).Return(&entity.Question{Id: 3, QuizId: 7}, nil)
+		mockRepo.EXPECT().DeleteQuestion(int64(3)).Return(driver.RowsAffected(1), nil)
+
+		if err := service.DeleteQuestion(7, 3); err != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestQuestionBank(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockQuiz.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo)
+
+	t.Run("AddBankQuestion should reject an unknown difficulty", func(t *testing.T) {
+		question := newTestQuestion("Is Go compiled?")
+		question.Difficulty = "impossible"
+
+		q, err := service.AddBankQuestion(1, question)
+
+		if q != nil || !errors.Is(err, entity.ErrInvalidQuestion) {
+			t.Fail()
+		}
+	})
+
+	t.Run("AddBankQuestion should store the question without a quiz and with normalized tags", func(t *testing.T) {
+		mockRepo.EXPECT().CreateBankQuestion(gomock.Any()).Return(nil)
+
+		question := newTestQuestion("Is Go compiled?")
+		question.QuizId = 7
+		question.Tags = []string{" Go ", "go", "", "Compilers"}
+
+		q, err := service.AddBankQuestion(1, question)
+
+		if err != nil || q.QuizId != 0 || q.OwnerId != 1 || q.Difficulty != entity.DifficultyMedium {
+			t.FailNow()
+		}
+
+		if len(q.Tags) != 2 || q.Tags[0] != "go" || q.Tags[1] != "compilers" {
+			t.Fail()
+		}
+	})
+
+	t.Run("GetBankQuestion should not return a question of a quiz", func(t *testing.T) {
+		mockRepo.EXPECT().FindQuestionByID(int64(3)).Return(&entity.Question{Id: 3, QuizId: 7}, nil)
+
+		if q, err := service.GetBankQuestion(3); q != nil || err != entity.ErrEntityNotFound {
+			t.Fail()
+		}
+	})
+
+	t.Run("SetDrawRules should reject a rule without questions to draw", func(t *testing.T) {
+		q, err := service.SetDrawRules(7, []*entity.DrawRule{{Tag: "go", Count: 0}})
+
+		if q != nil || err != entity.ErrInvalidDrawRule {
+			t.Fail()
+		}
+	})
+}
+
+func TestDraw(t *testing.T) {
+
+	bank := make([]*entity.Question, 0)
+	for id := int64(1); id <= 20; id++ {
+		difficulty := entity.DifficultyEasy
+		if id%2 == 0 {
+			difficulty = entity.DifficultyHard
+		}
+
+		tags := []string{"geography"}
+		if id%5 == 0 {
+			tags = append(tags, "capitals")
+		}
+
+		bank = append(bank, &entity.Question{Id: id, Difficulty: difficulty, Tags: tags})
+	}
+
+	rules := []*entity.DrawRule{
+		{Tag: "geography", Difficulty: entity.DifficultyEasy, Count: 5},
+		{Tag: "capitals", Difficulty: entity.DifficultyHard, Count: 2},
+	}
+
+	t.Run("Draw should pick the same questions for the same seed", func(t *testing.T) {
+		first, err := Draw(rules, bank, 7)
+		if err != nil || len(first) != 7 {
+			t.FailNow()
+		}
+
+		// the order of the bank does not matter:
+		reversed := make([]*entity.Question, len(bank))
+		for i, question := range bank {
+			reversed[len(bank)-1-i] = question
+		}
+
+		second, err := Draw(rules, reversed, 7)
+		if err != nil || len(second) != len(first) {
+			t.FailNow()
+		}
+
+		for i := range first {
+			if first[i].Id != second[i].Id {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Draw should pick questions that match their rule", func(t *testing.T) {
+		drawn, _ := Draw(rules, bank, 7)
+
+		for i, question := range drawn {
+			rule := rules[0]
+			if i >= 5 {
+				rule = rules[1]
+			}
+
+			if !matchesRule(rule, question) {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("Draw should not pick a question twice", func(t *testing.T) {
+		overlapping := []*entity.DrawRule{{Tag: "capitals", Count: 4}, {Tag: "geography", Count: 16}}
+		drawn, err := Draw(overlapping, bank, 3)
+
+		if err != nil || len(drawn) != 20 {
+			t.FailNow()
+		}
+
+		seen := make(map[int64]bool)
+		for _, question := range drawn {
+			if seen[question.Id] {
+				t.Fail()
+			}
+			seen[question.Id] = true
+		}
+	})
+
+	t.Run("Draw should fail when a rule has too few questions to draw from", func(t *testing.T) {
+		if _, err := Draw([]*entity.DrawRule{{Tag: "capitals", Count: 5}}, bank, 1); err != entity.ErrNotEnoughQuestions {
+			t.Fail()
+		}
+	})
+}
+
+func TestImportQuiz(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockQuiz.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo)
+
+	t.Run("ImportQuiz should report every invalid question at its line", func(t *testing.T) {
+		data := "title: Go\nquestions:\n  - text: Is Go compiled?\n    options: [{text: yes, isCorrect: true}, {text: no}]\n" +
+			"  - text: Is Go interpreted?\n    options: [{text: yes}]\n  - text: Who made Go?\n    type: essay\n"
+
+		q, err := service.ImportQuiz(1, "yaml", []byte(data), "")
+
+		importErr, ok := err.AppError.(*entity.ImportError)
+		if q != nil || !ok || len(importErr.Issues) != 2 {
+			t.FailNow()
+		}
+
+		if importErr.Issues[0].Line != 5 || importErr.Issues[1].Line != 7 {
+			t.Fail()
+		}
+	})
+
+	t.Run("ImportQuiz should give a quiz without a title the title of the request", func(t *testing.T) {
+		mockRepo.EXPECT().Create(gomock.Any()).Return(nil)
+
+		q, err := service.ImportQuiz(1, "gift", []byte("Is Go compiled? {T}"), "Go")
+
+		if err != nil || q.Title != "Go" || q.OwnerId != 1 || len(q.Questions) != 1 {
+			t.Fail()
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package stream
+
+import (
+	"quiz-app/pkg/entity"
+	"sync"
+	"time"
+)
+
+const (
+	historySize      = 100
+	subscriberBuffer = 16
+
+	// clients reconnect after retryMillis, so a topic outlives its last subscriber a while:
+	defaultRetention = time.Minute
+)
+
+// Broker passes the events of a quiz to its subscribers and keeps the last historySize events
+// of every quiz that is watched, so that subscribers that reconnect can catch up on the ones
+// they missed. A quiz is watched while it has subscribers and for the retention after the last
+// one left; events of other quizzes are not kept.
+type Broker struct {
+	mu        sync.Mutex
+	lastId    int64
+	topics    map[int64]*topic
+	closed    bool
+	now       func() time.Time
+	retention time.Duration
+}
+
+type topic struct {
+	history     []*entity.StreamEvent
+	evictedId   int64
+	subscribers map[*Subscription]bool
+	idleSince   time.Time
+	// states are the states of the last events published with PublishState, by event type:
+	states map[string]string
+}
+
+// Subscription receives the events of a quiz. Its channel is closed when the subscriber falls
+// too far behind or unsubscribes, or when the broker is closed.
+type Subscription struct {
+	quizId int64
+	events chan *entity.StreamEvent
+}
+
+// Events returns the channel the events of the subscription arrive on.
+func (sub *Subscription) Events() <-chan *entity.StreamEvent {
+	return sub.events
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		topics:    make(map[int64]*topic),
+		now:       time.Now,
+		retention: defaultRetention,
+	}
+}
+
+// Watched reports whether the events of the quiz are kept, see Broker.
+func (b *Broker) Watched(quizId int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep()
+	_, ok := b.topics[quizId]
+	return ok
+}
+
+// Publish passes a new event to the subscribers of the quiz and returns it, or returns nil when
+// the quiz is not watched. Subscribers whose buffer is full are dropped rather than holding up
+// the others; they resume from the history.
+func (b *Broker) Publish(quizId int64, eventType string, data interface{}) *entity.StreamEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.publish(quizId, eventType, data)
+}
+
+// PublishState publishes the event like Publish, unless the last event of its type that was
+// published with PublishState for the quiz had the same state.
+func (b *Broker) PublishState(quizId int64, eventType string, data interface{}, state string) *entity.StreamEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[quizId]
+	if !ok {
+		return nil
+	}
+
+	if last, ok := t.states[eventType]; ok && last == state {
+		return nil
+	}
+	t.states[eventType] = state
+
+	return b.publish(quizId, eventType, data)
+}
+
+// publish publishes the event. The caller has to hold b.mu.
+func (b *Broker) publish(quizId int64, eventType string, data interface{}) *entity.StreamEvent {
+	b.sweep()
+
+	t, ok := b.topics[quizId]
+	if !ok {
+		return nil
+	}
+
+	b.lastId++
+	event := &entity.StreamEvent{Id: b.lastId, QuizId: quizId, Type: eventType, Data: data}
+
+	t.history = append(t.history, event)
+	if len(t.history) > historySize {
+		t.evictedId = t.history[0].Id
+		t.history = t.history[1:]
+	}
+
+	for sub := range t.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(t, sub)
+		}
+	}
+
+	return event
+}
+
+// Subscribe subscribes to the events of the quiz. When resuming after the event with the id
+// lastId, it also returns the events since then and whether those are all of them, which they
+// are not when older events are no longer kept or the id is not known.
+func (b *Broker) Subscribe(quizId int64, lastId int64, resume bool) (*Subscription, []*entity.StreamEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweep()
+
+	sub := &Subscription{quizId: quizId, events: make(chan *entity.StreamEvent, subscriberBuffer)}
+	t := b.topic(quizId)
+	if b.closed {
+		close(sub.events)
+	} else {
+		t.subscribers[sub] = true
+		t.idleSince = time.Time{}
+	}
+
+	if !resume {
+		return sub, nil, false
+	}
+
+	missed := make([]*entity.StreamEvent, 0)
+	for _, event := range t.history {
+		if event.Id > lastId {
+			missed = append(missed, event)
+		}
+	}
+
+	return sub, missed, lastId >= t.evictedId && lastId <= b.lastId
+}
+
+// Unsubscribe stops the subscription, unless the broker already dropped it.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if t, ok := b.topics[sub.quizId]; ok && t.subscribers[sub] {
+		b.drop(t, sub)
+	}
+}
+
+// Close ends every subscription, and the ones that are made after it right away, so that the
+// streams end when the server shuts down.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, t := range b.topics {
+		for sub := range t.subscribers {
+			b.drop(t, sub)
+		}
+	}
+}
+
+// topic returns the topic of the quiz. A new topic cannot resume from the events before it. The
+// caller has to hold b.mu.
+func (b *Broker) topic(quizId int64) *topic {
+	t, ok := b.topics[quizId]
+	if !ok {
+		t = &topic{evictedId: b.lastId, subscribers: make(map[*Subscription]bool), states: make(map[string]string)}
+		b.topics[quizId] = t
+	}
+
+	return t
+}
+
+// drop ends the subscription to the topic. The caller has to hold b.mu.
+func (b *Broker) drop(t *topic, sub *Subscription) {
+	delete(t.subscribers, sub)
+	close(sub.events)
+
+	if len(t.subscribers) == 0 {
+		t.idleSince = b.now()
+	}
+}
+
+// sweep drops the topics that had no subscribers for the retention, with their history. The
+// caller has to hold b.mu.
+func (b *Broker) sweep() {
+	now := b.now()
+
+	for quizId, t := range b.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.idleSince) >= b.retention {
+			delete(b.topics, quizId)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package stream
+
+import (
+	"quiz-app/pkg/entity"
+)
+
+// LeaderboardReader ranks the users that took a quiz, see leaderboard.Service.
+type LeaderboardReader interface {
+	GetQuizLeaderboard(quizId int64, window string, limit int, callerId int64) (*entity.Leaderboard, *entity.AppError)
+}
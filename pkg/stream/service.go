@@ -0,0 +1,199 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"quiz-app/pkg/entity"
+	"strconv"
+	"time"
+)
+
+const (
+	boardSize = 10
+
+	defaultHeartbeat = 15 * time.Second
+	writeWait        = 10 * time.Second
+	retryMillis      = 3000
+)
+
+type Service struct {
+	broker       *Broker
+	leaderboards LeaderboardReader
+	heartbeat    time.Duration
+}
+
+// InitService creates the service that streams the events of quizzes to their dashboards.
+// Leaderboards are read through l when an attempt changes them.
+func InitService(b *Broker, l LeaderboardReader) *Service {
+	return &Service{
+		broker:       b,
+		leaderboards: l,
+		heartbeat:    defaultHeartbeat,
+	}
+}
+
+// AttemptSubmitted publishes the submitted attempt and, when the attempt changed it, the
+// all-time leaderboard of its quiz. Attempts at quizzes that nobody watches are skipped, see
+// Broker.
+func (s *Service) AttemptSubmitted(attempt *entity.Attempt) {
+	if !s.broker.Watched(attempt.QuizId) {
+		return
+	}
+
+	submittedAt := attempt.StartedAt
+	if attempt.SubmittedAt != nil {
+		submittedAt = *attempt.SubmittedAt
+	}
+
+	s.broker.Publish(attempt.QuizId, entity.StreamAttemptSubmitted, &entity.AttemptSubmission{
+		AttemptId:   attempt.Id,
+		QuizId:      attempt.QuizId,
+		UserId:      attempt.UserId,
+		Score:       attempt.Score,
+		MaxScore:    attempt.MaxScore,
+		SubmittedAt: submittedAt,
+	})
+
+	board, err := s.leaderboards.GetQuizLeaderboard(attempt.QuizId, entity.LeaderboardAllTime, boardSize, 0)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	entries, jsonErr := json.Marshal(board.Entries)
+	if jsonErr != nil {
+		log.Println(jsonErr)
+		return
+	}
+
+	s.broker.PublishState(attempt.QuizId, entity.StreamLeaderboard, board, string(entries))
+}
+
+// Close ends every stream, for when the server shuts down.
+func (s *Service) Close() {
+	s.broker.Close()
+}
+
+// Serve streams the events of the quiz as server-sent events until the client goes away. A
+// client that sends the id of the last event it received in Last-Event-ID gets the events it
+// missed first; any other client, or one that missed too many, gets the current leaderboard.
+// Browsers only send Last-Event-ID when they reconnect on their own, so a stream opened again
+// may pass the id in the lastEventId query parameter instead. Errors are only returned while
+// nothing has been written yet.
+func (s *Service) Serve(w http.ResponseWriter, r *http.Request, quizId int64) *entity.AppError {
+
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+
+	lastId, idErr := strconv.ParseInt(lastEventId, 10, 64)
+	sub, initial, complete := s.broker.Subscribe(quizId, lastId, idErr == nil)
+	defer s.broker.Unsubscribe(sub)
+
+	if !complete {
+		board, err := s.leaderboards.GetQuizLeaderboard(quizId, entity.LeaderboardAllTime, boardSize, 0)
+		if err != nil {
+			return err
+		}
+
+		initial = append([]*entity.StreamEvent{{QuizId: quizId, Type: entity.StreamLeaderboard, Data: board}}, initial...)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	out := newEventWriter(w)
+	if err := out.retry(retryMillis); err != nil {
+		log.Println(err)
+		return nil
+	}
+
+	for _, event := range initial {
+		if err := out.event(event); err != nil {
+			log.Println(err)
+			return nil
+		}
+	}
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case event, ok := <-sub.Events():
+			// the broker dropped a subscriber that fell behind or is closed, and the client
+			// resumes when it reconnects:
+			if !ok {
+				return nil
+			}
+
+			if err := out.event(event); err != nil {
+				log.Println(err)
+				return nil
+			}
+		case <-heartbeat.C:
+			if err := out.comment("heartbeat"); err != nil {
+				log.Println(err)
+				return nil
+			}
+		}
+	}
+}
+
+// eventWriter writes server-sent events. Every write gets its own deadline, which replaces the
+// WriteTimeout of the server that would otherwise end the stream, and is flushed right away.
+type eventWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newEventWriter(w http.ResponseWriter) *eventWriter {
+	return &eventWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+func (e *eventWriter) event(event *entity.StreamEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	return e.write(formatEvent(event, data))
+}
+
+func (e *eventWriter) comment(text string) error {
+	return e.write(": " + text + "\n\n")
+}
+
+func (e *eventWriter) retry(millis int) error {
+	return e.write("retry: " + strconv.Itoa(millis) + "\n\n")
+}
+
+func (e *eventWriter) write(text string) error {
+	if err := e.rc.SetWriteDeadline(time.Now().Add(writeWait)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	if _, err := e.w.Write([]byte(text)); err != nil {
+		return err
+	}
+
+	return e.rc.Flush()
+}
+
+// formatEvent formats the event with its json data, which has no line breaks. Snapshots have
+// no id, so that they do not change the id a client resumes from.
+func formatEvent(event *entity.StreamEvent, data []byte) string {
+	text := ""
+	if event.Id != 0 {
+		text += "id: " + strconv.FormatInt(event.Id, 10) + "\n"
+	}
+
+	return text + "event: " + event.Type + "\ndata: " + string(data) + "\n\n"
+}
@@ -0,0 +1,325 @@
+package stream
+
+import (
+	"bufio"
+	"go.uber.org/mock/gomock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"quiz-app/pkg/entity"
+	mockStream "quiz-app/pkg/mocks/stream"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBroker(t *testing.T) {
+
+	// watched returns a broker that keeps the events of the quizzes:
+	watched := func(quizIds ...int64) *Broker {
+		broker := NewBroker()
+		for _, quizId := range quizIds {
+			broker.Subscribe(quizId, 0, false)
+		}
+
+		return broker
+	}
+
+	t.Run("Subscribe should return the events after the last id when resuming", func(t *testing.T) {
+		broker := watched(7, 8)
+		first := broker.Publish(7, entity.StreamAttemptSubmitted, nil)
+		broker.Publish(8, entity.StreamAttemptSubmitted, nil)
+		third := broker.Publish(7, entity.StreamLeaderboard, nil)
+
+		_, missed, complete := broker.Subscribe(7, first.Id, true)
+
+		if !complete || len(missed) != 1 || missed[0].Id != third.Id {
+			t.Fail()
+		}
+	})
+
+	t.Run("Subscribe should not resume after events that are no longer kept", func(t *testing.T) {
+		broker := watched(7)
+		first := broker.Publish(7, entity.StreamAttemptSubmitted, nil)
+		for i := 0; i < historySize; i++ {
+			broker.Publish(7, entity.StreamAttemptSubmitted, nil)
+		}
+
+		_, missed, complete := broker.Subscribe(7, first.Id-1, true)
+
+		if complete || len(missed) != historySize {
+			t.Fail()
+		}
+	})
+
+	t.Run("Subscribe should not resume from an id it never gave out", func(t *testing.T) {
+		broker := watched(7)
+		broker.Publish(7, entity.StreamAttemptSubmitted, nil)
+
+		if _, _, complete := broker.Subscribe(7, 42, true); complete {
+			t.Fail()
+		}
+	})
+
+	t.Run("Publish should drop a subscriber that falls behind", func(t *testing.T) {
+		broker := NewBroker()
+		sub, _, _ := broker.Subscribe(7, 0, false)
+
+		for i := 0; i <= subscriberBuffer; i++ {
+			broker.Publish(7, entity.StreamAttemptSubmitted, nil)
+		}
+
+		received := 0
+		for range sub.Events() {
+			received++
+		}
+
+		if received != subscriberBuffer {
+			t.Fail()
+		}
+
+		// unsubscribing a dropped subscriber does nothing:
+		broker.Unsubscribe(sub)
+	})
+
+	t.Run("Publish should not keep the events of a quiz nobody watches", func(t *testing.T) {
+		broker := NewBroker()
+
+		if event := broker.Publish(7, entity.StreamAttemptSubmitted, nil); event != nil || broker.Watched(7) {
+			t.Fail()
+		}
+	})
+
+	t.Run("Unsubscribe should drop the history of a quiz once its retention is over", func(t *testing.T) {
+		now := time.Now()
+		broker := NewBroker()
+		broker.now = func() time.Time { return now }
+
+		sub, _, _ := broker.Subscribe(7, 0, false)
+		first := broker.Publish(7, entity.StreamAttemptSubmitted, nil)
+		broker.Unsubscribe(sub)
+
+		// a client that reconnects right away still resumes:
+		if broker.Publish(7, entity.StreamAttemptSubmitted, nil) == nil || !broker.Watched(7) {
+			t.Fatal()
+		}
+
+		now = now.Add(defaultRetention)
+		if broker.Watched(7) {
+			t.Fatal()
+		}
+
+		if _, missed, complete := broker.Subscribe(7, first.Id, true); complete || len(missed) != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("PublishState should only publish a state that changed", func(t *testing.T) {
+		broker := watched(7)
+
+		first := broker.PublishState(7, entity.StreamLeaderboard, nil, "a")
+		same := broker.PublishState(7, entity.StreamLeaderboard, nil, "a")
+		changed := broker.PublishState(7, entity.StreamLeaderboard, nil, "b")
+
+		if first == nil || same != nil || changed == nil {
+			t.Fail()
+		}
+	})
+
+	t.Run("Close should end every subscription and the ones made after it", func(t *testing.T) {
+		broker := NewBroker()
+		before, _, _ := broker.Subscribe(7, 0, false)
+
+		broker.Close()
+		after, _, _ := broker.Subscribe(8, 0, false)
+
+		for _, sub := range []*Subscription{before, after} {
+			if _, ok := <-sub.Events(); ok {
+				t.Fail()
+			}
+			broker.Unsubscribe(sub)
+		}
+	})
+}
+
+func TestAttemptSubmitted(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockLeaderboards := mockStream.NewMockLeaderboardReader(mockCtrl)
+	broker := NewBroker()
+	service := InitService(broker, mockLeaderboards)
+
+	submittedAt := time.Date(2024, time.May, 15, 13, 30, 0, 0, time.UTC)
+	attempt := &entity.Attempt{Id: 3, QuizId: 7, UserId: 2, Score: 1, MaxScore: 2, SubmittedAt: &submittedAt}
+	board := &entity.Leaderboard{QuizId: 7, Window: entity.LeaderboardAllTime, Entries: []*entity.LeaderboardEntry{{Rank: 1, UserId: 2, Score: 1}}}
+
+	t.Run("AttemptSubmitted should not read the leaderboard of a quiz nobody watches", func(t *testing.T) {
+		service.AttemptSubmitted(attempt)
+
+		if broker.Watched(7) {
+			t.Fail()
+		}
+	})
+
+	t.Run("AttemptSubmitted should publish the attempt and the leaderboard it changed", func(t *testing.T) {
+		sub, _, _ := broker.Subscribe(7, 0, false)
+		defer broker.Unsubscribe(sub)
+
+		mockLeaderboards.EXPECT().GetQuizLeaderboard(int64(7), entity.LeaderboardAllTime, boardSize, int64(0)).Return(board, nil)
+
+		service.AttemptSubmitted(attempt)
+
+		submission := <-sub.Events()
+		if data, ok := submission.Data.(*entity.AttemptSubmission); !ok || data.AttemptId != 3 || !data.SubmittedAt.Equal(submittedAt) {
+			t.Error("submission", submission)
+		}
+
+		if event := <-sub.Events(); event.Type != entity.StreamLeaderboard || event.Id <= submission.Id {
+			t.Error("leaderboard", event)
+		}
+	})
+
+	t.Run("AttemptSubmitted should not publish a leaderboard that did not change", func(t *testing.T) {
+		sub, _, _ := broker.Subscribe(7, 0, false)
+		defer broker.Unsubscribe(sub)
+
+		mockLeaderboards.EXPECT().GetQuizLeaderboard(int64(7), entity.LeaderboardAllTime, boardSize, int64(0)).Return(board, nil)
+
+		service.AttemptSubmitted(attempt)
+
+		if event := <-sub.Events(); event.Type != entity.StreamAttemptSubmitted || len(sub.Events()) != 0 {
+			t.Fail()
+		}
+	})
+}
+
+// readEvent reads the next event of the stream, skipping heartbeats.
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	var event strings.Builder
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unable to read event: [%s]", err)
+		}
+
+		switch {
+		case line == "\n" && event.Len() > 0:
+			return event.String()
+		case line == "\n", strings.HasPrefix(line, ":"):
+			continue
+		default:
+			event.WriteString(line)
+		}
+	}
+}
+
+func TestServe(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockLeaderboards := mockStream.NewMockLeaderboardReader(mockCtrl)
+	broker := NewBroker()
+	service := InitService(broker, mockLeaderboards)
+	service.heartbeat = 20 * time.Millisecond
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := service.Serve(w, r, 7); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	// the stream has to outlive the write timeout of the server:
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	get := func(lastEventId string) (*http.Response, *bufio.Reader) {
+		r, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if lastEventId != "" {
+			r.Header.Set("Last-Event-ID", lastEventId)
+		}
+
+		res, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res, bufio.NewReader(res.Body)
+	}
+
+	t.Run("Serve should start with the leaderboard and stream events after the write timeout", func(t *testing.T) {
+		mockLeaderboards.EXPECT().GetQuizLeaderboard(int64(7), entity.LeaderboardAllTime, boardSize, int64(0)).Return(&entity.Leaderboard{QuizId: 7}, nil)
+
+		res, reader := get("")
+		defer res.Body.Close()
+
+		if res.Header.Get("Content-Type") != "text/event-stream" {
+			t.Fail()
+		}
+
+		if retry := readEvent(t, reader); retry != "retry: 3000\n" {
+			t.Error("retry", retry)
+		}
+
+		if snapshot := readEvent(t, reader); !strings.HasPrefix(snapshot, "event: leaderboard\ndata: {\"quizId\":7") {
+			t.Error("snapshot", snapshot)
+		}
+
+		time.Sleep(300 * time.Millisecond)
+		published := broker.Publish(7, entity.StreamAttemptSubmitted, &entity.AttemptSubmission{AttemptId: 3})
+
+		expected := "id: " + strconv.FormatInt(published.Id, 10) + "\nevent: attempt_submitted\ndata: {\"attemptId\":3,"
+		if event := readEvent(t, reader); !strings.HasPrefix(event, expected) {
+			t.Error("event", event)
+		}
+	})
+
+	t.Run("Serve should resume after the Last-Event-ID", func(t *testing.T) {
+		first := broker.Publish(7, entity.StreamAttemptSubmitted, &entity.AttemptSubmission{AttemptId: 4})
+		second := broker.Publish(7, entity.StreamAttemptSubmitted, &entity.AttemptSubmission{AttemptId: 5})
+
+		res, reader := get(strconv.FormatInt(first.Id, 10))
+		defer res.Body.Close()
+
+		readEvent(t, reader)
+		if event := readEvent(t, reader); !strings.HasPrefix(event, "id: "+strconv.FormatInt(second.Id, 10)+"\n") {
+			t.Error("event", event)
+		}
+	})
+
+	t.Run("Serve should resume after the lastEventId of a stream opened again", func(t *testing.T) {
+		first := broker.Publish(7, entity.StreamAttemptSubmitted, &entity.AttemptSubmission{AttemptId: 6})
+		second := broker.Publish(7, entity.StreamAttemptSubmitted, &entity.AttemptSubmission{AttemptId: 7})
+
+		res, err := http.Get(server.URL + "?lastEventId=" + strconv.FormatInt(first.Id, 10))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		reader := bufio.NewReader(res.Body)
+		readEvent(t, reader)
+		if event := readEvent(t, reader); !strings.HasPrefix(event, "id: "+strconv.FormatInt(second.Id, 10)+"\n") {
+			t.Error("event", event)
+		}
+	})
+
+	t.Run("Serve should end the stream when the service is closed", func(t *testing.T) {
+		mockLeaderboards.EXPECT().GetQuizLeaderboard(int64(7), entity.LeaderboardAllTime, boardSize, int64(0)).Return(&entity.Leaderboard{QuizId: 7}, nil)
+
+		res, reader := get("")
+		defer res.Body.Close()
+
+		readEvent(t, reader)
+		readEvent(t, reader)
+		service.Close()
+
+		if _, err := io.ReadAll(reader); err != nil {
+			t.Fail()
+		}
+	})
+}
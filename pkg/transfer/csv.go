@@ -0,0 +1,364 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"quiz-app/pkg/entity"
+	"strconv"
+	"strings"
+)
+
+type csvFormat struct{}
+
+func init() {
+	Register("csv", csvFormat{})
+}
+
+var csvColumns = []string{"type", "text", "difficulty", "tags", "time_limit_seconds", "options", "correct", "matches",
+	"value", "tolerance", "accepted", "case_sensitive", "max_distance"}
+
+func (csvFormat) ContentType() string {
+	return "text/csv"
+}
+
+func (csvFormat) Decode(data []byte) (*Import, *entity.AppError) {
+	var problems issues
+	quiz := &entity.Quiz{Questions: make([]*entity.Question, 0)}
+
+	body, offset := decodeMetadata(data, "#", quiz, &problems)
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		problems.add(0, "the header row is missing")
+		return nil, problems.err()
+	}
+
+	if err != nil {
+		addCsvIssue(&problems, err, offset)
+		return nil, problems.err()
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !isCsvColumn(name) {
+			problems.add(offset+1, fmt.Sprintf("unknown column %q", name))
+			continue
+		}
+		columns[name] = i
+	}
+
+	if _, ok := columns["text"]; !ok {
+		problems.add(offset+1, "the text column is missing")
+	}
+
+	if len(problems) > 0 {
+		return nil, problems.err()
+	}
+
+	lines := make([]int, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			addCsvIssue(&problems, err, offset)
+			return nil, problems.err()
+		}
+
+		line, _ := reader.FieldPos(0)
+		line += offset
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok {
+				return strings.TrimSpace(record[i])
+			}
+
+			return ""
+		}
+
+		if question := decodeCsvQuestion(cell, line, &problems); question != nil {
+			quiz.Questions = append(quiz.Questions, question)
+			lines = append(lines, line)
+		}
+	}
+
+	if err := problems.err(); err != nil {
+		return nil, err
+	}
+
+	return &Import{Quiz: quiz, QuestionLines: lines}, nil
+}
+
+func decodeCsvQuestion(cell func(string) string, line int, problems *issues) *entity.Question {
+	question := &entity.Question{
+		Type:       cell("type"),
+		Text:       cell("text"),
+		Difficulty: cell("difficulty"),
+		Tags:       splitList(cell("tags")),
+		Options:    make([]*entity.AnswerOption, 0),
+	}
+	valid := true
+
+	number := func(name string, target interface{}) {
+		value := cell(name)
+		if value == "" {
+			return
+		}
+
+		var err error
+		switch t := target.(type) {
+		case *int:
+			*t, err = strconv.Atoi(value)
+		case *float64:
+			*t, err = strconv.ParseFloat(value, 64)
+		case *bool:
+			*t, err = strconv.ParseBool(value)
+		}
+
+		if err != nil {
+			problems.add(line, fmt.Sprintf("%s cannot be %q", name, value))
+			valid = false
+		}
+	}
+
+	number("time_limit_seconds", &question.TimeLimitSeconds)
+
+	for _, text := range splitList(cell("options")) {
+		question.Options = append(question.Options, &entity.AnswerOption{Text: text})
+	}
+
+	for _, n := range splitList(cell("correct")) {
+		i, err := strconv.Atoi(n)
+		if err != nil || i < 1 || i > len(question.Options) {
+			problems.add(line, fmt.Sprintf("correct option %q is not the number of an option", n))
+			valid = false
+			continue
+		}
+		question.Options[i-1].IsCorrect = true
+	}
+
+	matches := splitList(cell("matches"))
+	if len(matches) > 0 && len(matches) != len(question.Options) {
+		problems.add(line, "there has to be one match for every option")
+		valid = false
+	} else {
+		for i, match := range matches {
+			question.Options[i].Match = match
+		}
+	}
+
+	answer := &entity.QuestionAnswer{Accepted: splitList(cell("accepted"))}
+	number("value", &answer.Value)
+	number("tolerance", &answer.Tolerance)
+	number("case_sensitive", &answer.CaseSensitive)
+	number("max_distance", &answer.MaxDistance)
+
+	if cell("value") != "" || len(answer.Accepted) > 0 {
+		question.Answer = answer
+	}
+
+	if !valid {
+		return nil
+	}
+
+	return question
+}
+
+func (csvFormat) Encode(quiz *entity.Quiz) ([]byte, *entity.AppError) {
+	var b bytes.Buffer
+	encodeMetadata(&b, "#", quiz)
+
+	w := csv.NewWriter(&b)
+	if err := w.Write(csvColumns); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	for _, question := range quiz.Questions {
+		var options, correct, matches []string
+		for i, option := range question.Options {
+			options = append(options, option.Text)
+			if option.IsCorrect {
+				correct = append(correct, strconv.Itoa(i+1))
+			}
+			if option.Match != "" {
+				matches = append(matches, option.Match)
+			}
+		}
+
+		record := map[string]string{
+			"type":       question.Type,
+			"text":       question.Text,
+			"difficulty": question.Difficulty,
+			"tags":       joinList(question.Tags),
+			"options":    joinList(options),
+			"correct":    joinList(correct),
+			"matches":    joinList(matches),
+		}
+
+		if question.TimeLimitSeconds > 0 {
+			record["time_limit_seconds"] = strconv.Itoa(question.TimeLimitSeconds)
+		}
+
+		if a := question.Answer; a != nil {
+			record["accepted"] = joinList(a.Accepted)
+			if question.Type == entity.QuestionNumeric {
+				record["value"] = strconv.FormatFloat(a.Value, 'g', -1, 64)
+				record["tolerance"] = strconv.FormatFloat(a.Tolerance, 'g', -1, 64)
+			}
+			if a.CaseSensitive {
+				record["case_sensitive"] = "true"
+			}
+			if a.MaxDistance > 0 {
+				record["max_distance"] = strconv.Itoa(a.MaxDistance)
+			}
+		}
+
+		row := make([]string, len(csvColumns))
+		for i, name := range csvColumns {
+			row[i] = record[name]
+		}
+
+		if err := w.Write(row); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return b.Bytes(), nil
+}
+
+func isCsvColumn(name string) bool {
+	for _, column := range csvColumns {
+		if column == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addCsvIssue adds a parse error of the csv package at its line in the file.
+func addCsvIssue(problems *issues, err error, offset int) {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		problems.add(parseErr.Line+offset, parseErr.Err.Error())
+		return
+	}
+
+	problems.add(0, err.Error())
+}
+
+// decodeMetadata reads the title, description and time limit of the quiz from the comment
+// lines with the prefix at the top of the file. It returns the rest of the file and the number
+// of lines it skipped.
+func decodeMetadata(data []byte, prefix string, quiz *entity.Quiz, problems *issues) ([]byte, int) {
+	lines := 0
+
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '\n')
+		if end < 0 {
+			end = len(data) - 1
+		}
+
+		line := strings.TrimSpace(string(data[:end+1]))
+		if line != "" && !strings.HasPrefix(line, prefix) {
+			break
+		}
+
+		data = data[end+1:]
+		lines++
+
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, prefix), ":")
+		if !ok {
+			continue
+		}
+
+		decodeMetadataField(quiz, strings.TrimSpace(key), strings.TrimSpace(value), lines, problems)
+	}
+
+	return data, lines
+}
+
+// decodeMetadataField sets the title, description or time limit of the quiz and reports
+// whether the key was one of them.
+func decodeMetadataField(quiz *entity.Quiz, key string, value string, line int, problems *issues) bool {
+	switch key {
+	case "title":
+		quiz.Title = value
+	case "description":
+		quiz.Description = value
+	case "time_limit_seconds":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems.add(line, fmt.Sprintf("time_limit_seconds cannot be %q", value))
+		}
+		quiz.TimeLimitSeconds = n
+	default:
+		return false
+	}
+
+	return true
+}
+
+// encodeMetadata writes the title, description and time limit of the quiz as comment lines
+// with the prefix. Line breaks in the description become spaces.
+func encodeMetadata(b *bytes.Buffer, prefix string, quiz *entity.Quiz) {
+	flatten := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+	fmt.Fprintf(b, "%s title: %s\n", prefix, flatten.Replace(quiz.Title))
+	if quiz.Description != "" {
+		fmt.Fprintf(b, "%s description: %s\n", prefix, flatten.Replace(quiz.Description))
+	}
+
+	if quiz.TimeLimitSeconds > 0 {
+		fmt.Fprintf(b, "%s time_limit_seconds: %d\n", prefix, quiz.TimeLimitSeconds)
+	}
+}
+
+// splitList splits a cell at every "|" that is not escaped as "\|", and unescapes the items.
+func splitList(cell string) []string {
+	if cell == "" {
+		return nil
+	}
+
+	items := make([]string, 0)
+	var item strings.Builder
+	for i := 0; i < len(cell); i++ {
+		switch {
+		case cell[i] == '\\' && i+1 < len(cell):
+			i++
+			item.WriteByte(cell[i])
+		case cell[i] == '|':
+			items = append(items, strings.TrimSpace(item.String()))
+			item.Reset()
+		default:
+			item.WriteByte(cell[i])
+		}
+	}
+
+	return append(items, strings.TrimSpace(item.String()))
+}
+
+// joinList escapes and joins the items of a list cell.
+func joinList(items []string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `|`, `\|`)
+
+	escaped := make([]string, len(items))
+	for i, item := range items {
+		escaped[i] = escape.Replace(item)
+	}
+
+	return strings.Join(escaped, "|")
+}
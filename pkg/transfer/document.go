@@ -0,0 +1,137 @@
+package transfer
+
+import (
+	"quiz-app/pkg/entity"
+)
+
+// document is the quiz as stored in JSON and YAML files, without ids and positions.
+type document struct {
+	Title            string              `json:"title" yaml:"title"`
+	Description      string              `json:"description,omitempty" yaml:"description,omitempty"`
+	TimeLimitSeconds int                 `json:"timeLimitSeconds,omitempty" yaml:"timeLimitSeconds,omitempty"`
+	Questions        []*documentQuestion `json:"questions" yaml:"questions"`
+	DrawRules        []*documentDrawRule `json:"drawRules,omitempty" yaml:"drawRules,omitempty"`
+}
+
+type documentQuestion struct {
+	Type             string            `json:"type" yaml:"type"`
+	Text             string            `json:"text" yaml:"text"`
+	TimeLimitSeconds int               `json:"timeLimitSeconds,omitempty" yaml:"timeLimitSeconds,omitempty"`
+	Tags             []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Difficulty       string            `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	Options          []*documentOption `json:"options,omitempty" yaml:"options,omitempty"`
+	Answer           *documentAnswer   `json:"answer,omitempty" yaml:"answer,omitempty"`
+}
+
+type documentOption struct {
+	Text      string `json:"text" yaml:"text"`
+	IsCorrect bool   `json:"isCorrect,omitempty" yaml:"isCorrect,omitempty"`
+	Match     string `json:"match,omitempty" yaml:"match,omitempty"`
+}
+
+type documentAnswer struct {
+	Value         float64  `json:"value,omitempty" yaml:"value,omitempty"`
+	Tolerance     float64  `json:"tolerance,omitempty" yaml:"tolerance,omitempty"`
+	Accepted      []string `json:"accepted,omitempty" yaml:"accepted,omitempty"`
+	CaseSensitive bool     `json:"caseSensitive,omitempty" yaml:"caseSensitive,omitempty"`
+	MaxDistance   int      `json:"maxDistance,omitempty" yaml:"maxDistance,omitempty"`
+}
+
+type documentDrawRule struct {
+	Tag        string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Difficulty string `json:"difficulty,omitempty" yaml:"difficulty,omitempty"`
+	Count      int    `json:"count" yaml:"count"`
+}
+
+func newDocument(quiz *entity.Quiz) *document {
+	doc := &document{
+		Title:            quiz.Title,
+		Description:      quiz.Description,
+		TimeLimitSeconds: quiz.TimeLimitSeconds,
+		Questions:        make([]*documentQuestion, 0, len(quiz.Questions)),
+	}
+
+	for _, question := range quiz.Questions {
+		q := &documentQuestion{
+			Type:             question.Type,
+			Text:             question.Text,
+			TimeLimitSeconds: question.TimeLimitSeconds,
+			Tags:             question.Tags,
+			Difficulty:       question.Difficulty,
+		}
+
+		for _, option := range question.Options {
+			q.Options = append(q.Options, &documentOption{Text: option.Text, IsCorrect: option.IsCorrect, Match: option.Match})
+		}
+
+		if a := question.Answer; a != nil {
+			q.Answer = &documentAnswer{Value: a.Value, Tolerance: a.Tolerance, Accepted: a.Accepted, CaseSensitive: a.CaseSensitive, MaxDistance: a.MaxDistance}
+		}
+
+		doc.Questions = append(doc.Questions, q)
+	}
+
+	for _, rule := range quiz.DrawRules {
+		doc.DrawRules = append(doc.DrawRules, &documentDrawRule{Tag: rule.Tag, Difficulty: rule.Difficulty, Count: rule.Count})
+	}
+
+	return doc
+}
+
+// quiz returns the quiz of the document. Questions that are missing from the document, as
+// null entries, are reported at their line.
+func (doc *document) quiz(lines []int) (*entity.Quiz, *entity.AppError) {
+	var problems issues
+	quiz := &entity.Quiz{
+		Title:            doc.Title,
+		Description:      doc.Description,
+		TimeLimitSeconds: doc.TimeLimitSeconds,
+		Questions:        make([]*entity.Question, 0, len(doc.Questions)),
+	}
+
+	for i, q := range doc.Questions {
+		if q == nil {
+			problems.add(lines[i], "the question is empty")
+			continue
+		}
+
+		question := &entity.Question{
+			Type:             q.Type,
+			Text:             q.Text,
+			TimeLimitSeconds: q.TimeLimitSeconds,
+			Tags:             q.Tags,
+			Difficulty:       q.Difficulty,
+			Options:          make([]*entity.AnswerOption, 0, len(q.Options)),
+		}
+
+		for _, option := range q.Options {
+			if option == nil {
+				problems.add(lines[i], "an option is empty")
+				continue
+			}
+
+			question.Options = append(question.Options, &entity.AnswerOption{Text: option.Text, IsCorrect: option.IsCorrect, Match: option.Match})
+		}
+
+		if a := q.Answer; a != nil {
+			question.Answer = &entity.QuestionAnswer{Value: a.Value, Tolerance: a.Tolerance, Accepted: a.Accepted, CaseSensitive: a.CaseSensitive, MaxDistance: a.MaxDistance}
+		}
+
+		quiz.Questions = append(quiz.Questions, question)
+	}
+
+	for _, rule := range doc.DrawRules {
+		if rule == nil {
+			problems.add(0, "a draw rule is empty")
+			continue
+		}
+
+		quiz.DrawRules = append(quiz.DrawRules, &entity.DrawRule{Tag: rule.Tag, Difficulty: rule.Difficulty, Count: rule.Count})
+	}
+
+	if err := problems.err(); err != nil {
+		return nil, err
+	}
+
+	return quiz, nil
+}
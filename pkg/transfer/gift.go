@@ -0,0 +1,511 @@
+package transfer
+
+import (
+	"bytes"
+	"fmt"
+	"quiz-app/pkg/entity"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type giftFormat struct{}
+
+func init() {
+	Register("gift", giftFormat{})
+}
+
+var (
+	// giftMarker finds the [name] and [name:value] markers in the comments before a question:
+	giftMarker = regexp.MustCompile(`\[(\w+)(?::([^\]]*))?\]`)
+	// giftTextFormat is the optional format of the question text, which is ignored:
+	giftTextFormat = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+
+	// -> only needs escaping as a pair, since it separates the sides of a matching answer:
+	giftEscape = strings.NewReplacer(`\`, `\\`, `~`, `\~`, `=`, `\=`, `#`, `\#`, `{`, `\{`, `}`, `\}`, `:`, `\:`, `->`, `\->`, "\n", `\n`)
+)
+
+// the options of true/false questions written as {T} or {F}:
+const (
+	giftTrue  = "True"
+	giftFalse = "False"
+)
+
+// giftAnswer is one answer within the braces of a question, still escaped.
+type giftAnswer struct {
+	marker byte
+	weight *float64
+	text   string
+}
+
+func (giftFormat) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (giftFormat) Decode(data []byte) (*Import, *entity.AppError) {
+	var problems issues
+	quiz := &entity.Quiz{Questions: make([]*entity.Question, 0)}
+	lines := make([]int, 0)
+
+	var source []string
+	var comments []string
+	start := 0
+
+	// flush parses the question collected so far:
+	flush := func() {
+		if len(source) > 0 {
+			if question := decodeGiftQuestion(strings.Join(source, "\n"), comments, start, &problems); question != nil {
+				quiz.Questions = append(quiz.Questions, question)
+				lines = append(lines, start)
+			}
+		}
+
+		source = nil
+		comments = nil
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "//"):
+			comment := strings.TrimSpace(trimmed[2:])
+
+			// comments before the first question can set the quiz:
+			if len(quiz.Questions) == 0 && len(source) == 0 {
+				if key, value, ok := strings.Cut(comment, ":"); ok &&
+					decodeMetadataField(quiz, strings.TrimSpace(key), strings.TrimSpace(value), i+1, &problems) {
+					continue
+				}
+			}
+
+			comments = append(comments, comment)
+		case strings.HasPrefix(trimmed, "$CATEGORY:"):
+			continue
+		default:
+			if len(source) == 0 {
+				start = i + 1
+			}
+			source = append(source, strings.TrimRight(line, "\r"))
+		}
+	}
+	flush()
+
+	if err := problems.err(); err != nil {
+		return nil, err
+	}
+
+	return &Import{Quiz: quiz, QuestionLines: lines}, nil
+}
+
+// decodeGiftQuestion parses the question in source, which starts at line, with the markers in
+// the comments before it.
+func decodeGiftQuestion(source string, comments []string, line int, problems *issues) *entity.Question {
+	source = strings.TrimSpace(source)
+
+	// the name of the question is ignored:
+	if strings.HasPrefix(source, "::") {
+		if end := indexUnescaped(source[2:], "::"); end >= 0 {
+			source = strings.TrimSpace(source[end+4:])
+		}
+	}
+	source = strings.TrimSpace(giftTextFormat.ReplaceAllString(source, ""))
+
+	open := indexUnescaped(source, "{")
+	if open < 0 {
+		problems.add(line, "the answers in braces are missing")
+		return nil
+	}
+
+	length := indexUnescaped(source[open:], "}")
+	if length < 0 {
+		problems.add(line, "the closing brace of the answers is missing")
+		return nil
+	}
+
+	// answers in the middle of the text stand for a blank to fill in:
+	text := strings.TrimSpace(source[:open])
+	if after := strings.TrimSpace(source[open+length+1:]); after != "" {
+		text = strings.TrimSpace(text + " _____ " + after)
+	}
+
+	body := strings.TrimSpace(source[open+1 : open+length])
+	if end := indexUnescaped(body, "####"); end >= 0 {
+		body = strings.TrimSpace(body[:end])
+	}
+
+	question := &entity.Question{
+		Text:    unescapeGift(text),
+		Options: make([]*entity.AnswerOption, 0),
+	}
+
+	ok := true
+	switch {
+	case body == "":
+		problems.add(line, "essay questions are not supported")
+		ok = false
+	case body[0] == '#':
+		ok = decodeGiftNumeric(question, body[1:], line, problems)
+	case isGiftBoolean(withoutFeedback(body)):
+		correct := strings.ToUpper(withoutFeedback(body))[0] == 'T'
+		question.Type = entity.QuestionTrueFalse
+		question.Options = []*entity.AnswerOption{{Text: giftTrue, IsCorrect: correct}, {Text: giftFalse, IsCorrect: !correct}}
+	default:
+		ok = decodeGiftAnswers(question, body, line, problems)
+	}
+
+	if !ok || !decodeGiftMarkers(question, comments, line, problems) {
+		return nil
+	}
+
+	return question
+}
+
+func decodeGiftNumeric(question *entity.Question, body string, line int, problems *issues) bool {
+	body = strings.TrimSpace(withoutFeedback(body))
+	if strings.HasPrefix(body, "=") {
+		problems.add(line, "numeric questions with several answers are not supported")
+		return false
+	}
+
+	answer := &entity.QuestionAnswer{}
+	var err error
+	if low, high, isRange := strings.Cut(body, ".."); isRange {
+		var min, max float64
+		if min, err = strconv.ParseFloat(strings.TrimSpace(low), 64); err == nil {
+			max, err = strconv.ParseFloat(strings.TrimSpace(high), 64)
+		}
+		answer.Value = (min + max) / 2
+		answer.Tolerance = (max - min) / 2
+	} else {
+		value, tolerance, hasTolerance := strings.Cut(body, ":")
+		if answer.Value, err = strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && hasTolerance {
+			answer.Tolerance, err = strconv.ParseFloat(strings.TrimSpace(tolerance), 64)
+		}
+	}
+
+	if err != nil {
+		problems.add(line, fmt.Sprintf("%q is not a number, a number:tolerance or a min..max range", body))
+		return false
+	}
+
+	question.Type = entity.QuestionNumeric
+	question.Answer = answer
+	return true
+}
+
+// decodeGiftAnswers sets the type and options of a choice, matching or short answer question.
+// Lists of =answers are short answers, and ordering questions with a [type:ordering] marker.
+func decodeGiftAnswers(question *entity.Question, body string, line int, problems *issues) bool {
+	answers, ok := splitGiftAnswers(body)
+	if !ok {
+		problems.add(line, "every answer has to start with = or ~")
+		return false
+	}
+
+	allEquals, anyArrow, weighted, correct := true, false, false, 0
+	for _, answer := range answers {
+		allEquals = allEquals && answer.marker == '='
+		anyArrow = anyArrow || indexUnescaped(answer.text, "->") >= 0
+		weighted = weighted || answer.weight != nil
+
+		if answer.marker == '=' || (answer.weight != nil && *answer.weight > 0) {
+			correct++
+		}
+	}
+
+	switch {
+	case anyArrow:
+		if !allEquals {
+			problems.add(line, "every pair of a matching question has to start with =")
+			return false
+		}
+
+		question.Type = entity.QuestionMatching
+		for _, answer := range answers {
+			i := indexUnescaped(answer.text, "->")
+			if i < 0 {
+				problems.add(line, "every answer of a matching question has to be a pair like a -> b")
+				return false
+			}
+
+			question.Options = append(question.Options, &entity.AnswerOption{
+				Text:  unescapeGift(strings.TrimSpace(answer.text[:i])),
+				Match: unescapeGift(strings.TrimSpace(answer.text[i+2:])),
+			})
+		}
+	case allEquals && !weighted:
+		question.Type = entity.QuestionShortText
+		question.Answer = &entity.QuestionAnswer{}
+		for _, answer := range answers {
+			question.Answer.Accepted = append(question.Answer.Accepted, unescapeGift(strings.TrimSpace(answer.text)))
+		}
+	default:
+		question.Type = entity.QuestionSingleChoice
+		if weighted || correct > 1 {
+			question.Type = entity.QuestionMultipleChoice
+		}
+
+		for _, answer := range answers {
+			question.Options = append(question.Options, &entity.AnswerOption{
+				Text:      unescapeGift(strings.TrimSpace(answer.text)),
+				IsCorrect: answer.marker == '=' || (answer.weight != nil && *answer.weight > 0),
+			})
+		}
+	}
+
+	return true
+}
+
+// decodeGiftMarkers applies the markers in the comments before the question. A [type] marker
+// turns the accepted answers of a short answer into the options of an ordering question.
+func decodeGiftMarkers(question *entity.Question, comments []string, line int, problems *issues) bool {
+	ok := true
+	number := func(name string, value string) int {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems.add(line, fmt.Sprintf("[%s] cannot be %q", name, value))
+			ok = false
+		}
+
+		return n
+	}
+
+	for _, comment := range comments {
+		for _, m := range giftMarker.FindAllStringSubmatch(comment, -1) {
+			name, value := strings.ToLower(m[1]), strings.TrimSpace(m[2])
+
+			switch name {
+			case "type":
+				if question.Type == entity.QuestionShortText && value != entity.QuestionShortText && question.Answer != nil {
+					for _, text := range question.Answer.Accepted {
+						question.Options = append(question.Options, &entity.AnswerOption{Text: text})
+					}
+					question.Answer = nil
+				}
+				question.Type = value
+			case "tag":
+				question.Tags = append(question.Tags, value)
+			case "difficulty":
+				question.Difficulty = value
+			case "timelimit":
+				question.TimeLimitSeconds = number(name, value)
+			case "casesensitive", "maxdistance":
+				if question.Answer == nil {
+					continue
+				}
+
+				if name == "casesensitive" {
+					question.Answer.CaseSensitive = true
+				} else {
+					question.Answer.MaxDistance = number(name, value)
+				}
+			}
+		}
+	}
+
+	return ok
+}
+
+func (giftFormat) Encode(quiz *entity.Quiz) ([]byte, *entity.AppError) {
+	var b bytes.Buffer
+	encodeMetadata(&b, "//", quiz)
+
+	for _, question := range quiz.Questions {
+		markers := []string{"[type:" + question.Type + "]"}
+		for _, tag := range question.Tags {
+			markers = append(markers, "[tag:"+tag+"]")
+		}
+
+		if question.Difficulty != "" {
+			markers = append(markers, "[difficulty:"+question.Difficulty+"]")
+		}
+
+		if question.TimeLimitSeconds > 0 {
+			markers = append(markers, "[timelimit:"+strconv.Itoa(question.TimeLimitSeconds)+"]")
+		}
+
+		if a := question.Answer; a != nil && a.CaseSensitive {
+			markers = append(markers, "[casesensitive]")
+		}
+
+		if a := question.Answer; a != nil && a.MaxDistance > 0 {
+			markers = append(markers, "[maxdistance:"+strconv.Itoa(a.MaxDistance)+"]")
+		}
+
+		fmt.Fprintf(&b, "\n// %s\n%s {%s}\n", strings.Join(markers, " "), giftEscape.Replace(question.Text), encodeGiftAnswers(question))
+	}
+
+	return b.Bytes(), nil
+}
+
+func encodeGiftAnswers(question *entity.Question) string {
+	answers := make([]string, 0, len(question.Options))
+
+	switch question.Type {
+	case entity.QuestionNumeric:
+		if question.Answer == nil {
+			return "#"
+		}
+
+		return "#" + strconv.FormatFloat(question.Answer.Value, 'f', -1, 64) + ":" + strconv.FormatFloat(question.Answer.Tolerance, 'f', -1, 64)
+	case entity.QuestionShortText:
+		if question.Answer != nil {
+			for _, accepted := range question.Answer.Accepted {
+				answers = append(answers, "="+giftEscape.Replace(accepted))
+			}
+		}
+	case entity.QuestionOrdering:
+		for _, option := range question.Options {
+			answers = append(answers, "="+giftEscape.Replace(option.Text))
+		}
+	case entity.QuestionMatching:
+		for _, option := range question.Options {
+			answers = append(answers, "="+giftEscape.Replace(option.Text)+" -> "+giftEscape.Replace(option.Match))
+		}
+	case entity.QuestionTrueFalse:
+		if len(question.Options) == 2 && strings.EqualFold(question.Options[0].Text, giftTrue) &&
+			strings.EqualFold(question.Options[1].Text, giftFalse) {
+			if question.Options[0].IsCorrect {
+				return "T"
+			}
+
+			return "F"
+		}
+
+		answers = encodeGiftChoices(question, false)
+	case entity.QuestionMultipleChoice:
+		answers = encodeGiftChoices(question, true)
+	default:
+		answers = encodeGiftChoices(question, false)
+	}
+
+	return strings.Join(answers, " ")
+}
+
+// encodeGiftChoices writes correct options as =answers and wrong ones as ~answers, or with
+// weighted ~%weight%answers that share 100% among the correct options.
+func encodeGiftChoices(question *entity.Question, weighted bool) []string {
+	correct := 0
+	for _, option := range question.Options {
+		if option.IsCorrect {
+			correct++
+		}
+	}
+
+	weight := "~"
+	if weighted && correct > 0 {
+		w := strconv.FormatFloat(100/float64(correct), 'f', 5, 64)
+		weight = "~%" + strings.TrimRight(strings.TrimRight(w, "0"), ".") + "%"
+	}
+
+	answers := make([]string, 0, len(question.Options))
+	for _, option := range question.Options {
+		switch {
+		case !option.IsCorrect:
+			answers = append(answers, "~"+giftEscape.Replace(option.Text))
+		case weighted:
+			answers = append(answers, weight+giftEscape.Replace(option.Text))
+		default:
+			answers = append(answers, "="+giftEscape.Replace(option.Text))
+		}
+	}
+
+	return answers
+}
+
+// splitGiftAnswers splits the answers within braces at every unescaped = and ~. It fails when
+// there is text before the first answer.
+func splitGiftAnswers(body string) ([]*giftAnswer, bool) {
+	answers := make([]*giftAnswer, 0)
+	var current *giftAnswer
+
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '=' || c == '~':
+			current = &giftAnswer{marker: c}
+			answers = append(answers, current)
+		case current == nil:
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				return nil, false
+			}
+		case c == '\\' && i+1 < len(body):
+			current.text += body[i : i+2]
+			i++
+		default:
+			current.text += string(c)
+		}
+	}
+
+	for _, answer := range answers {
+		answer.text = strings.TrimSpace(withoutFeedback(answer.text))
+
+		if strings.HasPrefix(answer.text, "%") {
+			if end := strings.Index(answer.text[1:], "%"); end >= 0 {
+				if w, err := strconv.ParseFloat(answer.text[1:end+1], 64); err == nil {
+					answer.weight = &w
+					answer.text = strings.TrimSpace(answer.text[end+2:])
+				}
+			}
+		}
+	}
+
+	return answers, len(answers) > 0
+}
+
+// withoutFeedback cuts the feedback, which follows an unescaped #, off an answer.
+func withoutFeedback(answer string) string {
+	if i := indexUnescaped(answer, "#"); i >= 0 {
+		return answer[:i]
+	}
+
+	return answer
+}
+
+func isGiftBoolean(body string) bool {
+	switch strings.ToUpper(strings.TrimSpace(body)) {
+	case "T", "TRUE", "F", "FALSE":
+		return true
+	default:
+		return false
+	}
+}
+
+// indexUnescaped returns the index of the first sub in s that is not escaped with a backslash, or -1.
+func indexUnescaped(s string, sub string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescapeGift replaces the escape sequences of GIFT with the characters they stand for.
+func unescapeGift(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+
+		b.WriteByte(s[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,107 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"quiz-app/pkg/entity"
+)
+
+type jsonFormat struct{}
+
+func init() {
+	Register("json", jsonFormat{})
+}
+
+func (jsonFormat) ContentType() string {
+	return "application/json"
+}
+
+func (jsonFormat) Decode(data []byte) (*Import, *entity.AppError) {
+	var doc document
+	var problems issues
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+
+		switch {
+		case errors.As(err, &syntaxErr):
+			problems.add(lineAt(data, syntaxErr.Offset), syntaxErr.Error())
+		case errors.As(err, &typeErr):
+			problems.add(lineAt(data, typeErr.Offset), fmt.Sprintf("%s cannot be a %s", typeErr.Field, typeErr.Value))
+		default:
+			problems.add(0, err.Error())
+		}
+
+		return nil, problems.err()
+	}
+
+	lines := jsonQuestionLines(data, len(doc.Questions))
+	quiz, err := doc.quiz(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Import{Quiz: quiz, QuestionLines: lines}, nil
+}
+
+func (jsonFormat) Encode(quiz *entity.Quiz) ([]byte, *entity.AppError) {
+	b, err := json.MarshalIndent(newDocument(quiz), "", "  ")
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return append(b, '\n'), nil
+}
+
+// jsonQuestionLines returns the line every element of the questions array of the valid json
+// document starts at. Lines that cannot be found are 0.
+func jsonQuestionLines(data []byte, count int) []int {
+	lines := make([]int, count)
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	// the opening brace of the document:
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return lines
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return lines
+		}
+
+		if key != "questions" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return lines
+			}
+
+			continue
+		}
+
+		if t, err := dec.Token(); err != nil || t != json.Delim('[') {
+			return lines
+		}
+
+		for i := 0; dec.More() && i < count; i++ {
+			// the offset is at the end of the previous token, before any separator:
+			offset := dec.InputOffset()
+			for offset < int64(len(data)) && bytes.IndexByte([]byte(" \t\r\n,"), data[offset]) >= 0 {
+				offset++
+			}
+			lines[i] = lineAt(data, offset)
+
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return lines
+			}
+		}
+
+		return lines
+	}
+
+	return lines
+}
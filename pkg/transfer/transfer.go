@@ -0,0 +1,140 @@
+// Package transfer reads quizzes from and writes them to files in the formats below. Only JSON
+// and YAML keep every detail of a quiz; CSV and GIFT leave out the description line breaks and
+// the draw rules.
+//
+// JSON and YAML files hold one document with the same fields:
+//
+//	title: Capitals                  # required
+//	description: European capitals
+//	timeLimitSeconds: 600            # 0 or left out for no time limit
+//	questions:
+//	  - type: single_choice          # defaults to multiple_choice
+//	    text: What is the capital of France?
+//	    timeLimitSeconds: 30
+//	    tags: [geography, capitals]
+//	    difficulty: easy             # easy, medium (default) or hard
+//	    options:                     # in order, the correct order for ordering questions
+//	      - text: Paris
+//	        isCorrect: true
+//	      - text: Lyon
+//	  - type: matching
+//	    text: Match the countries with their capitals
+//	    options:
+//	      - {text: Spain, match: Madrid}
+//	      - {text: Italy, match: Rome}
+//	  - type: numeric
+//	    text: How many countries border Germany?
+//	    answer: {value: 9, tolerance: 0}
+//	  - type: short_text
+//	    text: What is the capital of Austria?
+//	    answer: {accepted: [Vienna, Wien], caseSensitive: false, maxDistance: 1}
+//	drawRules:
+//	  - {tag: capitals, difficulty: hard, count: 3}
+//
+// CSV files have a header row naming their columns, in any order, and one question per row.
+// Lists within a cell are separated by "|", which is escaped as "\|" (and "\" as "\\"):
+//
+//	type                 the question type
+//	text                 the question text (required)
+//	difficulty           easy, medium or hard
+//	tags                 the tags, as a list
+//	time_limit_seconds   the time limit of the question
+//	options              the option texts, as a list
+//	correct              the 1-based numbers of the correct options, as a list
+//	matches              the matches of the options of a matching question, as a list
+//	value, tolerance     the solution of a numeric question
+//	accepted             the accepted answers of a short text question, as a list
+//	case_sensitive       true when short text answers are case sensitive
+//	max_distance         the edits allowed in short text answers
+//
+// Lines before the header that start with "#" set the quiz title, description and time limit,
+// as in "# title: Capitals" and "# time_limit_seconds: 600".
+//
+// GIFT files follow the format of Moodle (https://docs.moodle.org/en/GIFT_format) for single
+// and multiple choice, true/false, matching, numeric and short answer questions. Comments at the
+// top of the file set the quiz like in CSV files, as in "// title: Capitals". Comments right
+// before a question can hold [type:ordering], [tag:capitals], [difficulty:hard], [timelimit:30],
+// [casesensitive] and [maxdistance:1], which Moodle ignores. Ordering questions, which Moodle
+// does not have, list their options in order as "{=first =second =third}" after [type:ordering].
+package transfer
+
+import (
+	"bytes"
+	"quiz-app/pkg/entity"
+)
+
+// Import is a quiz read from a file, with the line of the file each of its questions starts at.
+type Import struct {
+	Quiz          *entity.Quiz
+	QuestionLines []int
+}
+
+// Format reads and writes quizzes as files of one format. Decode reports problems with the
+// file as an entity.ErrInvalidImport error with the lines they are at.
+type Format interface {
+	Decode(data []byte) (*Import, *entity.AppError)
+	Encode(quiz *entity.Quiz) ([]byte, *entity.AppError)
+	ContentType() string
+}
+
+var formats = map[string]Format{}
+
+// Register makes a format available by name. Formats register themselves in their init function.
+func Register(name string, f Format) {
+	formats[name] = f
+}
+
+// For returns the format with the name, or ErrUnknownFormat.
+func For(name string) (Format, *entity.AppError) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, entity.ErrUnknownFormat
+	}
+
+	return f, nil
+}
+
+// Decode reads a quiz from a file of the named format.
+func Decode(format string, data []byte) (*Import, *entity.AppError) {
+	f, err := For(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Decode(data)
+}
+
+// Encode writes the quiz, with its answers, as a file of the named format.
+func Encode(format string, quiz *entity.Quiz) ([]byte, *entity.AppError) {
+	f, err := For(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Encode(quiz)
+}
+
+// issues collects the problems found while decoding a file.
+type issues []entity.ImportIssue
+
+func (is *issues) add(line int, message string) {
+	*is = append(*is, entity.ImportIssue{Line: line, Message: message})
+}
+
+// err returns the problems as an ErrInvalidImport error, or nil when there are none.
+func (is issues) err() *entity.AppError {
+	if len(is) == 0 {
+		return nil
+	}
+
+	return entity.NewImportError(is)
+}
+
+// lineAt returns the 1-based line of the byte offset into data.
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,262 @@
+package transfer
+
+import (
+	"encoding/json"
+	"quiz-app/pkg/entity"
+	"strings"
+	"testing"
+)
+
+func newTestQuiz() *entity.Quiz {
+	return &entity.Quiz{
+		Title:            "Capitals: Europe",
+		Description:      "European capitals",
+		TimeLimitSeconds: 600,
+		Questions: []*entity.Question{
+			{
+				Type: entity.QuestionSingleChoice, Text: "What is the capital of France?", Difficulty: entity.DifficultyEasy,
+				Tags: []string{"geography", "capitals"}, TimeLimitSeconds: 30,
+				Options: []*entity.AnswerOption{{Text: "Paris", IsCorrect: true}, {Text: "Lyon"}, {Text: "Nice | Cannes"}},
+			},
+			{
+				Type: entity.QuestionMultipleChoice, Text: "Which cities are capitals? {pick all}", Difficulty: entity.DifficultyMedium,
+				Options: []*entity.AnswerOption{{Text: "Rome", IsCorrect: true}, {Text: "Milan"}, {Text: "Bern", IsCorrect: true}},
+			},
+			{
+				Type: entity.QuestionTrueFalse, Text: "Vienna is the capital of Austria.", Difficulty: entity.DifficultyEasy,
+				Options: []*entity.AnswerOption{{Text: "True", IsCorrect: true}, {Text: "False"}},
+			},
+			{
+				Type: entity.QuestionTrueFalse, Text: "Is 2 = 3?", Difficulty: entity.DifficultyEasy,
+				Options: []*entity.AnswerOption{{Text: "Yes"}, {Text: "No", IsCorrect: true}},
+			},
+			{
+				Type: entity.QuestionOrdering, Text: "Order by population, largest first", Difficulty: entity.DifficultyHard,
+				Options: []*entity.AnswerOption{{Text: "Berlin"}, {Text: "Madrid"}, {Text: "Rome"}},
+			},
+			{
+				Type: entity.QuestionMatching, Text: "Match the countries with their capitals", Difficulty: entity.DifficultyMedium,
+				Options: []*entity.AnswerOption{{Text: "Spain", Match: "Madrid"}, {Text: "Italy", Match: "Rome"}, {Text: "x->y", Match: "z"}},
+			},
+			{
+				Type: entity.QuestionNumeric, Text: "How many countries border Germany? #neighbours", Difficulty: entity.DifficultyMedium,
+				Options: []*entity.AnswerOption{}, Answer: &entity.QuestionAnswer{Value: 9, Tolerance: 0.5},
+			},
+			{
+				Type: entity.QuestionShortText, Text: "What is the capital of Austria?", Difficulty: entity.DifficultyMedium,
+				Options: []*entity.AnswerOption{}, Answer: &entity.QuestionAnswer{Accepted: []string{"Vienna", "Wien"}, CaseSensitive: true, MaxDistance: 1},
+			},
+		},
+		DrawRules: []*entity.DrawRule{{Tag: "capitals", Difficulty: entity.DifficultyHard, Count: 3}},
+	}
+}
+
+// canonical returns the quiz as a json document, which leaves out ids and empty fields.
+func canonical(t *testing.T, quiz *entity.Quiz) string {
+	b, err := json.Marshal(newDocument(quiz))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
+func TestRoundTrip(t *testing.T) {
+
+	for _, format := range []string{"json", "yaml", "csv", "gift"} {
+		t.Run("Decode should read back the quiz written by Encode as "+format, func(t *testing.T) {
+			quiz := newTestQuiz()
+
+			data, err := Encode(format, quiz)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			imported, err := Decode(format, data)
+			if err != nil {
+				t.Fatal(err, string(data))
+			}
+
+			// draw rules are only kept by json and yaml:
+			if format == "csv" || format == "gift" {
+				quiz.DrawRules = nil
+			}
+
+			if expected, actual := canonical(t, quiz), canonical(t, imported.Quiz); expected != actual {
+				t.Errorf("expected %s\nbut got %s\nfrom %s", expected, actual, data)
+			}
+
+			if len(imported.QuestionLines) != len(quiz.Questions) {
+				t.FailNow()
+			}
+
+			for _, line := range imported.QuestionLines {
+				if line == 0 {
+					t.Fail()
+				}
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+
+	t.Run("Decode should reject an unknown format", func(t *testing.T) {
+		if _, err := Decode("xml", []byte("<quiz/>")); err != entity.ErrUnknownFormat {
+			t.Fail()
+		}
+	})
+
+	t.Run("Decode should find the lines of the questions in json", func(t *testing.T) {
+		data := "{\n  \"title\": \"Go\",\n  \"questions\": [\n    {\n      \"text\": \"a\"\n    },\n    {\"text\": \"b\"}\n  ]\n}"
+
+		imported, err := Decode("json", []byte(data))
+
+		if err != nil || len(imported.QuestionLines) != 2 || imported.QuestionLines[0] != 4 || imported.QuestionLines[1] != 7 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Decode should find the lines of the questions in yaml", func(t *testing.T) {
+		data := "title: Go\nquestions:\n  - text: a\n    type: numeric\n  - text: b\n"
+
+		imported, err := Decode("yaml", []byte(data))
+
+		if err != nil || len(imported.QuestionLines) != 2 || imported.QuestionLines[0] != 3 || imported.QuestionLines[1] != 5 {
+			t.Fail()
+		}
+	})
+
+	t.Run("Decode should read a Moodle GIFT file", func(t *testing.T) {
+		data := strings.Join([]string{
+			"// title: Moodle",
+			"$CATEGORY: geography",
+			"",
+			"::Q1:: What is the capital of France? {=Paris#Right! ~Lyon#No ~Nice}",
+			"",
+			"::Q2:: Which are capitals? {",
+			"  ~%50%Rome",
+			"  ~%50%Bern",
+			"  ~%-100%Milan",
+			"}",
+			"",
+			"Grant is buried in Grant's tomb.{F}",
+			"",
+			"Two plus {=two =2} equals four.",
+			"",
+			"Match the capitals. {=Spain -> Madrid =Italy -> Rome}",
+			"",
+			"When was Rome founded? {#-760..-746}",
+		}, "\n")
+
+		imported, err := Decode("gift", []byte(data))
+		if err != nil || imported.Quiz.Title != "Moodle" || len(imported.Quiz.Questions) != 6 {
+			t.Fatal(err)
+		}
+
+		questions := imported.Quiz.Questions
+		if questions[0].Type != entity.QuestionSingleChoice || !questions[0].Options[0].IsCorrect || questions[0].Options[1].Text != "Lyon" {
+			t.Error("single choice", questions[0])
+		}
+
+		if questions[1].Type != entity.QuestionMultipleChoice || !questions[1].Options[1].IsCorrect || questions[1].Options[2].IsCorrect {
+			t.Error("multiple choice", questions[1])
+		}
+
+		if questions[2].Type != entity.QuestionTrueFalse || questions[2].Options[0].IsCorrect || !questions[2].Options[1].IsCorrect {
+			t.Error("true/false", questions[2])
+		}
+
+		if questions[3].Type != entity.QuestionShortText || questions[3].Text != "Two plus _____ equals four." || len(questions[3].Answer.Accepted) != 2 {
+			t.Error("short text", questions[3])
+		}
+
+		if questions[4].Type != entity.QuestionMatching || questions[4].Options[1].Match != "Rome" {
+			t.Error("matching", questions[4])
+		}
+
+		if questions[5].Type != entity.QuestionNumeric || questions[5].Answer.Value != -753 || questions[5].Answer.Tolerance != 7 {
+			t.Error("numeric", questions[5])
+		}
+
+		if lines := imported.QuestionLines; lines[0] != 4 || lines[1] != 6 || lines[5] != 18 {
+			t.Error("lines", lines)
+		}
+	})
+}
+
+func TestDecodeErrors(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		lines  []int
+	}{
+		{
+			name:   "Decode should report a json syntax error at its line",
+			format: "json",
+			data:   "{\n  \"title\": \"Go\",\n  \"questions\": [,]\n}",
+			lines:  []int{3},
+		},
+		{
+			name:   "Decode should report a json value of the wrong type at its line",
+			format: "json",
+			data:   "{\n  \"title\": \"Go\",\n  \"timeLimitSeconds\": \"ten\"\n}",
+			lines:  []int{3},
+		},
+		{
+			name:   "Decode should report a yaml syntax error at its line",
+			format: "yaml",
+			data:   "title: Go\nquestions:\n  - text: \"a\n",
+			lines:  []int{3},
+		},
+		{
+			name:   "Decode should report every yaml value of the wrong type at its line",
+			format: "yaml",
+			data:   "title: Go\nquestions:\n  - text: a\n    timeLimitSeconds: ten\n  - text: b\n    options: yes\n",
+			lines:  []int{4, 6},
+		},
+		{
+			name:   "Decode should report an unknown csv column at the line of the header",
+			format: "csv",
+			data:   "# title: Go\ntype,text,answer\nnumeric,a,1\n",
+			lines:  []int{2},
+		},
+		{
+			name:   "Decode should report every invalid csv row at its line",
+			format: "csv",
+			data:   "# title: Go\n\ntext,options,correct,value\na,x|y,3,\nb,,,\nc,,,nine\n",
+			lines:  []int{4, 6},
+		},
+		{
+			name:   "Decode should report a csv row with a missing cell at its line",
+			format: "csv",
+			data:   "text,options\na,x|y\nb\n",
+			lines:  []int{3},
+		},
+		{
+			name:   "Decode should report every unsupported gift question at its line",
+			format: "gift",
+			data:   "// title: Go\n\nWrite an essay. {}\n\nIs Go compiled?\n{T}\n\nNo answers here.\n",
+			lines:  []int{3, 8},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Decode(test.format, []byte(test.data))
+
+			importErr, ok := err.AppError.(*entity.ImportError)
+			if !ok || err.Error() != entity.ErrInvalidImport.Error() || len(importErr.Issues) != len(test.lines) {
+				t.Fatal(err)
+			}
+
+			for i, issue := range importErr.Issues {
+				if issue.Line != test.lines[i] {
+					t.Errorf("expected line %d but got %d: %s", test.lines[i], issue.Line, issue.Message)
+				}
+			}
+		})
+	}
+}
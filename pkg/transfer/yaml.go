@@ -0,0 +1,103 @@
+package transfer
+
+import (
+	"errors"
+	"gopkg.in/yaml.v3"
+	"quiz-app/pkg/entity"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type yamlFormat struct{}
+
+func init() {
+	Register("yaml", yamlFormat{})
+}
+
+// yamlLine finds the line in the messages of the yaml package, as in "yaml: line 3: ...".
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): `)
+
+func (yamlFormat) ContentType() string {
+	return "application/yaml"
+}
+
+func (yamlFormat) Decode(data []byte) (*Import, *entity.AppError) {
+	var root yaml.Node
+	var doc document
+	var problems issues
+
+	err := yaml.Unmarshal(data, &root)
+	if err == nil {
+		if len(root.Content) == 0 {
+			problems.add(0, "the file is empty")
+			return nil, problems.err()
+		}
+
+		err = root.Content[0].Decode(&doc)
+	}
+
+	if err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			for _, message := range typeErr.Errors {
+				addYamlIssue(&problems, message)
+			}
+		} else {
+			addYamlIssue(&problems, err.Error())
+		}
+
+		return nil, problems.err()
+	}
+
+	lines := yamlQuestionLines(root.Content[0], len(doc.Questions))
+	quiz, appErr := doc.quiz(lines)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return &Import{Quiz: quiz, QuestionLines: lines}, nil
+}
+
+func (yamlFormat) Encode(quiz *entity.Quiz) ([]byte, *entity.AppError) {
+	b, err := yaml.Marshal(newDocument(quiz))
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return b, nil
+}
+
+// addYamlIssue adds the message of the yaml package at the line it names.
+func addYamlIssue(problems *issues, message string) {
+	if m := yamlLine.FindStringSubmatch(message); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		problems.add(line, message[len(m[0]):])
+		return
+	}
+
+	problems.add(0, strings.TrimPrefix(message, "yaml: "))
+}
+
+// yamlQuestionLines returns the line every item of the questions sequence of the document starts at.
+func yamlQuestionLines(doc *yaml.Node, count int) []int {
+	lines := make([]int, count)
+	if doc.Kind != yaml.MappingNode {
+		return lines
+	}
+
+	// the content of a mapping alternates between keys and values:
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value != "questions" {
+			continue
+		}
+
+		for j, item := range doc.Content[i+1].Content {
+			if j < count {
+				lines[j] = item.Line
+			}
+		}
+	}
+
+	return lines
+}
@@ -7,12 +7,31 @@ import (
 
 type Reader interface {
 	FindByID(user_id int64) (*entity.User, *entity.AppError)
+	FindByIDAndReturnPassword(user_id int64) (*entity.User, *entity.AppError)
 	FindByUsername(username string) (*entity.User, *entity.AppError)
 	FindByUsernameAndReturnPassword(username string) (*entity.User, *entity.AppError)
+	FindByEmail(email string) (*entity.User, *entity.AppError)
+	List(query *entity.UserListQuery) ([]*entity.User, *entity.AppError)
+	FindRolesByUserID(user_id int64) ([]string, *entity.AppError)
+	FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, *entity.AppError)
+	FindPasswordResetTokenByHash(tokenHash string) (*entity.PasswordResetToken, *entity.AppError)
 }
 
 type Writer interface {
+	Create(username string, email string, passwordHash string, role string) (*entity.User, *entity.AppError)
 	UpdateWithLastLoginAt(user_id int64) (sql.Result, *entity.AppError)
+	UpdateUsername(user_id int64, username string) (sql.Result, *entity.AppError)
+	UpdateEmail(user_id int64, email string) (sql.Result, *entity.AppError)
+	UpdatePassword(user_id int64, passwordHash string) (sql.Result, *entity.AppError)
+	Delete(user_id int64) (sql.Result, *entity.AppError)
+	AddRole(user_id int64, role string) (sql.Result, *entity.AppError)
+	CreateRefreshToken(token *entity.RefreshToken) *entity.AppError
+	MarkRefreshTokenUsed(token_id int64) (sql.Result, *entity.AppError)
+	RevokeRefreshTokenFamily(family_id string) (sql.Result, *entity.AppError)
+	RevokeRefreshTokensByUserID(user_id int64) (sql.Result, *entity.AppError)
+	CreatePasswordResetToken(token *entity.PasswordResetToken) *entity.AppError
+	MarkPasswordResetTokenUsed(token_id int64) (sql.Result, *entity.AppError)
+	InvalidatePasswordResetTokens(user_id int64) (sql.Result, *entity.AppError)
 }
 
 // Repository interface
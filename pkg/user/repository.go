@@ -2,7 +2,11 @@ package user
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
 	"quiz-app/pkg/entity"
+	"strings"
 	"time"
 )
 
@@ -20,18 +24,42 @@ func (r PGRepository) FindByID(userId int64) (*entity.User, *entity.AppError) {
 	var id int64
 	var username string
 	var createdAt time.Time
-	query := "select id, username, created_at from users where id=$1"
-	err := r.pool.QueryRow(query, userId).Scan(&id, &username, &createdAt)
+	var email sql.NullString
+	var lastLoginAt sql.NullTime
+	query := "select id, username, email, created_at, last_login_at from users where id=$1"
+	err := r.pool.QueryRow(query, userId).Scan(&id, &username, &email, &createdAt, &lastLoginAt)
+
+	if sql.ErrNoRows == err {
+		return nil, entity.ErrEntityNotFound
+	}
 
 	if err != nil {
 		return nil, entity.NewAppError(err)
 	}
 
-	if sql.ErrNoRows == err {
+	user := entity.User{Id: id, Username: username, Email: email.String, CreatedAt: createdAt, LastLoginAt: lastLoginAt.Time}
+	return &user, nil
+}
+
+func (r PGRepository) FindByIDAndReturnPassword(userId int64) (*entity.User, *entity.AppError) {
+	var id int64
+	var username string
+	var password string
+	var createdAt time.Time
+	var email sql.NullString
+
+	query := "select id, username, email, password, created_at from users where id=$1"
+	err := r.pool.QueryRow(query, userId).Scan(&id, &username, &email, &password, &createdAt)
+
+	if err == sql.ErrNoRows {
 		return nil, entity.ErrEntityNotFound
 	}
 
-	user := entity.User{Id: id, Username: username, CreatedAt: createdAt}
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	user := entity.User{Id: id, Username: username, Email: email.String, Password: password, CreatedAt: createdAt}
 	return &user, nil
 }
 
@@ -39,9 +67,10 @@ func (r PGRepository) FindByUsername(username string) (*entity.User, *entity.App
 	var id int64
 	var userName string
 	var createdAt time.Time
+	var lastLoginAt sql.NullTime
 
-	query := "select id, username, created_at from users where username=$1"
-	err := r.pool.QueryRow(query, username).Scan(&id, &userName, &createdAt)
+	query := "select id, username, created_at, last_login_at from users where username=$1"
+	err := r.pool.QueryRow(query, username).Scan(&id, &userName, &createdAt, &lastLoginAt)
 
 	if err == sql.ErrNoRows {
 		return nil, entity.ErrEntityNotFound
@@ -51,10 +80,126 @@ func (r PGRepository) FindByUsername(username string) (*entity.User, *entity.App
 		return nil, entity.NewAppError(err)
 	}
 
-	user := entity.User{Id: id, Username: userName, CreatedAt: createdAt}
+	user := entity.User{Id: id, Username: userName, CreatedAt: createdAt, LastLoginAt: lastLoginAt.Time}
 	return &user, nil
 }
 
+func (r PGRepository) FindByEmail(email string) (*entity.User, *entity.AppError) {
+	var id int64
+	var username string
+	var userEmail string
+	var createdAt time.Time
+
+	query := "select id, username, email, created_at from users where lower(email)=lower($1)"
+	err := r.pool.QueryRow(query, email).Scan(&id, &username, &userEmail, &createdAt)
+
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	user := entity.User{Id: id, Username: username, Email: userEmail, CreatedAt: createdAt}
+	return &user, nil
+}
+
+// userSortExpressions maps sort names to columns, a missing last login sorts like Go's zero time:
+var userSortExpressions = map[string]string{
+	entity.UserSortCreatedAt:   "created_at",
+	entity.UserSortLastLoginAt: "coalesce(last_login_at, '0001-01-01 00:00:00+00'::timestamptz)",
+	entity.UserSortUsername:    "username",
+}
+
+// List returns up to query.Limit users after the cursor using keyset pagination on (sort value, id).
+func (r PGRepository) List(query *entity.UserListQuery) ([]*entity.User, *entity.AppError) {
+	sortExpr, ok := userSortExpressions[query.SortBy]
+	if !ok {
+		return nil, entity.ErrInvalidSort
+	}
+
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+	addCondition := func(condition string, values ...interface{}) {
+		for _, value := range values {
+			args = append(args, value)
+			condition = strings.Replace(condition, "?", fmt.Sprintf("$%d", len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if query.UsernamePrefix != "" {
+		escaper := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+		addCondition("lower(username) like lower(?)", escaper.Replace(query.UsernamePrefix)+"%")
+	}
+
+	if query.CreatedAfter != nil {
+		addCondition("created_at >= ?", query.CreatedAfter.UTC())
+	}
+
+	if query.CreatedBefore != nil {
+		addCondition("created_at < ?", query.CreatedBefore.UTC())
+	}
+
+	if query.LastLoginAfter != nil {
+		addCondition("last_login_at >= ?", query.LastLoginAfter.UTC())
+	}
+
+	if query.LastLoginBefore != nil {
+		addCondition("last_login_at < ?", query.LastLoginBefore.UTC())
+	}
+
+	direction, comparison := "asc", ">"
+	if query.Descending {
+		direction, comparison = "desc", "<"
+	}
+
+	if query.After != nil {
+		var value interface{} = query.After.Value
+		if query.SortBy != entity.UserSortUsername {
+			t, err := time.Parse(time.RFC3339Nano, query.After.Value)
+			if err != nil {
+				return nil, entity.ErrInvalidCursor
+			}
+			value = t.UTC()
+		}
+		addCondition(fmt.Sprintf("(%s, id) %s (?, ?)", sortExpr, comparison), value, query.After.Id)
+	}
+
+	queryStmt := "select id, username, created_at, last_login_at from users"
+	if len(conditions) > 0 {
+		queryStmt += " where " + strings.Join(conditions, " and ")
+	}
+
+	args = append(args, query.Limit)
+	queryStmt += fmt.Sprintf(" order by %s %s, id %s limit $%d", sortExpr, direction, direction, len(args))
+
+	rows, err := r.pool.Query(queryStmt, args...)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	users := make([]*entity.User, 0, query.Limit)
+	for rows.Next() {
+		var user entity.User
+		var lastLoginAt sql.NullTime
+		if err := rows.Scan(&user.Id, &user.Username, &user.CreatedAt, &lastLoginAt); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		user.LastLoginAt = lastLoginAt.Time
+		users = append(users, &user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return users, nil
+}
+
 func (r PGRepository) FindByUsernameAndReturnPassword(username string) (*entity.User, *entity.AppError) {
 	var id int64
 	var userName string
@@ -76,6 +221,72 @@ func (r PGRepository) FindByUsernameAndReturnPassword(username string) (*entity.
 	return &user, nil
 }
 
+func (r PGRepository) FindRolesByUserID(userId int64) ([]string, *entity.AppError) {
+
+	query := "select role from user_roles where user_id=$1 order by role"
+	rows, err := r.pool.Query(query, userId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer rows.Close()
+
+	roles := make([]string, 0)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, entity.NewAppError(err)
+		}
+
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return roles, nil
+}
+
+// Create stores the user with the role in one transaction, so that no account is left without
+// a role.
+func (r PGRepository) Create(username string, email string, passwordHash string, role string) (*entity.User, *entity.AppError) {
+	var id int64
+	var userName string
+	var createdAt time.Time
+
+	tx, err := r.pool.Begin()
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+	defer tx.Rollback()
+
+	// a conflict on the unique username or email index inserts nothing and returns no rows:
+	query := "insert into users (username, email, password, created_at) values ($1, $2, $3, $4) on conflict do nothing returning id, username, created_at"
+	now := time.Now().UTC()
+	userEmail := sql.NullString{String: email, Valid: email != ""}
+	err = tx.QueryRow(query, username, userEmail, passwordHash, now).Scan(&id, &userName, &createdAt)
+
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrUserAlreadyExists
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	roleQuery := "insert into user_roles (user_id, role, created_at) values ($1, $2, $3)"
+	if _, err := tx.Exec(roleQuery, id, role, now); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	user := entity.User{Id: id, Username: userName, Email: email, Roles: []string{role}, CreatedAt: createdAt}
+	return &user, nil
+}
+
 func (r PGRepository) UpdateWithLastLoginAt(userId int64) (sql.Result, *entity.AppError) {
 
 	query := "update users set last_login_at=$1 where id=$2"
@@ -88,3 +299,223 @@ func (r PGRepository) UpdateWithLastLoginAt(userId int64) (sql.Result, *entity.A
 
 	return res, nil
 }
+
+func (r PGRepository) UpdateUsername(userId int64, username string) (sql.Result, *entity.AppError) {
+
+	query := "update users set username=$1 where id=$2"
+
+	res, err := r.pool.Exec(query, username, userId)
+	if isUniqueViolation(err) {
+		return nil, entity.ErrUserAlreadyExists
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) UpdateEmail(userId int64, email string) (sql.Result, *entity.AppError) {
+
+	query := "update users set email=$1 where id=$2"
+	userEmail := sql.NullString{String: email, Valid: email != ""}
+
+	res, err := r.pool.Exec(query, userEmail, userId)
+	if isUniqueViolation(err) {
+		return nil, entity.ErrEmailAlreadyExists
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) UpdatePassword(userId int64, passwordHash string) (sql.Result, *entity.AppError) {
+
+	query := "update users set password=$1 where id=$2"
+
+	res, err := r.pool.Exec(query, passwordHash, userId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+// Delete removes the user, roles and refresh tokens are removed by their foreign keys.
+func (r PGRepository) Delete(userId int64) (sql.Result, *entity.AppError) {
+
+	query := "delete from users where id=$1"
+
+	res, err := r.pool.Exec(query, userId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) AddRole(userId int64, role string) (sql.Result, *entity.AppError) {
+
+	query := "insert into user_roles (user_id, role, created_at) values ($1, $2, $3) on conflict do nothing"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, userId, role, now)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) FindRefreshTokenByHash(tokenHash string) (*entity.RefreshToken, *entity.AppError) {
+	var token entity.RefreshToken
+	var usedAt sql.NullTime
+	var revokedAt sql.NullTime
+
+	query := "select id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at from refresh_tokens where token_hash=$1"
+	err := r.pool.QueryRow(query, tokenHash).Scan(&token.Id, &token.UserId, &token.FamilyId, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &usedAt, &revokedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r PGRepository) CreateRefreshToken(token *entity.RefreshToken) *entity.AppError {
+
+	query := "insert into refresh_tokens (user_id, family_id, token_hash, expires_at, created_at) values ($1, $2, $3, $4, $5) returning id"
+
+	err := r.pool.QueryRow(query, token.UserId, token.FamilyId, token.TokenHash, token.ExpiresAt, token.CreatedAt).Scan(&token.Id)
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+// MarkRefreshTokenUsed only affects a token that is still active, so of two concurrent rotations
+// of the same token exactly one reports an affected row.
+func (r PGRepository) MarkRefreshTokenUsed(tokenId int64) (sql.Result, *entity.AppError) {
+
+	query := "update refresh_tokens set used_at=$1 where id=$2 and used_at is null and revoked_at is null"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, now, tokenId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) RevokeRefreshTokensByUserID(userId int64) (sql.Result, *entity.AppError) {
+
+	query := "update refresh_tokens set revoked_at=$1 where user_id=$2 and revoked_at is null"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, now, userId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) RevokeRefreshTokenFamily(familyId string) (sql.Result, *entity.AppError) {
+
+	query := "update refresh_tokens set revoked_at=$1 where family_id=$2 and revoked_at is null"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, now, familyId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+func (r PGRepository) FindPasswordResetTokenByHash(tokenHash string) (*entity.PasswordResetToken, *entity.AppError) {
+	var token entity.PasswordResetToken
+	var usedAt sql.NullTime
+
+	query := "select id, user_id, token_hash, expires_at, created_at, used_at from password_reset_tokens where token_hash=$1"
+	err := r.pool.QueryRow(query, tokenHash).Scan(&token.Id, &token.UserId, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &usedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, entity.ErrEntityNotFound
+	}
+
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+func (r PGRepository) CreatePasswordResetToken(token *entity.PasswordResetToken) *entity.AppError {
+
+	query := "insert into password_reset_tokens (user_id, token_hash, expires_at, created_at) values ($1, $2, $3, $4) returning id"
+
+	err := r.pool.QueryRow(query, token.UserId, token.TokenHash, token.ExpiresAt, token.CreatedAt).Scan(&token.Id)
+	if err != nil {
+		return entity.NewAppError(err)
+	}
+
+	return nil
+}
+
+// MarkPasswordResetTokenUsed only affects an unused token, so a token can be redeemed once.
+func (r PGRepository) MarkPasswordResetTokenUsed(tokenId int64) (sql.Result, *entity.AppError) {
+
+	query := "update password_reset_tokens set used_at=$1 where id=$2 and used_at is null"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, now, tokenId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+// InvalidatePasswordResetTokens marks every outstanding reset token of the user as used.
+func (r PGRepository) InvalidatePasswordResetTokens(userId int64) (sql.Result, *entity.AppError) {
+
+	query := "update password_reset_tokens set used_at=$1 where user_id=$2 and used_at is null"
+	now := time.Now().UTC()
+
+	res, err := r.pool.Exec(query, now, userId)
+	if err != nil {
+		return nil, entity.NewAppError(err)
+	}
+
+	return res, nil
+}
+
+// isUniqueViolation reports whether err comes from a unique index, which a concurrent request
+// can hit after the service checked that the value was free.
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -1,82 +1,200 @@
 package user
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"golang.org/x/crypto/bcrypt"
 	"log"
-	"os"
+	"net/mail"
+	"net/url"
 	"quiz-app/pkg/entity"
+	"quiz-app/pkg/keyring"
+	"quiz-app/pkg/mailer"
+	"regexp"
+	"sync"
 	"time"
 )
 
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{2,31}$`)
+
+const (
+	accessTokenTTL  = 2 * time.Hour
+	refreshTokenTTL = 30 * 24 * time.Hour
+	resetTokenTTL   = time.Hour
+
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	minPasswordLength = 8
+	// bcrypt ignores everything after the 72nd byte:
+	maxPasswordLength = 72
+)
+
+// dummyHash is compared against when a login names an unknown user, so that the response
+// takes as long as for a wrong password:
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
 type Service struct {
-	repo Repository
+	repo             Repository
+	keyring          *keyring.Keyring
+	mailer           mailer.Mailer
+	passwordResetURL string
+	throttle         *loginThrottle
+	// resets are the password reset mails being sent:
+	resets sync.WaitGroup
 }
 
 type AuthUser struct {
-	User  *entity.User `json:"user"`
-	Token string       `json:"token"`
+	User         *entity.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refreshToken"`
 }
 
-func InitService(r Repository) *Service {
+// ProfileUpdate holds the changes a user makes to their own account. Fields left nil are not changed.
+type ProfileUpdate struct {
+	Username        *string `json:"username"`
+	Email           *string `json:"email"`
+	Password        *string `json:"password"`
+	CurrentPassword string  `json:"currentPassword"`
+}
+
+// InitService creates the user service. Password reset mails link to passwordResetURL
+// with the reset token appended as the token query parameter.
+func InitService(r Repository, kr *keyring.Keyring, m mailer.Mailer, passwordResetURL string) *Service {
 	return &Service{
-		repo: r,
+		repo:             r,
+		keyring:          kr,
+		mailer:           m,
+		passwordResetURL: passwordResetURL,
+		throttle:         newLoginThrottle(),
 	}
 }
 
 func (s *Service) createJWTTokenString(user *entity.User) (string, *entity.AppError) {
 
 	// set expiration time:
-	expirationTime := time.Now().Add(2 * time.Hour)
+	expirationTime := time.Now().Add(accessTokenTTL)
+
+	// unique token id used for revocation:
+	jti, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
 
 	claims := &entity.JwtClaims{
 		Username: user.Username,
 		UserId:   user.Id,
+		Roles:    user.Roles,
 		StandardClaims: jwt.StandardClaims{
+			Id:        jti,
 			ExpiresAt: expirationTime.Unix(),
 			IssuedAt:  time.Now().Unix(),
 		},
 	}
 
-	// create a token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// get secret key:
-	key, isFound := os.LookupEnv("SECRET_KEY")
-	if isFound == false {
-		return "", entity.ErrSecretKey
+	// create token string, signed with the current keyring signing key:
+	tokenString, err := s.keyring.Sign(claims)
+	if err == entity.ErrSecretKey {
+		return "", err
 	}
 
-	// create token string:
-	tokenString, err := token.SignedString([]byte(key))
 	if err != nil {
 		log.Println(err)
-		tokenCreateError := entity.ErrJwtCreation
-		return "", tokenCreateError
+		return "", entity.ErrJwtCreation
 	}
 
 	return tokenString, nil
 }
 
-func (s *Service) AuthenticateUser(username string, password string) (*AuthUser, *entity.AppError) {
+// createRefreshToken stores a new refresh token for the user and returns its plain text value,
+// which is never persisted. An empty familyId starts a new token family.
+func (s *Service) createRefreshToken(userId int64, familyId string) (string, *entity.AppError) {
 
-	// get user with username
-	user, err := s.repo.FindByUsernameAndReturnPassword(username)
+	tokenString, err := randomToken(32)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	// compare user password with provided password:
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, entity.NewAppError(err)
+	if familyId == "" {
+		if familyId, err = randomToken(16); err != nil {
+			return "", err
+		}
+	}
+
+	now := time.Now().UTC()
+	token := &entity.RefreshToken{
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: hashToken(tokenString),
+		ExpiresAt: now.Add(refreshTokenTTL),
+		CreatedAt: now,
 	}
 
+	if err := s.repo.CreateRefreshToken(token); err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+func (s *Service) createAuthUser(user *entity.User, familyId string) (*AuthUser, *entity.AppError) {
+
 	// create JWT token
 	jwtTokenString, err := s.createJWTTokenString(user)
 	if err != nil {
 		return nil, err
 	}
 
+	refreshTokenString, err := s.createRefreshToken(user.Id, familyId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthUser{
+		User:         user,
+		Token:        jwtTokenString,
+		RefreshToken: refreshTokenString,
+	}, nil
+}
+
+// AuthenticateUser checks the credentials of a login from the client address ip. Repeated
+// failures for the username or from the address are answered with ErrTooManyAttempts, wrapping
+// a *entity.RetryAfterError, until their delay or lockout has passed. Unknown usernames and wrong
+// passwords both return ErrIncorrectPassword after the same bcrypt work.
+func (s *Service) AuthenticateUser(username string, password string, ip string) (*AuthUser, *entity.AppError) {
+
+	// the attempt counts as failed from here on, until it succeeds:
+	if retryAfter := s.throttle.begin(username, ip); retryAfter > 0 {
+		return nil, entity.NewRetryAfterError(retryAfter)
+	}
+
+	// get user with username
+	user, err := s.repo.FindByUsernameAndReturnPassword(username)
+	if err == entity.ErrEntityNotFound {
+		compareDummyPassword(password)
+		return nil, entity.ErrIncorrectPassword
+	}
+
+	if err != nil {
+		s.throttle.release(username, ip)
+		return nil, err
+	}
+
+	// compare user password with provided password:
+	if err := comparePassword(user.Password, password); err != nil {
+		return nil, err
+	}
+
+	s.throttle.succeed(username, ip)
+
 	if _, err := s.repo.UpdateWithLastLoginAt(user.Id); err != nil {
 		return nil, err
 	}
@@ -86,23 +204,559 @@ func (s *Service) AuthenticateUser(username string, password string) (*AuthUser,
 		return nil, err
 	}
 
+	roles, err := s.repo.FindRolesByUserID(user.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	authenticatedUser := &entity.User{
 		Id:          user.Id,
 		Username:    user.Username,
+		Roles:       roles,
 		CreatedAt:   user.CreatedAt,
 		LastLoginAt: updatedUser.LastLoginAt,
 	}
 
-	return &AuthUser{
-		User:  authenticatedUser,
-		Token: jwtTokenString,
-	}, nil
+	return s.createAuthUser(authenticatedUser, "")
+}
+
+// RegisterUser creates an account with the default role. The email address is optional,
+// but without one the password cannot be reset.
+func (s *Service) RegisterUser(username string, email string, password string) (*AuthUser, *entity.AppError) {
+
+	// validate credentials:
+	if err := validateUsername(username); err != nil {
+		return nil, err
+	}
+
+	if email != "" {
+		if err := validateEmail(email); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validatePassword(password); err != nil {
+		return nil, err
+	}
+
+	// reject duplicates before paying for a bcrypt hash:
+	if err := s.checkUsernameAvailable(username); err != nil {
+		return nil, err
+	}
+
+	if email != "" {
+		if err := s.checkEmailAvailable(email); err != nil {
+			return nil, err
+		}
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// create user with the default role, the repository reports a concurrent duplicate as
+	// ErrUserAlreadyExists:
+	user, err := s.repo.Create(username, email, hash, entity.RoleUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createAuthUser(user, "")
+}
+
+// RefreshToken exchanges a refresh token for a new JWT and a new refresh token in the same family.
+// Presenting a token that has already been exchanged revokes the whole family, since either the
+// client or an attacker is holding a stolen copy.
+func (s *Service) RefreshToken(tokenString string) (*AuthUser, *entity.AppError) {
+
+	token, err := s.repo.FindRefreshTokenByHash(hashToken(tokenString))
+	if err == entity.ErrEntityNotFound {
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token.UsedAt != nil || token.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(token)
+	}
+
+	if time.Now().After(token.ExpiresAt) {
+		return nil, entity.ErrInvalidRefreshToken
+	}
+
+	res, err := s.repo.MarkRefreshTokenUsed(token.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// another request rotated this token first:
+	if rows, rowsErr := res.RowsAffected(); rowsErr != nil || rows == 0 {
+		return nil, s.revokeReusedFamily(token)
+	}
+
+	user, err := s.repo.FindByID(token.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	// roles may have changed since the previous token was issued:
+	if user.Roles, err = s.repo.FindRolesByUserID(user.Id); err != nil {
+		return nil, err
+	}
+
+	return s.createAuthUser(user, token.FamilyId)
+}
+
+// RevokeRefreshToken revokes the family of the given refresh token of the user.
+// Unknown tokens and tokens of other users are ignored.
+func (s *Service) RevokeRefreshToken(userId int64, tokenString string) *entity.AppError {
+
+	token, err := s.repo.FindRefreshTokenByHash(hashToken(tokenString))
+	if err == entity.ErrEntityNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if token.UserId != userId {
+		return nil
+	}
+
+	if _, err := s.repo.RevokeRefreshTokenFamily(token.FamilyId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) revokeReusedFamily(token *entity.RefreshToken) *entity.AppError {
+	log.Printf("refresh token reuse detected for user=%d family=%s", token.UserId, token.FamilyId)
+
+	if _, err := s.repo.RevokeRefreshTokenFamily(token.FamilyId); err != nil {
+		return err
+	}
+
+	return entity.ErrRefreshTokenReuse
+}
+
+// GetProfile returns the user with its roles.
+func (s *Service) GetProfile(userId int64) (*entity.User, *entity.AppError) {
+	user, err := s.repo.FindByID(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Roles, err = s.repo.FindRolesByUserID(userId); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UpdateProfile changes the username, email address and/or password of the user. A new email
+// address or password is only accepted together with the current password, since either is
+// enough to take over the account. A new password signs out every other session of the user.
+func (s *Service) UpdateProfile(userId int64, update *ProfileUpdate) (*entity.User, *entity.AppError) {
+
+	if update.Username != nil {
+		if err := validateUsername(*update.Username); err != nil {
+			return nil, err
+		}
+	}
+
+	// an empty email address removes it:
+	if update.Email != nil && *update.Email != "" {
+		if err := validateEmail(*update.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Password != nil {
+		if err := validatePassword(*update.Password); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.repo.FindByIDAndReturnPassword(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	emailChanged := update.Email != nil && *update.Email != user.Email
+
+	// check the current password before changing anything:
+	if update.Password != nil || emailChanged {
+		if err := comparePassword(user.Password, update.CurrentPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Username != nil && *update.Username != user.Username {
+		if err := s.checkUsernameAvailable(*update.Username); err != nil {
+			return nil, err
+		}
+
+		if _, err := s.repo.UpdateUsername(userId, *update.Username); err != nil {
+			return nil, err
+		}
+	}
+
+	if emailChanged {
+		if *update.Email != "" {
+			if err := s.checkEmailAvailable(*update.Email); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err := s.repo.UpdateEmail(userId, *update.Email); err != nil {
+			return nil, err
+		}
+	}
+
+	if update.Password != nil {
+		hash, err := hashPassword(*update.Password)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := s.repo.UpdatePassword(userId, hash); err != nil {
+			return nil, err
+		}
+
+		if _, err := s.repo.RevokeRefreshTokensByUserID(userId); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetProfile(userId)
+}
+
+// DeleteUser deletes the account of the user after re-checking their password.
+func (s *Service) DeleteUser(userId int64, currentPassword string) *entity.AppError {
+
+	user, err := s.repo.FindByIDAndReturnPassword(userId)
+	if err != nil {
+		return err
+	}
+
+	if err := comparePassword(user.Password, currentPassword); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.Delete(userId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ListUsers returns one page of users. cursor is the NextCursor of the previous page and must
+// have been issued for the same sort order.
+func (s *Service) ListUsers(query *entity.UserListQuery, cursor string) (*entity.UserListPage, *entity.AppError) {
+
+	if query.SortBy == "" {
+		query.SortBy = entity.UserSortCreatedAt
+	}
+
+	switch query.SortBy {
+	case entity.UserSortCreatedAt, entity.UserSortLastLoginAt, entity.UserSortUsername:
+	default:
+		return nil, entity.ErrInvalidSort
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultListLimit
+	}
+
+	if query.Limit > maxListLimit {
+		query.Limit = maxListLimit
+	}
+
+	if cursor != "" {
+		after, err := decodeListCursor(cursor)
+		if err != nil || after.SortBy != query.SortBy || after.Descending != query.Descending {
+			return nil, entity.ErrInvalidCursor
+		}
+
+		query.After = after
+	}
+
+	// fetch one extra user to know whether there is a next page:
+	limit := query.Limit
+	query.Limit = limit + 1
+	users, err := s.repo.List(query)
+	query.Limit = limit
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.UserListPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+
+		last := page.Users[limit-1]
+		next := &entity.UserListCursor{SortBy: query.SortBy, Descending: query.Descending, Id: last.Id}
+		switch query.SortBy {
+		case entity.UserSortUsername:
+			next.Value = last.Username
+		case entity.UserSortLastLoginAt:
+			next.Value = last.LastLoginAt.UTC().Format(time.RFC3339Nano)
+		default:
+			next.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+		}
+
+		if page.NextCursor, err = encodeListCursor(next); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// RequestPasswordReset mails a single-use reset link to the user with the email address.
+// Unknown addresses are silently ignored so that the response does not reveal accounts, and
+// the link is created and mailed in the background, so that the response takes as long for
+// known addresses as for unknown ones. Failures to send it are logged.
+func (s *Service) RequestPasswordReset(email string) *entity.AppError {
+
+	user, err := s.repo.FindByEmail(email)
+	if err == entity.ErrEntityNotFound {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	s.resets.Add(1)
+	go func() {
+		defer s.resets.Done()
+
+		if err := s.sendPasswordReset(user); err != nil {
+			log.Printf("unable to send a password reset mail to user=%d: %s", user.Id, err)
+		}
+	}()
+
+	return nil
+}
+
+// Close waits for the password reset mails that are being sent.
+func (s *Service) Close() error {
+	s.resets.Wait()
+	return nil
+}
+
+func (s *Service) sendPasswordReset(user *entity.User) *entity.AppError {
+
+	// only the most recently mailed link stays valid:
+	if _, err := s.repo.InvalidatePasswordResetTokens(user.Id); err != nil {
+		return err
+	}
+
+	tokenString, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	token := &entity.PasswordResetToken{
+		UserId:    user.Id,
+		TokenHash: hashToken(tokenString),
+		ExpiresAt: now.Add(resetTokenTTL),
+		CreatedAt: now,
+	}
+
+	if err := s.repo.CreatePasswordResetToken(token); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", s.passwordResetURL, url.QueryEscape(tokenString))
+
+	return s.mailer.Send(&mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nuse the link below to choose a new password. It expires in %d minutes.\n\n%s\n\n"+
+			"If you did not ask for a new password you can ignore this message.\n", user.Username, int(resetTokenTTL.Minutes()), link),
+	})
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset and signs the
+// user out everywhere.
+func (s *Service) ResetPassword(tokenString string, password string) *entity.AppError {
+
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	token, err := s.repo.FindPasswordResetTokenByHash(hashToken(tokenString))
+	if err == entity.ErrEntityNotFound {
+		return entity.ErrInvalidResetToken
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		return entity.ErrInvalidResetToken
+	}
+
+	res, err := s.repo.MarkPasswordResetTokenUsed(token.Id)
+	if err != nil {
+		return err
+	}
+
+	// another request redeemed this token first:
+	if rows, rowsErr := res.RowsAffected(); rowsErr != nil || rows == 0 {
+		return entity.ErrInvalidResetToken
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.repo.UpdatePassword(token.UserId, hash); err != nil {
+		return err
+	}
+
+	if _, err := s.repo.RevokeRefreshTokensByUserID(token.UserId); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (s *Service) GetUserByID(userId int64) (*entity.User, error) {
+func (s *Service) checkEmailAvailable(email string) *entity.AppError {
+	_, err := s.repo.FindByEmail(email)
+	if err == nil {
+		return entity.ErrEmailAlreadyExists
+	}
+
+	if err != entity.ErrEntityNotFound {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) checkUsernameAvailable(username string) *entity.AppError {
+	_, err := s.repo.FindByUsername(username)
+	if err == nil {
+		return entity.ErrUserAlreadyExists
+	}
+
+	if err != entity.ErrEntityNotFound {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Service) GetUserByID(userId int64) (*entity.User, *entity.AppError) {
 	return s.repo.FindByID(userId)
 }
 
-func (s *Service) GetUserByUsername(username string) (*entity.User, error) {
+func (s *Service) GetUserByUsername(username string) (*entity.User, *entity.AppError) {
 	return s.repo.FindByUsername(username)
 }
+
+func validateUsername(username string) *entity.AppError {
+	if !usernamePattern.MatchString(username) {
+		return entity.ErrInvalidUsername
+	}
+
+	return nil
+}
+
+// validateEmail accepts a bare address such as "name@example.com".
+func validateEmail(email string) *entity.AppError {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return entity.ErrInvalidEmail
+	}
+
+	return nil
+}
+
+func validatePassword(password string) *entity.AppError {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return entity.ErrInvalidPassword
+	}
+
+	return nil
+}
+
+func hashPassword(password string) (string, *entity.AppError) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", entity.NewAppError(err)
+	}
+
+	return string(hash), nil
+}
+
+// comparePassword checks a plain text password against the stored bcrypt hash.
+func comparePassword(hash string, password string) *entity.AppError {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return entity.ErrIncorrectPassword
+	}
+
+	return nil
+}
+
+// compareDummyPassword spends the time of a password check on a hash that never matches.
+func compareDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+		if err != nil {
+			log.Println(err)
+		}
+		dummyHash = hash
+	})
+
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+func encodeListCursor(cursor *entity.UserListCursor) (string, *entity.AppError) {
+	b, err := json.Marshal(cursor)
+	if err != nil {
+		return "", entity.NewAppError(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeListCursor(cursor string) (*entity.UserListCursor, *entity.AppError) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	var c entity.UserListCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// randomToken returns n random bytes encoded as unpadded base64url.
+func randomToken(n int) (string, *entity.AppError) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", entity.NewAppError(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
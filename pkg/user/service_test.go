@@ -0,0 +1,649 @@
+package user
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"quiz-app/pkg/entity"
+	"quiz-app/pkg/keyring"
+	"quiz-app/pkg/mailer"
+	mockUser "quiz-app/pkg/mocks/user"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestKeyring(t *testing.T) *keyring.Keyring {
+	kr := keyring.New()
+
+	key, err := keyring.GenerateKey("test")
+	if err != nil {
+		t.Fatalf("unable to generate signing key: [%s]", err)
+	}
+
+	if err := kr.Add(key); err != nil {
+		t.Fatalf("unable to add signing key: [%s]", err)
+	}
+
+	if err := kr.SetSigningKey(key.Id); err != nil {
+		t.Fatalf("unable to set signing key: [%s]", err)
+	}
+
+	return kr
+}
+
+func TestRegisterUser(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockUser.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, newTestKeyring(t), mailer.InitLogMailer(io.Discard), "https://quiz.example/reset")
+
+	t.Run("RegisterUser should reject an invalid username", func(t *testing.T) {
+		for _, username := range []string{"", "ab", "has space", "-leading", "waytoolongusernamethatkeepsgoingon"} {
+			authUser, err := service.RegisterUser(username, "", "password123")
+
+			if authUser != nil {
+				t.Fail()
+			}
+
+			if err != entity.ErrInvalidUsername {
+				t.Errorf("expected invalid username error for %q, got [%v]", username, err)
+			}
+		}
+	})
+
+	t.Run("RegisterUser should reject a password that is too short or too long", func(t *testing.T) {
+		for _, password := range []string{"short", string(make([]byte, 73))} {
+			authUser, err := service.RegisterUser("munens", "", password)
+
+			if authUser != nil {
+				t.Fail()
+			}
+
+			if err != entity.ErrInvalidPassword {
+				t.Fail()
+			}
+		}
+	})
+
+	t.Run("RegisterUser should reject a username that is already taken", func(t *testing.T) {
+		mockRepo.EXPECT().FindByUsername("munens").Return(&entity.User{Id: 1, Username: "munens"}, nil)
+
+		authUser, err := service.RegisterUser("munens", "", "password123")
+
+		if authUser != nil {
+			t.Fail()
+		}
+
+		if !errors.Is(err, entity.ErrUserAlreadyExists) {
+			t.Fail()
+		}
+	})
+
+	t.Run("RegisterUser should surface a duplicate reported by the repository", func(t *testing.T) {
+		mockRepo.EXPECT().FindByUsername("munens").Return(nil, entity.ErrEntityNotFound)
+		mockRepo.EXPECT().Create("munens", "", gomock.Any(), entity.RoleUser).Return(nil, entity.ErrUserAlreadyExists)
+
+		authUser, err := service.RegisterUser("munens", "", "password123")
+
+		if authUser != nil {
+			t.Fail()
+		}
+
+		if err != entity.ErrUserAlreadyExists {
+			t.Fail()
+		}
+	})
+
+	t.Run("RegisterUser should store a bcrypt hash and return a token", func(t *testing.T) {
+		var storedHash string
+		createdAt := time.Now().UTC()
+
+		mockRepo.EXPECT().FindByUsername("munens").Return(nil, entity.ErrEntityNotFound)
+		mockRepo.EXPECT().Create("munens", "", gomock.Any(), entity.RoleUser).DoAndReturn(func(username string, email string, hash string, role string) (*entity.User, *entity.AppError) {
+			storedHash = hash
+			return &entity.User{Id: 1, Username: username, Roles: []string{role}, CreatedAt: createdAt}, nil
+		})
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		authUser, err := service.RegisterUser("munens", "", "password123")
+
+		if err != nil {
+			t.Fatalf("unexpected error: [%s]", err)
+		}
+
+		if storedHash == "password123" {
+			t.Fail()
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte("password123")); err != nil {
+			t.Fail()
+		}
+
+		if authUser.User.Id != 1 || authUser.User.Password != "" || authUser.Token == "" || authUser.RefreshToken == "" {
+			t.Fail()
+		}
+
+		if !entity.HasRole(authUser.User.Roles, entity.RoleUser) {
+			t.Fail()
+		}
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockUser.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, newTestKeyring(t), mailer.InitLogMailer(io.Discard), "https://quiz.example/reset")
+
+	t.Run("RefreshToken should reject an unknown token", func(t *testing.T) {
+		mockRepo.EXPECT().FindRefreshTokenByHash(hashToken("unknown")).Return(nil, entity.ErrEntityNotFound)
+
+		authUser, err := service.RefreshToken("unknown")
+
+		if authUser != nil || err != entity.ErrInvalidRefreshToken {
+			t.Fail()
+		}
+	})
+
+	t.Run("RefreshToken should reject an expired token", func(t *testing.T) {
+		mockRepo.EXPECT().FindRefreshTokenByHash(hashToken("expired")).Return(&entity.RefreshToken{
+			Id:        1,
+			UserId:    1,
+			FamilyId:  "family",
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}, nil)
+
+		authUser, err := service.RefreshToken("expired")
+
+		if authUser != nil || err != entity.ErrInvalidRefreshToken {
+			t.Fail()
+		}
+	})
+
+	t.Run("RefreshToken should revoke the family when a used token is replayed", func(t *testing.T) {
+		usedAt := time.Now().Add(-time.Minute)
+		mockRepo.EXPECT().FindRefreshTokenByHash(hashToken("used")).Return(&entity.RefreshToken{
+			Id:        1,
+			UserId:    1,
+			FamilyId:  "family",
+			ExpiresAt: time.Now().Add(time.Hour),
+			UsedAt:    &usedAt,
+		}, nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily("family").Return(driver.RowsAffected(2), nil)
+
+		authUser, err := service.RefreshToken("used")
+
+		if authUser != nil || err != entity.ErrRefreshTokenReuse {
+			t.Fail()
+		}
+	})
+
+	t.Run("RefreshToken should revoke the family when a concurrent rotation wins", func(t *testing.T) {
+		mockRepo.EXPECT().FindRefreshTokenByHash(hashToken("racing")).Return(&entity.RefreshToken{
+			Id:        1,
+			UserId:    1,
+			FamilyId:  "family",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(int64(1)).Return(driver.RowsAffected(0), nil)
+		mockRepo.EXPECT().RevokeRefreshTokenFamily("family").Return(driver.RowsAffected(1), nil)
+
+		authUser, err := service.RefreshToken("racing")
+
+		if authUser != nil || err != entity.ErrRefreshTokenReuse {
+			t.Fail()
+		}
+	})
+
+	t.Run("RefreshToken should rotate a valid token within its family", func(t *testing.T) {
+		mockRepo.EXPECT().FindRefreshTokenByHash(hashToken("valid")).Return(&entity.RefreshToken{
+			Id:        1,
+			UserId:    1,
+			FamilyId:  "family",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+		mockRepo.EXPECT().MarkRefreshTokenUsed(int64(1)).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.User{Id: 1, Username: "munens"}, nil)
+		mockRepo.EXPECT().FindRolesByUserID(int64(1)).Return([]string{entity.RoleAdmin}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any()).DoAndReturn(func(token *entity.RefreshToken) *entity.AppError {
+			if token.FamilyId != "family" || token.UserId != 1 || token.TokenHash == "" {
+				t.Fail()
+			}
+			return nil
+		})
+
+		authUser, err := service.RefreshToken("valid")
+
+		if err != nil {
+			t.Fatalf("unexpected error: [%s]", err)
+		}
+
+		if authUser.Token == "" || authUser.RefreshToken == "" || authUser.RefreshToken == "valid" {
+			t.Fail()
+		}
+
+		if !entity.HasRole(authUser.User.Roles, entity.RoleAdmin) {
+			t.Fail()
+		}
+	})
+}
+
+func TestUpdateProfile(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockUser.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, newTestKeyring(t), mailer.InitLogMailer(io.Discard), "https://quiz.example/reset")
+
+	hash, err := hashPassword("password123")
+	if err != nil {
+		t.Fatalf("unable to hash password: [%s]", err)
+	}
+
+	newPassword := "password456"
+	newUsername := "munens2"
+
+	t.Run("UpdateProfile should reject a password change with the wrong current password", func(t *testing.T) {
+		mockRepo.EXPECT().FindByIDAndReturnPassword(int64(1)).Return(&entity.User{Id: 1, Username: "munens", Password: hash}, nil)
+
+		u, err := service.UpdateProfile(1, &ProfileUpdate{
+			Username:        &newUsername,
+			Password:        &newPassword,
+			CurrentPassword: "wrong",
+		})
+
+		if u != nil || err != entity.ErrIncorrectPassword {
+			t.Fail()
+		}
+	})
+
+	t.Run("UpdateProfile should reject a username that is taken", func(t *testing.T) {
+		mockRepo.EXPECT().FindByIDAndReturnPassword(int64(1)).Return(&entity.User{Id: 1, Username: "munens", Password: hash}, nil)
+		mockRepo.EXPECT().FindByUsername(newUsername).Return(&entity.User{Id: 2, Username: newUsername}, nil)
+
+		u, err := service.UpdateProfile(1, &ProfileUpdate{Username: &newUsername})
+
+		if u != nil || err != entity.ErrUserAlreadyExists {
+			t.Fail()
+		}
+	})
+
+	t.Run("UpdateProfile should reject an email change without the current password", func(t *testing.T) {
+		newEmail := "attacker@example.com"
+		mockRepo.EXPECT().FindByIDAndReturnPassword(int64(1)).Return(&entity.User{Id: 1, Username: "munens", Email: "munens@example.com", Password: hash}, nil)
+
+		u, err := service.UpdateProfile(1, &ProfileUpdate{Email: &newEmail})
+
+		if u != nil || err != entity.ErrIncorrectPassword {
+			t.Fail()
+		}
+	})
+
+	t.Run("UpdateProfile should store a new email address with the current password", func(t *testing.T) {
+		newEmail := "munens2@example.com"
+		mockRepo.EXPECT().FindByIDAndReturnPassword(int64(1)).Return(&entity.User{Id: 1, Username: "munens", Email: "munens@example.com", Password: hash}, nil)
+		mockRepo.EXPECT().FindByEmail(newEmail).Return(nil, entity.ErrEntityNotFound)
+		mockRepo.EXPECT().UpdateEmail(int64(1), newEmail).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.User{Id: 1, Username: "munens", Email: newEmail}, nil)
+		mockRepo.EXPECT().FindRolesByUserID(int64(1)).Return([]string{entity.RoleUser}, nil)
+
+		u, err := service.UpdateProfile(1, &ProfileUpdate{Email: &newEmail, CurrentPassword: "password123"})
+
+		if err != nil || u == nil || u.Email != newEmail {
+			t.Fail()
+		}
+	})
+
+	t.Run("UpdateProfile should store a new password hash and sign out other sessions", func(t *testing.T) {
+		mockRepo.EXPECT().FindByIDAndReturnPassword(int64(1)).Return(&entity.User{Id: 1, Username: "munens", Password: hash}, nil)
+		mockRepo.EXPECT().UpdatePassword(int64(1), gomock.Any()).DoAndReturn(func(userId int64, newHash string) (sql.Result, *entity.AppError) {
+			if comparePassword(newHash, newPassword) != nil {
+				t.Fail()
+			}
+			return driver.RowsAffected(1), nil
+		})
+		mockRepo.EXPECT().RevokeRefreshTokensByUserID(int64(1)).Return(driver.RowsAffected(3), nil)
+		mockRepo.EXPECT().FindByID(int64(1)).Return(&entity.User{Id: 1, Username: "munens"}, nil)
+		mockRepo.EXPECT().FindRolesByUserID(int64(1)).Return([]string{entity.RoleUser}, nil)
+
+		u, err := service.UpdateProfile(1, &ProfileUpdate{
+			Password:        &newPassword,
+			CurrentPassword: "password123",
+		})
+
+		if err != nil || u == nil || u.Username != "munens" {
+			t.Fail()
+		}
+	})
+}
+
+func TestListUsers(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockUser.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, newTestKeyring(t), mailer.InitLogMailer(io.Discard), "https://quiz.example/reset")
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	users := []*entity.User{
+		{Id: 1, Username: "a", CreatedAt: createdAt},
+		{Id: 2, Username: "b", CreatedAt: createdAt.Add(time.Hour)},
+		{Id: 3, Username: "c", CreatedAt: createdAt.Add(2 * time.Hour)},
+	}
+
+	t.Run("ListUsers should reject an unknown sort", func(t *testing.T) {
+		page, err := service.ListUsers(&entity.UserListQuery{SortBy: "password"}, "")
+
+		if page != nil || err != entity.ErrInvalidSort {
+			t.Fail()
+		}
+	})
+
+	t.Run("ListUsers should return a cursor when there are more users", func(t *testing.T) {
+		mockRepo.EXPECT().List(gomock.Any()).DoAndReturn(func(query *entity.UserListQuery) ([]*entity.User, *entity.AppError) {
+			if query.Limit != 3 || query.After != nil || query.SortBy != entity.UserSortCreatedAt {
+				t.Fail()
+			}
+			return users, nil
+		})
+
+		page, err := service.ListUsers(&entity.UserListQuery{Limit: 2}, "")
+		if err != nil {
+			t.Fatalf("unexpected error: [%s]", err)
+		}
+
+		if len(page.Users) != 2 || page.NextCursor == "" {
+			t.FailNow()
+		}
+
+		cursor, err := decodeListCursor(page.NextCursor)
+		if err != nil {
+			t.Fatalf("unable to decode cursor: [%s]", err)
+		}
+
+		if cursor.Id != 2 || cursor.Value != users[1].CreatedAt.Format(time.RFC3339Nano) {
+			t.Fail()
+		}
+	})
+
+	t.Run("ListUsers should pass the cursor on and omit it on the last page", func(t *testing.T) {
+		next, _ := encodeListCursor(&entity.UserListCursor{SortBy: entity.UserSortCreatedAt, Value: "2024-01-01T01:00:00Z", Id: 2})
+
+		mockRepo.EXPECT().List(gomock.Any()).DoAndReturn(func(query *entity.UserListQuery) ([]*entity.User, *entity.AppError) {
+			if query.After == nil || query.After.Id != 2 {
+				t.Fail()
+			}
+			return users[2:], nil
+		})
+
+		page, err := service.ListUsers(&entity.UserListQuery{Limit: 2}, next)
+		if err != nil {
+			t.Fatalf("unexpected error: [%s]", err)
+		}
+
+		if len(page.Users) != 1 || page.NextCursor != "" {
+			t.Fail()
+		}
+	})
+
+	t.Run("ListUsers should reject a cursor issued for another sort order", func(t *testing.T) {
+		next, _ := encodeListCursor(&entity.UserListCursor{SortBy: entity.UserSortUsername, Value: "b", Id: 2})
+
+		page, err := service.ListUsers(&entity.UserListQuery{SortBy: entity.UserSortCreatedAt}, next)
+
+		if page != nil || err != entity.ErrInvalidCursor {
+			t.Fail()
+		}
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var mails bytes.Buffer
+	mockRepo := mockUser.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, newTestKeyring(t), mailer.InitLogMailer(&mails), "https://quiz.example/reset")
+
+	t.Run("RequestPasswordReset should not mail an unknown address", func(t *testing.T) {
+		mockRepo.EXPECT().FindByEmail("nobody@example.com").Return(nil, entity.ErrEntityNotFound)
+
+		if err := service.RequestPasswordReset("nobody@example.com"); err != nil {
+			t.Fail()
+		}
+		service.Close()
+
+		if mails.Len() != 0 {
+			t.Fail()
+		}
+	})
+
+	t.Run("RequestPasswordReset should mail a link whose token hash is stored", func(t *testing.T) {
+		var storedHash string
+		mockRepo.EXPECT().FindByEmail("munens@example.com").Return(&entity.User{Id: 1, Username: "munens", Email: "munens@example.com"}, nil)
+		mockRepo.EXPECT().InvalidatePasswordResetTokens(int64(1)).Return(driver.RowsAffected(0), nil)
+		mockRepo.EXPECT().CreatePasswordResetToken(gomock.Any()).DoAndReturn(func(token *entity.PasswordResetToken) *entity.AppError {
+			storedHash = token.TokenHash
+			if token.UserId != 1 || !token.ExpiresAt.After(time.Now()) {
+				t.Fail()
+			}
+			return nil
+		})
+
+		if err := service.RequestPasswordReset("munens@example.com"); err != nil {
+			t.Fatalf("unexpected error: [%s]", err)
+		}
+
+		// the mail is sent in the background:
+		service.Close()
+
+		mail := mails.String()
+		i := strings.Index(mail, "https://quiz.example/reset?token=")
+		if i < 0 || !strings.Contains(mail, "To: munens@example.com") {
+			t.FailNow()
+		}
+
+		tokenString := strings.Fields(mail[i+len("https://quiz.example/reset?token="):])[0]
+		if hashToken(tokenString) != storedHash {
+			t.Fail()
+		}
+	})
+
+	t.Run("ResetPassword should reject a used token", func(t *testing.T) {
+		usedAt := time.Now().Add(-time.Minute)
+		mockRepo.EXPECT().FindPasswordResetTokenByHash(hashToken("used")).Return(&entity.PasswordResetToken{
+			Id:        1,
+			UserId:    1,
+			ExpiresAt: time.Now().Add(time.Hour),
+			UsedAt:    &usedAt,
+		}, nil)
+
+		if err := service.ResetPassword("used", "password456"); err != entity.ErrInvalidResetToken {
+			t.Fail()
+		}
+	})
+
+	t.Run("ResetPassword should reject an expired token", func(t *testing.T) {
+		mockRepo.EXPECT().FindPasswordResetTokenByHash(hashToken("expired")).Return(&entity.PasswordResetToken{
+			Id:        1,
+			UserId:    1,
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}, nil)
+
+		if err := service.ResetPassword("expired", "password456"); err != entity.ErrInvalidResetToken {
+			t.Fail()
+		}
+	})
+
+	t.Run("ResetPassword should set the password and sign the user out", func(t *testing.T) {
+		mockRepo.EXPECT().FindPasswordResetTokenByHash(hashToken("valid")).Return(&entity.PasswordResetToken{
+			Id:        1,
+			UserId:    1,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}, nil)
+		mockRepo.EXPECT().MarkPasswordResetTokenUsed(int64(1)).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().UpdatePassword(int64(1), gomock.Any()).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().RevokeRefreshTokensByUserID(int64(1)).Return(driver.RowsAffected(1), nil)
+
+		if err := service.ResetPassword("valid", "password456"); err != nil {
+			t.Fail()
+		}
+	})
+}
+
+func TestAuthenticateUser(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockRepo := mockUser.NewMockRepository(mockCtrl)
+	service := InitService(mockRepo, newTestKeyring(t), mailer.InitLogMailer(io.Discard), "https://quiz.example/reset")
+
+	now := time.Now()
+	service.throttle.now = func() time.Time { return now }
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("unable to hash password: [%s]", err)
+	}
+
+	retryAfter := func(err *entity.AppError) time.Duration {
+		if err == nil || !err.Is(entity.ErrTooManyAttempts) {
+			return 0
+		}
+
+		retryErr, ok := err.AppError.(*entity.RetryAfterError)
+		if !ok {
+			return 0
+		}
+
+		return retryErr.RetryAfter
+	}
+
+	t.Run("AuthenticateUser should answer an unknown user like a wrong password", func(t *testing.T) {
+		mockRepo.EXPECT().FindByUsernameAndReturnPassword("nobody").Return(nil, entity.ErrEntityNotFound)
+
+		authUser, err := service.AuthenticateUser("nobody", "password123", "10.0.0.1")
+
+		if authUser != nil || err != entity.ErrIncorrectPassword {
+			t.Fail()
+		}
+	})
+
+	t.Run("AuthenticateUser should delay an account after repeated failures", func(t *testing.T) {
+		mockRepo.EXPECT().FindByUsernameAndReturnPassword("munens").Return(&entity.User{Id: 1, Username: "munens", Password: string(hash)}, nil).Times(accountPolicy.freeAttempts)
+
+		for i := 0; i < accountPolicy.freeAttempts; i++ {
+			if _, err := service.AuthenticateUser("munens", "wrong", "10.0.0.2"); err != entity.ErrIncorrectPassword {
+				t.FailNow()
+			}
+		}
+
+		// a different address does not help, the delay belongs to the account:
+		_, err := service.AuthenticateUser("munens", "password123", "10.0.0.3")
+		if retryAfter(err) != accountPolicy.baseDelay {
+			t.Errorf("expected a delay of %s, got [%v]", accountPolicy.baseDelay, err)
+		}
+	})
+
+	t.Run("AuthenticateUser should succeed once the delay passed and reset the account", func(t *testing.T) {
+		now = now.Add(accountPolicy.baseDelay)
+
+		mockRepo.EXPECT().FindByUsernameAndReturnPassword("munens").Return(&entity.User{Id: 1, Username: "munens", Password: string(hash)}, nil)
+		mockRepo.EXPECT().UpdateWithLastLoginAt(int64(1)).Return(driver.RowsAffected(1), nil)
+		mockRepo.EXPECT().FindByUsername("munens").Return(&entity.User{Id: 1, Username: "munens"}, nil)
+		mockRepo.EXPECT().FindRolesByUserID(int64(1)).Return([]string{entity.RoleUser}, nil)
+		mockRepo.EXPECT().CreateRefreshToken(gomock.Any()).Return(nil)
+
+		authUser, err := service.AuthenticateUser("munens", "password123", "10.0.0.2")
+		if err != nil || authUser == nil {
+			t.FailNow()
+		}
+
+		// the next failure is free again:
+		mockRepo.EXPECT().FindByUsernameAndReturnPassword("munens").Return(&entity.User{Id: 1, Username: "munens", Password: string(hash)}, nil)
+		if _, err := service.AuthenticateUser("munens", "wrong", "10.0.0.3"); err != entity.ErrIncorrectPassword {
+			t.Error(err)
+		}
+	})
+
+	t.Run("AuthenticateUser should lock an account out after too many failures", func(t *testing.T) {
+		mockRepo.EXPECT().FindByUsernameAndReturnPassword("locked").Return(nil, entity.ErrEntityNotFound).Times(accountPolicy.lockoutAttempts + 1)
+
+		for i := 0; i < accountPolicy.lockoutAttempts; i++ {
+			// wait out every progressive delay:
+			now = now.Add(accountPolicy.maxDelay)
+
+			if _, err := service.AuthenticateUser("locked", "wrong", "10.0.0.4"); err != entity.ErrIncorrectPassword {
+				t.FailNow()
+			}
+		}
+
+		_, err := service.AuthenticateUser("locked", "wrong", "10.0.0.4")
+		if retryAfter(err) != accountPolicy.lockout {
+			t.Errorf("expected a lockout of %s, got [%v]", accountPolicy.lockout, err)
+		}
+
+		now = now.Add(accountPolicy.lockout)
+		if _, err := service.AuthenticateUser("locked", "wrong", "10.0.0.4"); err != entity.ErrIncorrectPassword {
+			t.Error(err)
+		}
+	})
+
+	t.Run("AuthenticateUser should not let concurrent guesses past the free attempts", func(t *testing.T) {
+		guesses := 3 * accountPolicy.freeAttempts
+		mockRepo.EXPECT().FindByUsernameAndReturnPassword("racer").Return(&entity.User{Id: 3, Username: "racer", Password: string(hash)}, nil).Times(accountPolicy.freeAttempts)
+
+		var wg sync.WaitGroup
+		errs := make(chan *entity.AppError, guesses)
+		for i := 0; i < guesses; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := service.AuthenticateUser("racer", "wrong", fmt.Sprintf("10.0.1.%d", i))
+				errs <- err
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+
+		delayed := 0
+		for err := range errs {
+			if retryAfter(err) > 0 {
+				delayed++
+			} else if err != entity.ErrIncorrectPassword {
+				t.Error(err)
+			}
+		}
+
+		if delayed != guesses-accountPolicy.freeAttempts {
+			t.Errorf("expected %d delayed guesses, got %d", guesses-accountPolicy.freeAttempts, delayed)
+		}
+	})
+
+	t.Run("AuthenticateUser should lock out an address guessing many accounts", func(t *testing.T) {
+		for i := 0; i < ipPolicy.lockoutAttempts; i++ {
+			now = now.Add(ipPolicy.maxDelay)
+			service.throttle.begin(fmt.Sprintf("user%d", i), "10.0.0.5")
+		}
+
+		_, err := service.AuthenticateUser("someone", "password123", "10.0.0.5")
+		if retryAfter(err) != ipPolicy.lockout {
+			t.Errorf("expected a lockout of %s, got [%v]", ipPolicy.lockout, err)
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package user
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// throttlePolicy describes how failed logins against one key are slowed down. After
+// freeAttempts failures every further attempt has to wait baseDelay, doubling with each
+// failure up to maxDelay. Reaching lockoutAttempts locks the key for lockout. Failures are
+// forgotten after window without a new failure.
+type throttlePolicy struct {
+	freeAttempts    int
+	lockoutAttempts int
+	baseDelay       time.Duration
+	maxDelay        time.Duration
+	lockout         time.Duration
+	window          time.Duration
+}
+
+var accountPolicy = throttlePolicy{
+	freeAttempts:    3,
+	lockoutAttempts: 10,
+	baseDelay:       time.Second,
+	maxDelay:        30 * time.Second,
+	lockout:         15 * time.Minute,
+	window:          time.Hour,
+}
+
+// many users may share an address behind a NAT, so an address gets more room than an account:
+var ipPolicy = throttlePolicy{
+	freeAttempts:    20,
+	lockoutAttempts: 100,
+	baseDelay:       time.Second,
+	maxDelay:        30 * time.Second,
+	lockout:         15 * time.Minute,
+	window:          time.Hour,
+}
+
+const throttleSweepInterval = 5 * time.Minute
+
+type failedLogins struct {
+	policy      *throttlePolicy
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// retryAfter returns how long the key has to wait before its next attempt, or 0.
+func (f *failedLogins) retryAfter(now time.Time) time.Duration {
+	if now.Before(f.lockedUntil) {
+		return f.lockedUntil.Sub(now)
+	}
+
+	if f.count < f.policy.freeAttempts {
+		return 0
+	}
+
+	delay := f.policy.baseDelay << uint(f.count-f.policy.freeAttempts)
+	if delay > f.policy.maxDelay || delay <= 0 {
+		delay = f.policy.maxDelay
+	}
+
+	if wait := f.lastFailure.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+
+	return 0
+}
+
+func (f *failedLogins) expired(now time.Time) bool {
+	return !now.Before(f.lockedUntil) && now.Sub(f.lastFailure) > f.policy.window
+}
+
+// loginThrottle tracks failed logins per account and per client address in memory, so each
+// instance of the api keeps its own counts.
+type loginThrottle struct {
+	mu        sync.Mutex
+	failures  map[string]*failedLogins
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func newLoginThrottle() *loginThrottle {
+	return &loginThrottle{
+		failures: map[string]*failedLogins{},
+		now:      time.Now,
+	}
+}
+
+func accountKey(username string) string {
+	return "account:" + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// wait returns the longest wait of the keys.
+func (t *loginThrottle) wait(keys []string, now time.Time) time.Duration {
+	var wait time.Duration
+	for _, key := range keys {
+		if f, ok := t.failures[key]; ok {
+			if d := f.retryAfter(now); d > wait {
+				wait = d
+			}
+		}
+	}
+
+	return wait
+}
+
+// begin reserves an attempt at the account from the address and returns 0, or returns how long
+// the caller has to wait without reserving anything. The attempt counts as failed until succeed
+// or release gives it back, so that concurrent guesses cannot all pass before the first of them
+// has failed.
+func (t *loginThrottle) begin(username string, ip string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	keys := t.keys(username, ip)
+	if wait := t.wait(keys, now); wait > 0 {
+		return wait
+	}
+
+	for i, key := range keys {
+		f, ok := t.failures[key]
+		if !ok || f.expired(now) {
+			policy := &accountPolicy
+			if i > 0 {
+				policy = &ipPolicy
+			}
+
+			f = &failedLogins{policy: policy}
+			t.failures[key] = f
+		}
+
+		f.count++
+		f.lastFailure = now
+
+		if f.count >= f.policy.lockoutAttempts {
+			f.count = 0
+			f.lockedUntil = now.Add(f.policy.lockout)
+		}
+	}
+
+	return 0
+}
+
+// succeed forgets the failed attempts against the account. The address only gets its
+// reservation back, so that logging into an own account does not reset the budget for
+// guessing others.
+func (t *loginThrottle) succeed(username string, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, accountKey(username))
+	if ip != "" {
+		t.unreserve([]string{ipKey(ip)})
+	}
+}
+
+// release gives back the reservation of an attempt that neither failed nor succeeded.
+func (t *loginThrottle) release(username string, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.unreserve(t.keys(username, ip))
+}
+
+func (t *loginThrottle) unreserve(keys []string) {
+	for _, key := range keys {
+		if f, ok := t.failures[key]; ok && f.count > 0 {
+			f.count--
+		}
+	}
+}
+
+func (t *loginThrottle) keys(username string, ip string) []string {
+	keys := []string{accountKey(username)}
+	if ip != "" {
+		keys = append(keys, ipKey(ip))
+	}
+
+	return keys
+}
+
+// sweep drops expired entries so that the map does not grow with every guessed username.
+func (t *loginThrottle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < throttleSweepInterval {
+		return
+	}
+	t.lastSweep = now
+
+	for key, f := range t.failures {
+		if f.expired(now) {
+			delete(t.failures, key)
+		}
+	}
+}